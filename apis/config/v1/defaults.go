@@ -71,6 +71,31 @@ var (
 		{Name: string(v1.ResourceMemory), Weight: 1},
 	}
 
+	defaultIgnoredResources = []string{
+		string(v1.ResourceEphemeralStorage),
+		string(v1.ResourcePods),
+	}
+
+	defaultResourceRoundingPolicies = map[string]ResourceRoundingPolicy{
+		string(v1.ResourceCPU): RoundingCeil,
+	}
+
+	defaultResourceGranularity = map[string]resource.Quantity{
+		string(v1.ResourceCPU): resource.MustParse("1"),
+	}
+
+	// DefaultMissingNRTPolicy is how the NodeResourceTopologyMatch filter handles the nodes without NRT data
+	DefaultMissingNRTPolicy = MissingNRTSkip
+
+	// DefaultNegativeAvailabilityPolicy is how the NodeResourceTopologyMatch cache handles the negative availability
+	DefaultNegativeAvailabilityPolicy = NegativeAvailabilityClamp
+
+	// DefaultEvictionReleasePolicy is when the NodeResourceTopologyMatch cache releases the resources of the terminating pods
+	DefaultEvictionReleasePolicy = EvictionReleaseOnDeletion
+
+	// DefaultMaxZonesForSubsetSearch is the maximum number of NUMA zones searched exhaustively by the restricted policy
+	DefaultMaxZonesForSubsetSearch int64 = 8
+
 	// Defaults for NetworkOverhead
 	// DefaultWeightsName contains the default costs to be used by networkAware plugins
 	DefaultWeightsName = "UserDefined"
@@ -151,6 +176,43 @@ func SetDefaults_NodeResourceTopologyMatchArgs(obj *NodeResourceTopologyMatchArg
 			obj.ScoringStrategy.Resources[i].Weight = 1
 		}
 	}
+
+	// an explicitly empty list means nothing is ignored
+	if obj.IgnoredResources == nil {
+		obj.IgnoredResources = append([]string{}, defaultIgnoredResources...)
+	}
+
+	if obj.MaxZonesForSubsetSearch == nil {
+		obj.MaxZonesForSubsetSearch = &DefaultMaxZonesForSubsetSearch
+	}
+
+	// an explicitly empty map means nothing is rounded
+	if obj.ResourceRoundingPolicies == nil {
+		obj.ResourceRoundingPolicies = make(map[string]ResourceRoundingPolicy, len(defaultResourceRoundingPolicies))
+		for name, policy := range defaultResourceRoundingPolicies {
+			obj.ResourceRoundingPolicies[name] = policy
+		}
+	}
+
+	if obj.MissingNRTPolicy == "" {
+		obj.MissingNRTPolicy = DefaultMissingNRTPolicy
+	}
+
+	if obj.NegativeAvailabilityPolicy == "" {
+		obj.NegativeAvailabilityPolicy = DefaultNegativeAvailabilityPolicy
+	}
+
+	if obj.EvictionReleasePolicy == "" {
+		obj.EvictionReleasePolicy = DefaultEvictionReleasePolicy
+	}
+
+	// an explicitly empty map means the availability is not rounded
+	if obj.ResourceGranularity == nil {
+		obj.ResourceGranularity = make(map[string]resource.Quantity, len(defaultResourceGranularity))
+		for name, qty := range defaultResourceGranularity {
+			obj.ResourceGranularity[name] = qty.DeepCopy()
+		}
+	}
 }
 
 // SetDefaults_PreemptionTolerationArgs reuses SetDefaults_DefaultPreemptionArgs
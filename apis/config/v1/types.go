@@ -18,6 +18,7 @@ package v1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schedulerconfigv1 "k8s.io/kube-scheduler/config/v1"
 )
@@ -134,11 +135,55 @@ const (
 	LeastNUMANodes ScoringStrategyType = "LeastNUMANodes"
 )
 
+// ResourceRoundingPolicy is a "string" type.
+type ResourceRoundingPolicy string
+
+const (
+	// RoundingCeil rounds the requests up to whole units, like the whole CPUs the kubelet allocates exclusively
+	RoundingCeil ResourceRoundingPolicy = "Ceil"
+	// RoundingFloor rounds the requests down to whole units
+	RoundingFloor ResourceRoundingPolicy = "Floor"
+)
+
+// MissingNRTPolicy is a "string" type.
+type MissingNRTPolicy string
+
+const (
+	// MissingNRTSkip lets the nodes without NRT data pass the filter, with the lowest score
+	MissingNRTSkip MissingNRTPolicy = "Skip"
+	// MissingNRTReject filters out the nodes without NRT data
+	MissingNRTReject MissingNRTPolicy = "Reject"
+	// MissingNRTError fails the filter with an error on the nodes without NRT data
+	MissingNRTError MissingNRTPolicy = "Error"
+)
+
 type ScoringStrategy struct {
 	Type      ScoringStrategyType              `json:"type,omitempty"`
 	Resources []schedulerconfigv1.ResourceSpec `json:"resources,omitempty"`
 }
 
+// NegativeAvailabilityPolicy is a "string" type.
+type NegativeAvailabilityPolicy string
+
+const (
+	// NegativeAvailabilityClamp clamps the negative availability reported by the nodes to zero
+	NegativeAvailabilityClamp NegativeAvailabilityPolicy = "Clamp"
+	// NegativeAvailabilityReject rejects the NRT updates reporting a negative availability
+	NegativeAvailabilityReject NegativeAvailabilityPolicy = "Reject"
+)
+
+// EvictionReleasePolicy is a "string" type.
+type EvictionReleasePolicy string
+
+const (
+	// EvictionReleaseOnDeletion releases the resources of the terminating pods once they are gone
+	EvictionReleaseOnDeletion EvictionReleasePolicy = "OnDeletion"
+	// EvictionReleaseImmediate releases the resources of the terminating pods as soon as they start terminating
+	EvictionReleaseImmediate EvictionReleasePolicy = "Immediate"
+	// EvictionReleaseAtGraceExpiry releases the resources of the terminating pods once their grace period expires
+	EvictionReleaseAtGraceExpiry EvictionReleasePolicy = "AtGraceExpiry"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // NodeResourceTopologyMatchArgs holds arguments used to configure the NodeResourceTopologyMatch plugin
@@ -149,6 +194,36 @@ type NodeResourceTopologyMatchArgs struct {
 	ScoringStrategy *ScoringStrategy `json:"scoringStrategy,omitempty"`
 	// If > 0, enables the caching facilities of the reserve plugin - which must be enabled
 	CacheResyncPeriodSeconds *int64 `json:"cacheResyncPeriodSeconds,omitempty"`
+	// If > 0, nodes whose podset fingerprint mismatches more than this many consecutive times are served in passthrough mode
+	CacheResyncMismatchThreshold *int64 `json:"cacheResyncMismatchThreshold,omitempty"`
+	// If > 0, the cached availability of all the nodes is recomputed from the apiserver data and the reserved pods this often
+	CacheRebuildPeriodSeconds *int64 `json:"cacheRebuildPeriodSeconds,omitempty"`
+	// Resources which are not NUMA-local, and thus are skipped entirely in the NUMA zone accounting
+	IgnoredResources []string `json:"ignoredResources,omitempty"`
+	// Resources the overreserve discount applies to. If empty, the discount applies to all the resources
+	DiscountedResources []string `json:"discountedResources,omitempty"`
+	// Maximum number of NUMA zones the restricted policy searches exhaustively. Past it, a greedy heuristic is used
+	MaxZonesForSubsetSearch *int64 `json:"maxZonesForSubsetSearch,omitempty"`
+	// Seconds the NRT data of a node newly seen by the cache must stay stable before being trusted
+	NRTTrustDelaySeconds *int64 `json:"nrtTrustDelaySeconds,omitempty"`
+	// Rounding of the requests of each resource in the fit check of the single-numa-node policy
+	ResourceRoundingPolicies map[string]ResourceRoundingPolicy `json:"resourceRoundingPolicies,omitempty"`
+	// Resources which may be allocated across NUMA zones, so they only need to fit the sum of the zones
+	SpillableResources []string `json:"spillableResources,omitempty"`
+	// How the nodes without NRT data are handled by the filter
+	MissingNRTPolicy MissingNRTPolicy `json:"missingNRTPolicy,omitempty"`
+	// How the cache handles the negative availability reported by the nodes
+	NegativeAvailabilityPolicy NegativeAvailabilityPolicy `json:"negativeAvailabilityPolicy,omitempty"`
+	// If not empty, the cache accounts only the pods bound by these schedulers
+	AccountedSchedulerNames []string `json:"accountedSchedulerNames,omitempty"`
+	// If true, the terminating pods replaced by a pod of the same controller on the same node are not accounted
+	DiscountTerminatingPods *bool `json:"discountTerminatingPods,omitempty"`
+	// When the cache releases the resources of the terminating pods
+	EvictionReleasePolicy EvictionReleasePolicy `json:"evictionReleasePolicy,omitempty"`
+	// Minimum allocation unit of each resource in the availability the cache reports
+	ResourceGranularity map[string]resource.Quantity `json:"resourceGranularity,omitempty"`
+	// If true, the overbooked zones metric is labeled with the node name
+	OverbookedZonesMetricByNode *bool `json:"overbookedZonesMetricByNode,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
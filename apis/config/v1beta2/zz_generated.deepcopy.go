@@ -23,6 +23,7 @@ package v1beta2
 
 import (
 	v1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	configv1beta2 "k8s.io/kube-scheduler/config/v1beta2"
 )
@@ -148,6 +149,70 @@ func (in *NodeResourceTopologyMatchArgs) DeepCopyInto(out *NodeResourceTopologyM
 		*out = new(int64)
 		**out = **in
 	}
+	if in.CacheResyncMismatchThreshold != nil {
+		in, out := &in.CacheResyncMismatchThreshold, &out.CacheResyncMismatchThreshold
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CacheRebuildPeriodSeconds != nil {
+		in, out := &in.CacheRebuildPeriodSeconds, &out.CacheRebuildPeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.IgnoredResources != nil {
+		in, out := &in.IgnoredResources, &out.IgnoredResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscountedResources != nil {
+		in, out := &in.DiscountedResources, &out.DiscountedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxZonesForSubsetSearch != nil {
+		in, out := &in.MaxZonesForSubsetSearch, &out.MaxZonesForSubsetSearch
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NRTTrustDelaySeconds != nil {
+		in, out := &in.NRTTrustDelaySeconds, &out.NRTTrustDelaySeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ResourceRoundingPolicies != nil {
+		in, out := &in.ResourceRoundingPolicies, &out.ResourceRoundingPolicies
+		*out = make(map[string]ResourceRoundingPolicy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SpillableResources != nil {
+		in, out := &in.SpillableResources, &out.SpillableResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AccountedSchedulerNames != nil {
+		in, out := &in.AccountedSchedulerNames, &out.AccountedSchedulerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscountTerminatingPods != nil {
+		in, out := &in.DiscountTerminatingPods, &out.DiscountTerminatingPods
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ResourceGranularity != nil {
+		in, out := &in.ResourceGranularity, &out.ResourceGranularity
+		*out = make(map[string]resource.Quantity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.OverbookedZonesMetricByNode != nil {
+		in, out := &in.OverbookedZonesMetricByNode, &out.OverbookedZonesMetricByNode
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
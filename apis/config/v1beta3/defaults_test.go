@@ -142,6 +142,17 @@ func TestSchedulingDefaults(t *testing.T) {
 					Type:      LeastAllocated,
 					Resources: defaultResourceSpec,
 				},
+				IgnoredResources:        []string{"ephemeral-storage", "pods"},
+				MaxZonesForSubsetSearch: &DefaultMaxZonesForSubsetSearch,
+				ResourceRoundingPolicies: map[string]ResourceRoundingPolicy{
+					"cpu": RoundingCeil,
+				},
+				MissingNRTPolicy:           MissingNRTSkip,
+				NegativeAvailabilityPolicy: NegativeAvailabilityClamp,
+				EvictionReleasePolicy:      EvictionReleaseOnDeletion,
+				ResourceGranularity: map[string]resource.Quantity{
+					"cpu": resource.MustParse("1"),
+				},
 			},
 		},
 		{
@@ -18,6 +18,7 @@ package v1beta3
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schedulerconfigv1beta3 "k8s.io/kube-scheduler/config/v1beta3"
 )
@@ -134,11 +135,55 @@ const (
 	LeastNUMANodes ScoringStrategyType = "LeastNUMANodes"
 )
 
+// ResourceRoundingPolicy is a "string" type.
+type ResourceRoundingPolicy string
+
+const (
+	// RoundingCeil rounds the requests up to whole units, like the whole CPUs the kubelet allocates exclusively
+	RoundingCeil ResourceRoundingPolicy = "Ceil"
+	// RoundingFloor rounds the requests down to whole units
+	RoundingFloor ResourceRoundingPolicy = "Floor"
+)
+
+// MissingNRTPolicy is a "string" type.
+type MissingNRTPolicy string
+
+const (
+	// MissingNRTSkip lets the nodes without NRT data pass the filter, with the lowest score
+	MissingNRTSkip MissingNRTPolicy = "Skip"
+	// MissingNRTReject filters out the nodes without NRT data
+	MissingNRTReject MissingNRTPolicy = "Reject"
+	// MissingNRTError fails the filter with an error on the nodes without NRT data
+	MissingNRTError MissingNRTPolicy = "Error"
+)
+
 type ScoringStrategy struct {
 	Type      ScoringStrategyType                   `json:"type,omitempty"`
 	Resources []schedulerconfigv1beta3.ResourceSpec `json:"resources,omitempty"`
 }
 
+// NegativeAvailabilityPolicy is a "string" type.
+type NegativeAvailabilityPolicy string
+
+const (
+	// NegativeAvailabilityClamp clamps the negative availability reported by the nodes to zero
+	NegativeAvailabilityClamp NegativeAvailabilityPolicy = "Clamp"
+	// NegativeAvailabilityReject rejects the NRT updates reporting a negative availability
+	NegativeAvailabilityReject NegativeAvailabilityPolicy = "Reject"
+)
+
+// EvictionReleasePolicy is a "string" type.
+type EvictionReleasePolicy string
+
+const (
+	// EvictionReleaseOnDeletion releases the resources of the terminating pods once they are gone
+	EvictionReleaseOnDeletion EvictionReleasePolicy = "OnDeletion"
+	// EvictionReleaseImmediate releases the resources of the terminating pods as soon as they start terminating
+	EvictionReleaseImmediate EvictionReleasePolicy = "Immediate"
+	// EvictionReleaseAtGraceExpiry releases the resources of the terminating pods once their grace period expires
+	EvictionReleaseAtGraceExpiry EvictionReleasePolicy = "AtGraceExpiry"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // NodeResourceTopologyMatchArgs holds arguments used to configure the NodeResourceTopologyMatch plugin
@@ -152,6 +197,79 @@ type NodeResourceTopologyMatchArgs struct {
 	// implicitely enables the caching. If zero, disables the caching entirely.
 	// If the cache is enabled, the Reserve plugin must be enabled.
 	CacheResyncPeriodSeconds *int64 `json:"cacheResyncPeriodSeconds,omitempty"`
+	// CacheResyncMismatchThreshold sets how many consecutive times the podset fingerprint
+	// of a node can mismatch during the cache resync before the cached data of the node
+	// is discarded, and the node is served in passthrough mode until the next match.
+	// If zero or not present, nodes are never switched to passthrough mode.
+	CacheResyncMismatchThreshold *int64 `json:"cacheResyncMismatchThreshold,omitempty"`
+	// CacheRebuildPeriodSeconds sets the period, in seconds, of the full recomputation
+	// of the cached availability of all the nodes, from the NodeResourceTopology data
+	// in the apiserver and the pods reserved by the scheduler, to bound the accounting drift.
+	// If zero or not present, the availability is never fully recomputed.
+	CacheRebuildPeriodSeconds *int64 `json:"cacheRebuildPeriodSeconds,omitempty"`
+	// IgnoredResources lists the resources which are not NUMA-local, like ephemeral-storage,
+	// and thus are skipped entirely in the NUMA zone accounting and in the fit checks.
+	// If not present, defaults to ephemeral-storage and pods. Set to an empty list to ignore nothing.
+	IgnoredResources []string `json:"ignoredResources,omitempty"`
+	// DiscountedResources lists the resources the overreserve discount of the reserved pods applies to.
+	// The availability of the other resources is reported as the nodes advertise it. Requires the cache.
+	// If empty or not present, the discount applies to all the resources.
+	DiscountedResources []string `json:"discountedResources,omitempty"`
+	// MaxZonesForSubsetSearch sets the maximum number of NUMA zones of a node for which the restricted
+	// policy searches all the zone sets to find the narrowest one fitting the request. The search cost is
+	// exponential in the number of zones, so on nodes with more zones a greedy heuristic is used instead.
+	// If not present, defaults to 8.
+	MaxZonesForSubsetSearch *int64 `json:"maxZonesForSubsetSearch,omitempty"`
+	// NRTTrustDelaySeconds sets how long the NRT data of a node newly seen by the cache, like a node whose
+	// topology exporter restarted, must keep the same podset fingerprint before the cache uses it. Until
+	// then, the NRT data is used as reported, like with the cache disabled. If not present or zero, the data
+	// is trusted immediately. Has no effect if the cache is disabled.
+	NRTTrustDelaySeconds *int64 `json:"nrtTrustDelaySeconds,omitempty"`
+	// ResourceRoundingPolicies sets how the requests of each resource are rounded to whole units in the fit
+	// check of the single-numa-node policy, either "Ceil" or "Floor". The kubelet allocates whole CPUs, so
+	// a request of 2500m CPUs consumes 3 CPUs of the zone with "Ceil". If not present, the CPU requests are
+	// rounded up; an explicitly empty map disables the rounding.
+	ResourceRoundingPolicies map[string]ResourceRoundingPolicy `json:"resourceRoundingPolicies,omitempty"`
+	// SpillableResources lists the resources a pod may get from more than one NUMA zone, like memory for
+	// workloads which tolerate the remote accesses. Their requests must fit the sum of the zones of the node,
+	// while the other resources still need to fit a zone as the policy of the node requires. Has no effect
+	// on the resources of the pods not in the Guaranteed QoS class, which never need to fit a single zone.
+	SpillableResources []string `json:"spillableResources,omitempty"`
+	// MissingNRTPolicy sets how the filter handles the nodes without NRT data: "Skip" lets them pass,
+	// with the lowest score, "Reject" filters them out and "Error" fails the filter with an error, which
+	// makes the scheduling attempt fail. Defaults to "Skip".
+	MissingNRTPolicy MissingNRTPolicy `json:"missingNRTPolicy,omitempty"`
+	// NegativeAvailabilityPolicy sets how the cache handles the NRT data reporting a negative availability,
+	// which is an exporter bug: "Clamp" uses the data with the negative availability clamped to zero, while
+	// "Reject" discards the whole update, and the cache keeps the data it has. Defaults to "Clamp".
+	// Has no effect if the cache is disabled.
+	NegativeAvailabilityPolicy NegativeAvailabilityPolicy `json:"negativeAvailabilityPolicy,omitempty"`
+	// AccountedSchedulerNames lists the schedulers whose pods are accounted by the cache when it computes
+	// the resources consumed on the nodes. If empty or not present, all the pods bound to the nodes are
+	// accounted, regardless of the scheduler which bound them, because in clusters with more than one
+	// scheduler the pods bound by the other schedulers still consume the node resources.
+	// Has no effect if the cache is disabled.
+	AccountedSchedulerNames []string `json:"accountedSchedulerNames,omitempty"`
+	// DiscountTerminatingPods makes the cache stop accounting a terminating pod once a pod with the same
+	// controller, like the new pod of a rolling update, is accounted on the same node, so the resources the
+	// old pod is releasing are not counted twice. If not present, defaults to false.
+	// Has no effect if the cache is disabled.
+	DiscountTerminatingPods *bool `json:"discountTerminatingPods,omitempty"`
+	// EvictionReleasePolicy sets when the cache releases the resources of the terminating pods, like the
+	// evicted pods: "OnDeletion" once the pods are gone, "Immediate" as soon as they start terminating and
+	// "AtGraceExpiry" once their grace period expires. Defaults to "OnDeletion".
+	// Has no effect if the cache is disabled.
+	EvictionReleasePolicy EvictionReleasePolicy `json:"evictionReleasePolicy,omitempty"`
+	// ResourceGranularity sets the minimum allocation unit of each resource, like whole CPUs. The cache
+	// rounds down the available resources of each zone to a multiple of it, so a zone whose reservations
+	// left 1500m CPUs available reports 1 CPU. If not present, the CPUs are accounted in whole units; an
+	// explicitly empty map disables the rounding. Has no effect if the cache is disabled.
+	ResourceGranularity map[string]resource.Quantity `json:"resourceGranularity,omitempty"`
+	// OverbookedZonesMetricByNode labels the overbooked zones metric of the cache with the node name, to
+	// find the nodes whose reported availability disagrees with the scheduler. The label makes the metric
+	// cardinality grow with the cluster size. If not present, defaults to false.
+	// Has no effect if the cache is disabled.
+	OverbookedZonesMetricByNode *bool `json:"overbookedZonesMetricByNode,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -25,6 +25,7 @@ import (
 	unsafe "unsafe"
 
 	corev1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -288,6 +289,33 @@ func autoConvert_v1beta3_NodeResourceTopologyMatchArgs_To_config_NodeResourceTop
 	if err := v1.Convert_Pointer_int64_To_int64(&in.CacheResyncPeriodSeconds, &out.CacheResyncPeriodSeconds, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.CacheResyncMismatchThreshold, &out.CacheResyncMismatchThreshold, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.CacheRebuildPeriodSeconds, &out.CacheRebuildPeriodSeconds, s); err != nil {
+		return err
+	}
+	out.IgnoredResources = *(*[]string)(unsafe.Pointer(&in.IgnoredResources))
+	out.DiscountedResources = *(*[]string)(unsafe.Pointer(&in.DiscountedResources))
+	if err := v1.Convert_Pointer_int64_To_int64(&in.MaxZonesForSubsetSearch, &out.MaxZonesForSubsetSearch, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.NRTTrustDelaySeconds, &out.NRTTrustDelaySeconds, s); err != nil {
+		return err
+	}
+	out.ResourceRoundingPolicies = *(*map[string]config.ResourceRoundingPolicy)(unsafe.Pointer(&in.ResourceRoundingPolicies))
+	out.SpillableResources = *(*[]string)(unsafe.Pointer(&in.SpillableResources))
+	out.MissingNRTPolicy = config.MissingNRTPolicy(in.MissingNRTPolicy)
+	out.NegativeAvailabilityPolicy = config.NegativeAvailabilityPolicy(in.NegativeAvailabilityPolicy)
+	out.AccountedSchedulerNames = *(*[]string)(unsafe.Pointer(&in.AccountedSchedulerNames))
+	if err := v1.Convert_Pointer_bool_To_bool(&in.DiscountTerminatingPods, &out.DiscountTerminatingPods, s); err != nil {
+		return err
+	}
+	out.EvictionReleasePolicy = config.EvictionReleasePolicy(in.EvictionReleasePolicy)
+	out.ResourceGranularity = *(*map[string]resource.Quantity)(unsafe.Pointer(&in.ResourceGranularity))
+	if err := v1.Convert_Pointer_bool_To_bool(&in.OverbookedZonesMetricByNode, &out.OverbookedZonesMetricByNode, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -296,6 +324,33 @@ func autoConvert_config_NodeResourceTopologyMatchArgs_To_v1beta3_NodeResourceTop
 	if err := v1.Convert_int64_To_Pointer_int64(&in.CacheResyncPeriodSeconds, &out.CacheResyncPeriodSeconds, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.CacheResyncMismatchThreshold, &out.CacheResyncMismatchThreshold, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.CacheRebuildPeriodSeconds, &out.CacheRebuildPeriodSeconds, s); err != nil {
+		return err
+	}
+	out.IgnoredResources = *(*[]string)(unsafe.Pointer(&in.IgnoredResources))
+	out.DiscountedResources = *(*[]string)(unsafe.Pointer(&in.DiscountedResources))
+	if err := v1.Convert_int64_To_Pointer_int64(&in.MaxZonesForSubsetSearch, &out.MaxZonesForSubsetSearch, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.NRTTrustDelaySeconds, &out.NRTTrustDelaySeconds, s); err != nil {
+		return err
+	}
+	out.ResourceRoundingPolicies = *(*map[string]ResourceRoundingPolicy)(unsafe.Pointer(&in.ResourceRoundingPolicies))
+	out.SpillableResources = *(*[]string)(unsafe.Pointer(&in.SpillableResources))
+	out.MissingNRTPolicy = MissingNRTPolicy(in.MissingNRTPolicy)
+	out.NegativeAvailabilityPolicy = NegativeAvailabilityPolicy(in.NegativeAvailabilityPolicy)
+	out.AccountedSchedulerNames = *(*[]string)(unsafe.Pointer(&in.AccountedSchedulerNames))
+	if err := v1.Convert_bool_To_Pointer_bool(&in.DiscountTerminatingPods, &out.DiscountTerminatingPods, s); err != nil {
+		return err
+	}
+	out.EvictionReleasePolicy = EvictionReleasePolicy(in.EvictionReleasePolicy)
+	out.ResourceGranularity = *(*map[string]resource.Quantity)(unsafe.Pointer(&in.ResourceGranularity))
+	if err := v1.Convert_bool_To_Pointer_bool(&in.OverbookedZonesMetricByNode, &out.OverbookedZonesMetricByNode, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -23,6 +23,7 @@ package config
 
 import (
 	v1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	apisconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 )
@@ -129,6 +130,40 @@ func (in *NodeResourceTopologyMatchArgs) DeepCopyInto(out *NodeResourceTopologyM
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ScoringStrategy.DeepCopyInto(&out.ScoringStrategy)
+	if in.IgnoredResources != nil {
+		in, out := &in.IgnoredResources, &out.IgnoredResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscountedResources != nil {
+		in, out := &in.DiscountedResources, &out.DiscountedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceRoundingPolicies != nil {
+		in, out := &in.ResourceRoundingPolicies, &out.ResourceRoundingPolicies
+		*out = make(map[string]ResourceRoundingPolicy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SpillableResources != nil {
+		in, out := &in.SpillableResources, &out.SpillableResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AccountedSchedulerNames != nil {
+		in, out := &in.AccountedSchedulerNames, &out.AccountedSchedulerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceGranularity != nil {
+		in, out := &in.ResourceGranularity, &out.ResourceGranularity
+		*out = make(map[string]resource.Quantity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+)
+
+// AnnotationAlignedContainers is the pod annotation listing the containers which need all their resources, e.g. their
+// devices and their CPUs, on the same NUMA zone, regardless of the policy of the node. Value is a comma-separated list
+// of container names. The other containers of the pod are placed as the policy of the node allows.
+const AnnotationAlignedContainers = "noderesourcetopology/aligned-containers"
+
+// alignedContainerNames returns the names of the containers of the given pod requiring their resources to be aligned.
+func alignedContainerNames(pod *v1.Pod) sets.String {
+	names := sets.NewString()
+	if pod == nil {
+		return names
+	}
+	val, ok := pod.Annotations[AnnotationAlignedContainers]
+	if !ok {
+		return names
+	}
+	for _, name := range strings.Split(val, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		names.Insert(name)
+	}
+	return names
+}
+
+// alignedContainersHandler admits the pod only if each of the given containers fits in a single NUMA zone. The containers
+// are placed in order, so the resources of a container are not available to the following ones. The containers not
+// listed are not checked here. This is enforced by the scheduler only; the kubelet is not aware of the alignment.
+func alignedContainersHandler(pod *v1.Pod, names sets.String, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+	klog.V(5).InfoS("Aligned containers handler")
+
+	nodes := createNUMANodeList(zones)
+	qos := v1qos.GetPodQOS(pod)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes("aligned containers handler NUMA resources", nodeInfo.Node().Name, nodes)
+
+	for _, container := range pod.Spec.Containers {
+		if !names.Has(container.Name) {
+			continue
+		}
+		logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, container.Name)
+		klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, container.Resources.Requests)...)
+
+		numaID, match := resourcesAvailableInAnyNUMANodes(logID, nodes, container.Resources.Requests, qos, nodeInfo)
+		if !match {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot align container: %s", container.Name))
+		}
+		subtractFromNUMA(nodes, numaID, container)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestAlignedContainerNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    sets.String
+	}{
+		{
+			name:     "no annotations",
+			expected: sets.NewString(),
+		},
+		{
+			name:        "single container",
+			annotations: map[string]string{AnnotationAlignedContainers: "cnt-1"},
+			expected:    sets.NewString("cnt-1"),
+		},
+		{
+			name:        "multiple containers with spaces",
+			annotations: map[string]string{AnnotationAlignedContainers: "cnt-1, cnt-2,,"},
+			expected:    sets.NewString("cnt-1", "cnt-2"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := alignedContainerNames(pod); !got.Equal(tt.expected) {
+				t.Errorf("got %v expected %v", got.List(), tt.expected.List())
+			}
+		})
+	}
+}
+
+func TestNodeResourceTopologyAlignedContainers(t *testing.T) {
+	makeNRT := func(name, cpuWithDevice string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: name},
+			TopologyPolicies: []string{string(topologyv1alpha1.BestEffortContainerLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, cpuWithDevice, cpuWithDevice),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+						MakeTopologyResInfo(nicResourceName, "1", "1"),
+					},
+				},
+			},
+		}
+	}
+	// the device is on a zone without enough cpus for the first container
+	smallNRT := makeNRT("small", "2")
+	largeNRT := makeNRT("large", "4")
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(smallNRT)
+	fakeInformer.Informer().GetStore().Add(largeNRT)
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	makeTestPod := func(aligned string) *v1.Pod {
+		pod := makePod("testpod", withMultiContainers([]v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+				nicResourceName:   resource.MustParse("1"),
+			},
+			{
+				v1.ResourceCPU:    resource.MustParse("1"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		}))
+		if aligned != "" {
+			pod.Annotations = map[string]string{AnnotationAlignedContainers: aligned}
+		}
+		return pod
+	}
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		aligned    string
+		wantStatus *framework.Status
+	}{
+		{
+			name: "no alignment requested",
+			nrt:  smallNRT,
+		},
+		{
+			name:       "device container must be aligned",
+			nrt:        smallNRT,
+			aligned:    "cnt-1",
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align container: cnt-1"),
+		},
+		{
+			name:    "only the container without devices must be aligned",
+			nrt:     smallNRT,
+			aligned: "cnt-2",
+		},
+		{
+			name:    "device container aligned on a larger zone",
+			nrt:     largeNRT,
+			aligned: "cnt-1",
+		},
+		{
+			name:    "both containers aligned on a larger zone",
+			nrt:     largeNRT,
+			aligned: "cnt-1,cnt-2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), makeTestPod(tt.aligned), nodeInfo)
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
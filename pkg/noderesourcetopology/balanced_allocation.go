@@ -24,7 +24,7 @@ import (
 	"gonum.org/v1/gonum/stat"
 )
 
-func balancedAllocationScoreStrategy(requested, allocatable v1.ResourceList, resourceToWeightMap resourceToWeightMap) int64 {
+func balancedAllocationScoreStrategy(requested, allocatable, _ v1.ResourceList, resourceToWeightMap resourceToWeightMap) int64 {
 	resourceFractions := make([]float64, 0)
 
 	// We don't care what kind of resources are being requested, we just iterate all of them.
@@ -27,6 +27,7 @@ type Interface interface {
 	// It will be used as the source of truth across the Pod's scheduling cycle.
 	// Over-reserved resources are the resources consumed by pods scheduled to that node after the last update
 	// of NRT pertaining to the same node, pessimistically overallocated on ALL the NUMA zones of the node.
+	// These include the pods reserved but not yet bound, so the pods scheduled in a row see each other.
 	// The pod argument is used only for logging purposes.
 	// Returns a boolean to signal the caller if the NRT data is clean. If false, then the node has foreign
 	// Pods detected - so it should be ignored or handled differently by the caller.
@@ -47,13 +48,30 @@ type Interface interface {
 	// The former is a always-fail, the latter is a always-succeed.
 	NodeHasForeignPods(nodeName string, pod *corev1.Pod)
 
+	// HasForeignPods tells if the node is currently marked as running foreign pods, thus pending a resync.
+	// Callers can use this information to be stricter (or looser) with nodes with unknown occupants.
+	HasForeignPods(nodeName string) bool
+
 	// ReserveNodeResources add the resources requested by a pod to the assumed resources for the node on which the pod
 	// is scheduled on. This is a prerequesite for the pessimistic overallocation tracking.
 	// Additionally, this function resets the discarded counter for the same node. Being able to handle a pod means
 	// that this node has still available resources. If a node was previously discarded and then cleared, we interpret
 	// this sequence of events as the previous pod required too much - a possible and benign condition.
-	ReserveNodeResources(nodeName string, pod *corev1.Pod)
+	// Reserving an already reserved pod is a no-op. Returns true if the pod was already reserved, false otherwise.
+	// Returns error if the pod requests more resources than the zones of the node can ever provide, without reserving it.
+	// If target zones are given, the pod is known to be allocated from these NUMA zones, so its resources are subtracted
+	// only from them instead of from all the NUMA zones of the node.
+	ReserveNodeResources(nodeName string, pod *corev1.Pod, targetZones ...string) (bool, error)
 
 	// UnreserveNodeResources decrement from the node assumed resources the resources required by the given pod.
-	UnreserveNodeResources(nodeName string, pod *corev1.Pod)
+	// Unreserving a pod not reserved is a no-op. Returns true if the pod was reserved and is now released, false otherwise.
+	UnreserveNodeResources(nodeName string, pod *corev1.Pod) bool
+
+	// SetPodZones records the NUMA zones the given pod, reserved on the given node, is expected to be allocated from.
+	// Returns false if the pod is not reserved on the node.
+	SetPodZones(nodeName string, pod *corev1.Pod, zoneNames []string) bool
+
+	// OwnerPodsPerZone returns how many pods owned by the same controller of the given pod are expected to be allocated
+	// from each NUMA zone of the given node. Returns nil if the pod has no controller, or nothing is known.
+	OwnerPodsPerZone(nodeName string, pod *corev1.Pod) map[string]int
 }
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	"k8s.io/klog/v2"
+)
+
+// ConsistentNodes returns the names of the nodes, sorted, whose cached NRT data carries a podset fingerprint matching
+// the one computed from the pods the scheduler knows to be on the node, including the reserved pods. The data of
+// these nodes accounts for all their pods, so it is trustworthy as-is, without the overreserve discount.
+// Nodes whose data carries no fingerprint can't be verified, so they are never reported. Meant for observability.
+func (ov *OverReserve) ConsistentNodes() []string {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	var nodeNames []string
+	for nodeName, nrt := range ov.nrts.data {
+		pfpExpected := podFingerprintForNodeTopology(nrt)
+		if pfpExpected == "" {
+			continue
+		}
+		logID := "consistency-" + nodeName
+		if err := checkPodFingerprintForNode(logID, ov.nodeIndexer, nodeName, pfpExpected); err != nil {
+			klog.V(6).InfoS("nrtcache: inconsistent NodeTopology", "logID", logID, "node", nodeName, "error", err)
+			continue
+		}
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	return nodeNames
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+)
+
+func TestConsistentNodes(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	if nodes := nrtCache.ConsistentNodes(); len(nodes) != 0 {
+		t.Fatalf("unexpected consistent nodes on empty cache: %v", nodes)
+	}
+
+	podsByNode := map[string][]*corev1.Pod{
+		"node-a": {makeOwnedPod("pod-a0", types.UID("rs-0"), "2"), makeOwnedPod("pod-a1", types.UID("rs-0"), "2")},
+		"node-b": {makeOwnedPod("pod-b0", types.UID("rs-0"), "2")},
+		"node-c": {makeOwnedPod("pod-c0", types.UID("rs-0"), "2")},
+		"node-d": {makeOwnedPod("pod-d0", types.UID("rs-0"), "2")},
+	}
+	fingerprints := make(map[string]string)
+	for nodeName, pods := range podsByNode {
+		fp := podfingerprint.NewFingerprint(len(pods))
+		for _, pod := range pods {
+			pod.Spec.NodeName = nodeName
+			fakeIndex.Add(pod)
+			fp.Add(pod.Namespace, pod.Name)
+		}
+		fingerprints[nodeName] = fp.Sign()
+	}
+	// node-b runs a pod the node data doesn't know about yet
+	extraPod := makeOwnedPod("pod-b1", types.UID("rs-0"), "2")
+	extraPod.Spec.NodeName = "node-b"
+	fakeIndex.Add(extraPod)
+
+	for _, nodeName := range []string{"node-a", "node-b", "node-c", "node-d"} {
+		nrt := makeTwoZonesTestTopology()
+		nrt.Name = nodeName
+		// node-d reports no fingerprint, so it can't be verified
+		if nodeName != "node-d" {
+			nrt.Annotations = map[string]string{
+				podfingerprint.Annotation: fingerprints[nodeName],
+			}
+		}
+		nrtCache.Store().Update(nrt)
+	}
+
+	got := nrtCache.ConsistentNodes()
+	expected := []string{"node-a", "node-c"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected consistent nodes: got %v expected %v", got, expected)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ContentionStats reports how the lock protecting the cache state is acquired.
+type ContentionStats struct {
+	// Acquisitions is how many times the lock was acquired.
+	Acquisitions int64
+	// Contended is how many acquisitions had to wait because the lock was held.
+	Contended int64
+	// TotalWait is the time spent waiting across all the contended acquisitions.
+	TotalWait time.Duration
+	// MaxWait is the longest time spent waiting for a single acquisition.
+	MaxWait time.Duration
+}
+
+// instrumentedMutex is a sync.Mutex which tracks how long the callers wait to acquire it.
+// The uncontended fast path only adds an atomic increment.
+type instrumentedMutex struct {
+	mu           sync.Mutex
+	acquisitions atomic.Int64
+	contended    atomic.Int64
+	totalWait    atomic.Int64 // nanoseconds
+	maxWait      atomic.Int64 // nanoseconds
+}
+
+func (im *instrumentedMutex) Lock() {
+	if im.mu.TryLock() {
+		im.acquisitions.Add(1)
+		return
+	}
+	start := time.Now()
+	im.mu.Lock()
+	wait := int64(time.Since(start))
+
+	im.acquisitions.Add(1)
+	im.contended.Add(1)
+	im.totalWait.Add(wait)
+	for {
+		cur := im.maxWait.Load()
+		if wait <= cur || im.maxWait.CompareAndSwap(cur, wait) {
+			break
+		}
+	}
+}
+
+func (im *instrumentedMutex) Unlock() {
+	im.mu.Unlock()
+}
+
+// Stats returns a snapshot of the lock acquisition statistics. Safe to be called concurrently with Lock and Unlock;
+// the fields are read independently, so they may be slightly out of sync with each other.
+func (im *instrumentedMutex) Stats() ContentionStats {
+	return ContentionStats{
+		Acquisitions: im.acquisitions.Load(),
+		Contended:    im.contended.Load(),
+		TotalWait:    time.Duration(im.totalWait.Load()),
+		MaxWait:      time.Duration(im.maxWait.Load()),
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// ZoneResourceDelta is an incremental update of the availability of the resources of a node, which exporters may
+// publish instead of full NRT snapshots.
+type ZoneResourceDelta struct {
+	// Sequence orders the deltas of a node: each delta must carry the sequence number of the previous one plus one.
+	Sequence uint64
+	// Zones maps the zone names to the changes of the availability of their resources. Negative changes mean
+	// less resources available.
+	Zones map[string]corev1.ResourceList
+}
+
+// ApplyDelta adjusts the cached availability of the given node by the given delta, without replacing the whole NRT data.
+// The first delta after a full update is taken as is, because full updates carry no sequence number; later deltas must
+// follow the sequence. If a delta is missed, or doesn't match the cached data, the cached availability can't be trusted:
+// the node is marked as a candidate for resync, and deltas are rejected until the next full update (see FlushNodes).
+// Deltas already applied are ignored. Returns true if the delta was applied, false otherwise.
+func (ov *OverReserve) ApplyDelta(nodeName string, delta ZoneResourceDelta) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	if ov.nodesWithDeltaGaps.IsSet(nodeName) {
+		klog.V(5).InfoS("nrtcache: ignoring delta, waiting for full update", "node", nodeName, "sequence", delta.Sequence)
+		return false
+	}
+	nrt := ov.nrts.GetNRTCopyByNodeName(nodeName)
+	if nrt == nil {
+		return false
+	}
+
+	lastSequence, ok := ov.deltaSequences[nodeName]
+	if ok && delta.Sequence <= lastSequence {
+		klog.V(5).InfoS("nrtcache: ignoring stale delta", "node", nodeName, "sequence", delta.Sequence, "lastSequence", lastSequence)
+		return false
+	}
+	if ok && delta.Sequence != lastSequence+1 {
+		klog.V(3).InfoS("nrtcache: missed deltas", "node", nodeName, "sequence", delta.Sequence, "lastSequence", lastSequence)
+		ov.deltaGap(nodeName)
+		return false
+	}
+
+	for zoneName, changes := range delta.Zones {
+		zone := GetZoneByName(nrt, zoneName)
+		if zone == nil {
+			klog.V(3).InfoS("nrtcache: delta for unknown zone", "node", nodeName, "sequence", delta.Sequence, "zone", zoneName)
+			ov.deltaGap(nodeName)
+			return false
+		}
+		for resName, qty := range changes {
+			zr := zoneResourceInfo(zone, string(resName))
+			if zr == nil {
+				klog.V(3).InfoS("nrtcache: delta for unknown resource", "node", nodeName, "sequence", delta.Sequence, "zone", zoneName, "resource", resName)
+				ov.deltaGap(nodeName)
+				return false
+			}
+			zr.Available.Add(qty)
+		}
+	}
+
+	nrt, ok = sanitizeAvailability(fmt.Sprintf("delta%d", delta.Sequence), nrt, ov.StrictAvailability)
+	if !ok {
+		ov.deltaGap(nodeName)
+		return false
+	}
+	ov.nrts.Update(nrt)
+	ov.deltaSequences[nodeName] = delta.Sequence
+	klog.V(5).InfoS("nrtcache: applied delta", "node", nodeName, "sequence", delta.Sequence)
+	return true
+}
+
+// deltaGap makes the given node wait for a full update, requesting it on the next resync. Must be called with the lock held.
+func (ov *OverReserve) deltaGap(nodeName string) {
+	delete(ov.deltaSequences, nodeName)
+	ov.nodesWithDeltaGaps.Incr(nodeName)
+	ov.nodesMaybeOverreserved.Incr(nodeName)
+}
+
+// zoneResourceInfo returns a pointer to the resource with the given name of the zone, or nil if the zone doesn't report it.
+func zoneResourceInfo(zone *topologyv1alpha1.Zone, name string) *topologyv1alpha1.ResourceInfo {
+	for ri := 0; ri < len(zone.Resources); ri++ {
+		if zone.Resources[ri].Name == name {
+			return &zone.Resources[ri]
+		}
+	}
+	return nil
+}
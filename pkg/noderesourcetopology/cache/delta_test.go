@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func makeCPUDelta(sequence uint64, zoneName, change string) ZoneResourceDelta {
+	return ZoneResourceDelta{
+		Sequence: sequence,
+		Zones: map[string]corev1.ResourceList{
+			zoneName: {corev1.ResourceCPU: resource.MustParse(change)},
+		},
+	}
+}
+
+func cachedZoneCPU(t *testing.T, nrtCache *OverReserve, zoneIdx int) string {
+	t.Helper()
+	nrt := nrtCache.Store().GetNRTCopyByNodeName("node")
+	qty := findResourceInfo(nrt.Zones[zoneIdx].Resources, cpu).Available
+	return qty.String()
+}
+
+func TestApplyDeltaInOrder(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	if nrtCache.ApplyDelta("node", makeCPUDelta(1, "node-0", "-2")) {
+		t.Fatalf("delta applied to unknown node")
+	}
+
+	nrtCache.FlushNodes("testApplyDelta", makeTwoZonesTestTopology())
+	if !nrtCache.ApplyDelta("node", makeCPUDelta(7, "node-0", "-4")) {
+		t.Fatalf("first delta after a full update not applied")
+	}
+	if !nrtCache.ApplyDelta("node", makeCPUDelta(8, "node-1", "-6")) {
+		t.Fatalf("in-order delta not applied")
+	}
+	if !nrtCache.ApplyDelta("node", makeCPUDelta(9, "node-0", "1")) {
+		t.Fatalf("in-order delta not applied")
+	}
+	// already applied
+	if nrtCache.ApplyDelta("node", makeCPUDelta(8, "node-1", "-6")) {
+		t.Fatalf("stale delta applied")
+	}
+
+	if got := cachedZoneCPU(t, nrtCache, 0); got != "17" {
+		t.Errorf("unexpected cpu availability on node-0: %s", got)
+	}
+	if got := cachedZoneCPU(t, nrtCache, 1); got != "14" {
+		t.Errorf("unexpected cpu availability on node-1: %s", got)
+	}
+	if nodes := nrtCache.NodesMaybeOverReserved("testApplyDelta"); len(nodes) != 0 {
+		t.Errorf("unexpected resync candidates: %v", nodes)
+	}
+}
+
+func TestApplyDeltaGap(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	nrtCache.FlushNodes("testApplyDeltaGap", makeTwoZonesTestTopology())
+	if !nrtCache.ApplyDelta("node", makeCPUDelta(1, "node-0", "-2")) {
+		t.Fatalf("first delta not applied")
+	}
+
+	// delta 2 is missed
+	if nrtCache.ApplyDelta("node", makeCPUDelta(3, "node-0", "-2")) {
+		t.Fatalf("delta after a gap applied")
+	}
+	if nodes := nrtCache.NodesMaybeOverReserved("testApplyDeltaGap"); !reflect.DeepEqual(nodes, []string{"node"}) {
+		t.Errorf("node not marked for resync: %v", nodes)
+	}
+	// the following deltas are rejected too, until a full update
+	if nrtCache.ApplyDelta("node", makeCPUDelta(4, "node-0", "-2")) {
+		t.Fatalf("delta applied while waiting for a full update")
+	}
+	if got := cachedZoneCPU(t, nrtCache, 0); got != "18" {
+		t.Errorf("unexpected cpu availability on node-0: %s", got)
+	}
+
+	nrtCache.FlushNodes("testApplyDeltaGap", makeTwoZonesTestTopology())
+	if !nrtCache.ApplyDelta("node", makeCPUDelta(5, "node-0", "-2")) {
+		t.Fatalf("delta after a full update not applied")
+	}
+	if got := cachedZoneCPU(t, nrtCache, 0); got != "18" {
+		t.Errorf("unexpected cpu availability on node-0: %s", got)
+	}
+
+	// deltas not matching the cached data are treated like gaps
+	if nrtCache.ApplyDelta("node", makeCPUDelta(6, "node-2", "-2")) {
+		t.Fatalf("delta for unknown zone applied")
+	}
+	if nrtCache.ApplyDelta("node", makeCPUDelta(7, "node-0", "-2")) {
+		t.Fatalf("delta applied while waiting for a full update")
+	}
+}
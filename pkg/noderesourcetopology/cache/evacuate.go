@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// CanEvacuate checks if all the pods tracked on the given node can be moved to the target nodes, like when
+// planning a node maintenance. Each pod must fit in a single NUMA zone of a target node, considering the resources
+// already reserved on the targets and the pods placed earlier in the same check. Targets with foreign pods are
+// skipped, because their data can't be trusted. Returns true if all the pods fit, and the keys (namespace + "/" + name)
+// of the pods which don't, sorted.
+func (ov *OverReserve) CanEvacuate(nodeName string, targetNodes []string) (bool, []string) {
+	logID := "evacuate-" + nodeName
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	nodeAssumedResources, ok := ov.assumedResources[nodeName]
+	if !ok {
+		return true, nil
+	}
+
+	var targets []*topologyv1alpha1.NodeResourceTopology
+	for _, targetName := range targetNodes {
+		if targetName == nodeName {
+			continue
+		}
+		if ov.nodesWithForeignPods.IsSet(targetName) {
+			klog.V(4).InfoS("nrtcache: evacuate: skipping target with foreign pods", "logID", logID, "node", targetName)
+			continue
+		}
+		nrt := ov.nrts.GetNRTCopyByNodeName(targetName)
+		if nrt == nil {
+			continue
+		}
+		subtractReservedFromZones(logID, nrt)
+		if targetAssumedResources, ok := ov.assumedResources[targetName]; ok {
+			targetAssumedResources.UpdateNRT(logID, nrt)
+		}
+		targets = append(targets, nrt)
+	}
+
+	keys := make([]string, 0, len(nodeAssumedResources.data))
+	for key := range nodeAssumedResources.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var unplaced []string
+	for _, key := range keys {
+		res := nodeAssumedResources.data[key]
+		if !placeOnTargets(logID, key, res, targets) {
+			klog.V(4).InfoS("nrtcache: evacuate: cannot place pod", "logID", logID, "node", nodeName, "pod", key)
+			unplaced = append(unplaced, key)
+		}
+	}
+	return len(unplaced) == 0, unplaced
+}
+
+// placeOnTargets charges the given resources to the first NUMA zone which can fit them across the targets.
+// Returns false if no zone can fit them.
+func placeOnTargets(logID, key string, res corev1.ResourceList, targets []*topologyv1alpha1.NodeResourceTopology) bool {
+	for _, nrt := range targets {
+		for _, zone := range ZonesOfType(nrt, ZoneTypeNUMANode) {
+			if !zoneAvailableFits(res, zone) {
+				continue
+			}
+			klog.V(5).InfoS("nrtcache: evacuate: placed pod", "logID", logID, "pod", key, "node", nrt.Name, "zone", zone.Name)
+			subtractFromZones(logID, nrt.Name, key, res, []*topologyv1alpha1.Zone{zone}, nil)
+			return true
+		}
+	}
+	return false
+}
+
+// zoneAvailableFits returns true if the available resources of the zone can fit the given resources.
+// Resources the zone doesn't report are ignored.
+func zoneAvailableFits(res corev1.ResourceList, zone *topologyv1alpha1.Zone) bool {
+	for _, zr := range zone.Resources {
+		qty, ok := res[corev1.ResourceName(zr.Name)]
+		if ok && zr.Available.Cmp(qty) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// AnnotationExclusiveZone marks pods which want a whole NUMA zone for themselves, like some HPC workloads.
+// These pods can only be placed on zones not used by any other pod, and once placed the zone is accounted
+// as fully consumed, so no other pod will be placed on it.
+const AnnotationExclusiveZone = "noderesourcetopology/exclusive-zone"
+
+// IsExclusiveZonePod returns true if the pod requests an exclusive NUMA zone.
+func IsExclusiveZonePod(pod *corev1.Pod) bool {
+	if pod == nil || pod.Annotations == nil {
+		return false
+	}
+	val, ok := pod.Annotations[AnnotationExclusiveZone]
+	if !ok {
+		return false
+	}
+	exclusive, err := strconv.ParseBool(val)
+	return err == nil && exclusive
+}
+
+// IsZoneUnused returns true if no resource of the zone is consumed, so the zone is not used by any pod.
+// If a resource does not report the allocatable amount, its capacity is used instead.
+func IsZoneUnused(zone topologyv1alpha1.Zone) bool {
+	for _, res := range zone.Resources {
+		total := res.Allocatable
+		if total.IsZero() {
+			total = res.Capacity
+		}
+		if res.Available.Cmp(total) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// claimUnusedZone marks as fully consumed the first unused zone which can fit the given resources.
+// Returns the name of the claimed zone, or false if there is no such zone.
+func claimUnusedZone(zones topologyv1alpha1.ZoneList, res corev1.ResourceList) (string, bool) {
+	for zi := 0; zi < len(zones); zi++ {
+		zone := &zones[zi] // shortcut
+		if !isNUMAZone(*zone) || len(zone.Resources) == 0 || !IsZoneUnused(*zone) || !zoneCanFit(*zone, res) {
+			continue
+		}
+		for ri := 0; ri < len(zone.Resources); ri++ {
+			zone.Resources[ri].Available.Set(0)
+		}
+		return zone.Name, true
+	}
+	return "", false
+}
+
+func zoneCanFit(zone topologyv1alpha1.Zone, res corev1.ResourceList) bool {
+	for _, zr := range zone.Resources {
+		qty, ok := res[corev1.ResourceName(zr.Name)]
+		if !ok {
+			continue
+		}
+		if zr.Available.Cmp(qty) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -68,13 +68,25 @@ func IsForeignPod(pod *corev1.Pod) bool {
 		// nothing to do yet
 		return false
 	}
-	if schedProfileNames.Has(pod.Spec.SchedulerName) {
-		// nothing to do here - we know already about this pod
-		return false
+	for _, schedProfileName := range schedProfileNames.UnsortedList() {
+		if isPodScheduledByUs(pod, schedProfileName) {
+			// nothing to do here - we know already about this pod
+			return false
+		}
 	}
 	return true
 }
 
+// isPodScheduledByUs returns true if the given pod is meant to be scheduled by the scheduler (profile) with the given name.
+// Pods not setting a scheduler name are scheduled by the default scheduler, as the apiserver defaults the field.
+func isPodScheduledByUs(pod *corev1.Pod, schedulerName string) bool {
+	podSchedulerName := pod.Spec.SchedulerName
+	if podSchedulerName == "" {
+		podSchedulerName = corev1.DefaultSchedulerName
+	}
+	return podSchedulerName == schedulerName
+}
+
 // for testing only; NOT thread safe
 func CleanRegisteredSchedulerProfileNames() {
 	schedProfileNames = sets.String{}
@@ -77,6 +77,19 @@ func TestIsForeignPod(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:         "node-default-profile",
+			profileNames: []string{corev1.DefaultSchedulerName},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod",
+					Namespace: "default",
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "random-node",
+				},
+			},
+		},
 		{
 			name:         "node-multi-profile",
 			profileNames: []string{"secondary-scheduler-A", "secondary-scheduler-B", "fancy-scheduler"},
@@ -107,3 +120,52 @@ func TestIsForeignPod(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPodScheduledByUs(t *testing.T) {
+	tests := []struct {
+		name             string
+		podSchedulerName string
+		schedulerName    string
+		expected         bool
+	}{
+		{
+			name:             "matching",
+			podSchedulerName: "secondary-scheduler",
+			schedulerName:    "secondary-scheduler",
+			expected:         true,
+		},
+		{
+			name:             "not-matching",
+			podSchedulerName: "fancy-scheduler",
+			schedulerName:    "secondary-scheduler",
+		},
+		{
+			name:          "empty-is-default",
+			schedulerName: corev1.DefaultSchedulerName,
+			expected:      true,
+		},
+		{
+			name:          "empty-is-not-secondary",
+			schedulerName: "secondary-scheduler",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod",
+					Namespace: "default",
+				},
+				Spec: corev1.PodSpec{
+					NodeName:      "random-node",
+					SchedulerName: tt.podSchedulerName,
+				},
+			}
+			got := isPodScheduledByUs(pod, tt.schedulerName)
+			if got != tt.expected {
+				t.Errorf("pod with scheduler name %q scheduled by %q got %v expected %v", tt.podSchedulerName, tt.schedulerName, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"math"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// headroomWindowSize is how many availability samples are kept per node and resource.
+const headroomWindowSize = 10
+
+// headroomTracker records the aggregated availability of the nodes, as reported by the NRT objects,
+// over a sliding window of the last updates. It is not safe for concurrent use.
+type headroomTracker struct {
+	window  int
+	samples map[string]map[string][]float64 // nodeName -> resourceName -> availability samples, oldest first
+}
+
+func newHeadroomTracker(window int) *headroomTracker {
+	return &headroomTracker{
+		window:  window,
+		samples: make(map[string]map[string][]float64),
+	}
+}
+
+// Record adds a sample of the availability of every resource reported by the given NRT object.
+func (ht *headroomTracker) Record(nrt *topologyv1alpha1.NodeResourceTopology) {
+	resourceNames := sets.NewString()
+	for _, zone := range nrt.Zones {
+		for _, zr := range zone.Resources {
+			resourceNames.Insert(zr.Name)
+		}
+	}
+
+	nodeSamples, ok := ht.samples[nrt.Name]
+	if !ok {
+		nodeSamples = make(map[string][]float64)
+		ht.samples[nrt.Name] = nodeSamples
+	}
+	for _, resourceName := range resourceNames.List() {
+		qty := AggregateZoneAvailable(nrt, resourceName)
+		vals := append(nodeSamples[resourceName], qty.AsApproximateFloat64())
+		if len(vals) > ht.window {
+			vals = vals[len(vals)-ht.window:]
+		}
+		nodeSamples[resourceName] = vals
+	}
+}
+
+// Trend returns the slope of the least squares fit of the availability samples of the given resource on the
+// given node, in resource units per update. A negative value means the free capacity is shrinking, a positive
+// value means it is growing. Returns zero if there are not enough samples to tell.
+func (ht *headroomTracker) Trend(nodeName, resourceName string) float64 {
+	slope, _, ok := leastSquaresFit(ht.samples[nodeName][resourceName])
+	if !ok {
+		return 0
+	}
+	return slope
+}
+
+// Risk returns the probability, in the [0, 1] range, the availability of the given resource on the given node drops
+// below the given request within the given number of updates. The availability is projected along the least squares
+// fit of the samples, and the scatter of the samples around the fit is taken as the uncertainty of the projection.
+// Returns zero if there are not enough samples to tell.
+func (ht *headroomTracker) Risk(nodeName, resourceName string, request float64, horizon int) float64 {
+	vals := ht.samples[nodeName][resourceName]
+	slope, intercept, ok := leastSquaresFit(vals)
+	if !ok {
+		return 0
+	}
+	if vals[len(vals)-1] < request {
+		// already infeasible
+		return 1
+	}
+	if slope >= 0 {
+		// the fit is lowest at the last sample, which is feasible
+		return 0
+	}
+
+	var sumSq float64
+	for i, y := range vals {
+		res := y - (intercept + slope*float64(i))
+		sumSq += res * res
+	}
+	stdDev := math.Sqrt(sumSq / float64(len(vals)))
+
+	projected := intercept + slope*float64(len(vals)-1+horizon)
+	if stdDev == 0 {
+		if projected < request {
+			return 1
+		}
+		return 0
+	}
+	// probability a normal variable centered in the projection is lower than the request
+	return 0.5 * math.Erfc((projected-request)/(stdDev*math.Sqrt2))
+}
+
+// leastSquaresFit returns the slope and the intercept of the least squares line fitting the samples, taking
+// the sample indexes as the x values. Returns false if there are not enough samples.
+func leastSquaresFit(vals []float64) (float64, float64, bool) {
+	n := float64(len(vals))
+	if n < 2 {
+		return 0, 0, false
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range vals {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	return slope, (sumY - slope*sumX) / n, true
+}
@@ -22,6 +22,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	k8scache "k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 )
@@ -45,8 +46,6 @@ import (
  * (possibly inlined in the calling site)
  */
 
-// TODO: tests using multiple goroutines (+ race detector)
-
 type podObservedState int
 
 const (
@@ -75,6 +74,9 @@ type nodeNameIndexer struct {
 	// we need this map to handle possible deletions when pods have been are reserved but not yet detected running
 	// note we do NOT clean up this map when pods are detected running, but only on pod deletion - or on Unreserve.
 	podUIDToCandidateNodeMap map[types.UID]string
+	// if not empty, only the bound pods scheduled by these schedulers are accounted.
+	// Immutable after creation, so it needs no locking.
+	schedulerNames sets.String
 }
 
 type podData struct {
@@ -82,10 +84,15 @@ type podData struct {
 	observedState  podObservedState
 }
 
-func NewNodeNameIndexer(podInformer k8scache.SharedInformer) NodeIndexer {
+// NewNodeNameIndexer creates a NodeIndexer fed by the given pod informer. By default all the bound pods
+// are accounted, regardless of the scheduler which bound them, because in clusters with multiple schedulers
+// pods bound by another scheduler still consume node resources. If schedulerNames are given, only the pods
+// bound by these schedulers are accounted.
+func NewNodeNameIndexer(podInformer k8scache.SharedInformer, schedulerNames ...string) NodeIndexer {
 	nni := &nodeNameIndexer{
 		nodeToPodsMap:            make(map[string]map[types.UID]podData),
 		podUIDToCandidateNodeMap: make(map[types.UID]string),
+		schedulerNames:           sets.NewString(schedulerNames...),
 	}
 	podInformer.AddEventHandler(k8scache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -158,6 +165,10 @@ func (nni *nodeNameIndexer) addPod(pod *corev1.Pod) {
 		klog.V(4).InfoS("nrtcache: nni WARN", "logID", klog.KObj(pod), "node", "EMPTY", "podUID", pod.UID, "phase", pod.Status.Phase)
 		return
 	}
+	if nni.schedulerNames.Len() > 0 && !nni.schedulerNames.Has(pod.Spec.SchedulerName) {
+		klog.V(6).InfoS("nrtcache: nni SKIP", "logID", klog.KObj(pod), "node", pod.Spec.NodeName, "podUID", pod.UID, "schedulerName", pod.Spec.SchedulerName)
+		return
+	}
 	if pod.Status.Phase != corev1.PodRunning {
 		// We should only consider pod which are consuming resources. But it's also paramount to take into account what the
 		// node side can do. The primary source of data for node agents is the podresources API, and the podresources API
@@ -17,6 +17,8 @@ limitations under the License.
 package cache
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -164,6 +166,119 @@ func TestNodeNameIndexerByIndexAdd(t *testing.T) {
 	}
 }
 
+func TestNodeNameIndexerSchedulerNames(t *testing.T) {
+	makeBoundPod := func(name, schedulerName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1",
+				Name:      name,
+				UID:       types.UID("ns1" + name),
+			},
+			Spec: corev1.PodSpec{
+				NodeName:      "worker-node-A",
+				SchedulerName: schedulerName,
+			},
+		}
+	}
+	pods := []*corev1.Pod{
+		makeBoundPod("pod1", "numa-aware-scheduler"),
+		makeBoundPod("pod2", "default-scheduler"),
+		makeBoundPod("pod3", "other-scheduler"),
+	}
+
+	tests := []struct {
+		name             string
+		schedulerNames   []string
+		expectedPodNames sets.String
+	}{
+		{
+			name:             "all bound pods",
+			expectedPodNames: sets.NewString("ns1/pod1", "ns1/pod2", "ns1/pod3"),
+		},
+		{
+			name:             "only this scheduler pods",
+			schedulerNames:   []string{"numa-aware-scheduler"},
+			expectedPodNames: sets.NewString("ns1/pod1"),
+		},
+		{
+			name:             "selected schedulers pods",
+			schedulerNames:   []string{"numa-aware-scheduler", "default-scheduler"},
+			expectedPodNames: sets.NewString("ns1/pod1", "ns1/pod2"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi := &fakeInformer{
+				events: makeAddEvents(pods),
+			}
+			nni := NewNodeNameIndexer(fi, tt.schedulerNames...)
+
+			fi.SendEvents()
+
+			objs, err := nni.GetPodNamespacedNamesByNode(tt.name, "worker-node-A")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			gotNames := sets.NewString()
+			for _, obj := range objs {
+				gotNames.Insert(obj.String())
+			}
+
+			if !gotNames.Equal(tt.expectedPodNames) {
+				t.Errorf("%s: got but not expected: %#v", tt.name, gotNames.Difference(tt.expectedPodNames))
+				t.Errorf("%s: expected but not got: %#v", tt.name, tt.expectedPodNames.Difference(gotNames))
+			}
+		})
+	}
+}
+
+func TestNodeNameIndexerConcurrentAccess(t *testing.T) {
+	fi := &fakeInformer{}
+	nni := NewNodeNameIndexer(fi)
+
+	const workers = 8
+	const iterations = 100
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			nodeName := fmt.Sprintf("worker-node-%d", w%2)
+			for i := 0; i < iterations; i++ {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      fmt.Sprintf("pod-%d-%d", w, i),
+						UID:       types.UID(fmt.Sprintf("uid-%d-%d", w, i)),
+					},
+				}
+				// informer events and scheduling cycles run on different goroutines
+				nni.TrackReservedPod(pod, nodeName)
+				bound := pod.DeepCopy()
+				bound.Spec.NodeName = nodeName
+				fi.rev.OnAdd(bound)
+				if _, err := nni.GetPodNamespacedNamesByNode("testing", nodeName); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				nni.UntrackReservedPod(pod, nodeName)
+				fi.rev.OnDelete(bound)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, nodeName := range []string{"worker-node-0", "worker-node-1"} {
+		objs, err := nni.GetPodNamespacedNamesByNode("testing", nodeName)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(objs) != 0 {
+			t.Errorf("%s: unexpected pods left: %v", nodeName, objs)
+		}
+	}
+}
+
 const (
 	evAdd = iota
 	evUpdate
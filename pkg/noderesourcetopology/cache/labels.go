@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+const (
+	// LabelNUMAZones is the recommended node label holding the number of NUMA zones of the node.
+	LabelNUMAZones = "topology.node.k8s.io/numa-zones"
+	// LabelLargestZoneCPU is the recommended node label holding the CPU capacity of the largest NUMA zone of the node.
+	LabelLargestZoneCPU = "topology.node.k8s.io/largest-zone-cpu"
+)
+
+// RecommendNodeLabels returns the labels which summarize the NUMA characteristics of the node described by the given
+// Node Resource Topology object. Operators can set them on the nodes to steer the workloads with plain node affinity,
+// before the finer grained, NUMA aware filtering happens. The largest zone label is omitted if no NUMA zone reports CPUs.
+func RecommendNodeLabels(nrt *topologyv1alpha1.NodeResourceTopology) map[string]string {
+	if nrt == nil {
+		return nil
+	}
+	numaZones := ZonesOfType(nrt, ZoneTypeNUMANode)
+	labels := map[string]string{
+		LabelNUMAZones: strconv.Itoa(len(numaZones)),
+	}
+
+	var largest *resource.Quantity
+	for _, zone := range numaZones {
+		for _, zr := range zone.Resources {
+			if zr.Name != string(corev1.ResourceCPU) {
+				continue
+			}
+			if largest == nil || zr.Capacity.Cmp(*largest) > 0 {
+				capacity := zr.Capacity.DeepCopy()
+				largest = &capacity
+			}
+		}
+	}
+	if largest != nil {
+		labels[LabelLargestZoneCPU] = largest.String()
+	}
+	return labels
+}
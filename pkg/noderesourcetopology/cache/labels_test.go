@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+func TestRecommendNodeLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		nrt      *topologyv1alpha1.NodeResourceTopology
+		expected map[string]string
+	}{
+		{
+			name: "nil object",
+		},
+		{
+			name: "two zones",
+			nrt:  makeTwoZonesTestTopology(),
+			expected: map[string]string{
+				LabelNUMAZones:      "2",
+				LabelLargestZoneCPU: "20",
+			},
+		},
+		{
+			name: "no CPUs reported",
+			nrt: &topologyv1alpha1.NodeResourceTopology{
+				Zones: topologyv1alpha1.ZoneList{
+					{
+						Name: "node-0",
+						Type: ZoneTypeNUMANode,
+					},
+				},
+			},
+			expected: map[string]string{
+				LabelNUMAZones: "1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RecommendNodeLabels(tt.nrt)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("unexpected labels: got %v expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsSubsystem = "nrtcache"
+
+	lookupResultHit  = "hit"
+	lookupResultMiss = "miss"
+)
+
+var (
+	nrtLookups = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "lookups_total",
+			Help:           "Number of lookups of the cached NodeResourceTopology data by result: 'hit' or 'miss' (node not cached).",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"result"})
+	nrtMaxStaleness = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "max_staleness_seconds",
+			Help:           "Longest time since the cached NodeResourceTopology data of a node was last updated, across all the cached nodes.",
+			StabilityLevel: metrics.ALPHA,
+		})
+	nrtOverbookedZones = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "overbooked_zones_total",
+			Help:           "Number of times the accounting of the reserved resources found less resources available in a zone than requested, by node (if enabled) and resource.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"node", "resource"})
+	nrtNegativeAvailability = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "negative_availability_total",
+			Help:           "Number of negative resource availabilities reported in the NodeResourceTopology data, by resource and action: 'clamped' (to zero) or 'rejected' (the whole update).",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"resource", "action"})
+
+	metricsList = []metrics.Registerable{
+		nrtLookups,
+		nrtMaxStaleness,
+		nrtOverbookedZones,
+		nrtNegativeAvailability,
+	}
+)
+
+// overbookedZonesByNode enables the node label of the overbooked zones metric. See SetOverbookedZonesMetricByNode.
+var overbookedZonesByNode atomic.Bool
+
+// SetOverbookedZonesMetricByNode sets if the overbooked zones metric is labeled with the node name. Disabled by default,
+// to keep the metric cardinality bounded on large clusters; when disabled, the node label is always empty.
+func SetOverbookedZonesMetricByNode(enabled bool) {
+	overbookedZonesByNode.Store(enabled)
+}
+
+func recordOverbookedZone(nodeName, resourceName string) {
+	if !overbookedZonesByNode.Load() {
+		nodeName = ""
+	}
+	nrtOverbookedZones.WithLabelValues(nodeName, resourceName).Inc()
+}
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the cache metrics in the legacy registry. Safe to be called more than once.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		for _, metric := range metricsList {
+			legacyregistry.MustRegister(metric)
+		}
+	})
+}
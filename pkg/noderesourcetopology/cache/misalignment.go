@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"gonum.org/v1/gonum/stat/combin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PodsByMisalignment returns the keys (namespace + "/" + name) of the pods tracked on the node described
+// by the given NRT object, sorted from the worst aligned to the best aligned, so the descheduler can evict
+// the worst offenders first. Pods with the same alignment cost are sorted by key.
+func (ov *OverReserve) PodsByMisalignment(nrt *topologyv1alpha1.NodeResourceTopology) []string {
+	if nrt == nil {
+		return nil
+	}
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	nodeAssumedResources, ok := ov.assumedResources[nrt.Name]
+	if !ok {
+		return nil
+	}
+	return nodeAssumedResources.PodsByMisalignment(nrt)
+}
+
+// PodsByMisalignment returns the keys of the pods tracked in this store, sorted by decreasing alignment cost
+// against the NUMA zones of the given NRT object. See misalignmentCost.
+func (rs *resourceStore) PodsByMisalignment(nrt *topologyv1alpha1.NodeResourceTopology) []string {
+	zones := ZonesOfType(nrt, ZoneTypeNUMANode)
+	costs := make(map[string]int, len(rs.data))
+	keys := make([]string, 0, len(rs.data))
+	for key, res := range rs.data {
+		costs[key] = misalignmentCost(res, zones)
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if costs[keys[i]] != costs[keys[j]] {
+			return costs[keys[i]] > costs[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// misalignmentCost returns the minimum number of NUMA zones whose combined capacity can satisfy the given
+// resources, which is the least amount of zones the pod can be spread across. A pod which fits in a single
+// zone costs 1. A pod which can't fit even in all the zones costs one more than the zone count.
+func misalignmentCost(res corev1.ResourceList, zones []*topologyv1alpha1.Zone) int {
+	for size := 1; size <= len(zones); size++ {
+		for _, combination := range combin.Combinations(len(zones), size) {
+			if zonesFitResources(res, zones, combination) {
+				return size
+			}
+		}
+	}
+	return len(zones) + 1
+}
+
+// zonesFitResources returns true if the combined capacity of the selected zones satisfies the given resources.
+// Resources not reported by any zone don't have NUMA affinity, so they are ignored.
+func zonesFitResources(res corev1.ResourceList, zones []*topologyv1alpha1.Zone, combination []int) bool {
+	combined := make(map[string]*resource.Quantity)
+	for _, idx := range combination {
+		for _, zr := range zones[idx].Resources {
+			qty := ResourceCapacity(zr)
+			if cur, ok := combined[zr.Name]; ok {
+				cur.Add(qty)
+				continue
+			}
+			combined[zr.Name] = &qty
+		}
+	}
+	for name, qty := range res {
+		if qty.IsZero() {
+			continue
+		}
+		available, ok := combined[string(name)]
+		if !ok {
+			if zonesReportResource(zones, string(name)) {
+				return false
+			}
+			continue
+		}
+		if available.Cmp(qty) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func zonesReportResource(zones []*topologyv1alpha1.Zone, name string) bool {
+	for _, zone := range zones {
+		for _, zr := range zone.Resources {
+			if zr.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"k8s.io/klog/v2"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+const (
+	negativeAvailabilityClamped  = "clamped"
+	negativeAvailabilityRejected = "rejected"
+)
+
+// sanitizeAvailability handles the negative availability an exporter may report because of bugs, which would otherwise
+// make the accounting admit pods only when other pods go away. In strict mode the whole object is rejected, so the caller
+// keeps the data it has; otherwise the negative availability is clamped to zero. The given object is never changed.
+// Returns the object to use, or false if the object is rejected.
+func sanitizeAvailability(logID string, nrt *topologyv1alpha1.NodeResourceTopology, strict bool) (*topologyv1alpha1.NodeResourceTopology, bool) {
+	if !hasNegativeAvailability(nrt) {
+		return nrt, true
+	}
+
+	action := negativeAvailabilityClamped
+	if strict {
+		action = negativeAvailabilityRejected
+	}
+	ret := nrt.DeepCopy()
+	for zi := 0; zi < len(ret.Zones); zi++ {
+		zone := &ret.Zones[zi] // shortcut
+		for ri := 0; ri < len(zone.Resources); ri++ {
+			zr := &zone.Resources[ri] // shortcut
+			if zr.Available.Sign() >= 0 {
+				continue
+			}
+			klog.V(2).InfoS("nrtcache: negative availability reported", "logID", logID, "node", nrt.Name, "zone", zone.Name, "resource", zr.Name, "available", zr.Available.String(), "action", action)
+			nrtNegativeAvailability.WithLabelValues(zr.Name, action).Inc()
+			zr.Available.Set(0)
+		}
+	}
+	if strict {
+		return nil, false
+	}
+	return ret, true
+}
+
+func hasNegativeAvailability(nrt *topologyv1alpha1.NodeResourceTopology) bool {
+	for _, zone := range nrt.Zones {
+		for _, zr := range zone.Resources {
+			if zr.Available.Sign() < 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestFlushNodesNegativeAvailability(t *testing.T) {
+	registry := metrics.NewKubeRegistry()
+	registry.MustRegister(nrtNegativeAvailability)
+
+	tests := []struct {
+		name          string
+		strict        bool
+		expectedCPU   string
+		expectedCount map[string]float64
+	}{
+		{
+			name:          "lenient",
+			expectedCPU:   "0",
+			expectedCount: map[string]float64{negativeAvailabilityClamped: 1, negativeAvailabilityRejected: 0},
+		},
+		{
+			name:          "strict",
+			strict:        true,
+			expectedCPU:   "20",
+			expectedCount: map[string]float64{negativeAvailabilityClamped: 0, negativeAvailabilityRejected: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrtNegativeAvailability.Reset()
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeIndex := &fakePodByNodeNameIndex{}
+
+			nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+			nrtCache.StrictAvailability = tt.strict
+			nrtCache.FlushNodes("testNegativeAvailability", makeTwoZonesTestTopology())
+
+			nrt := makeTwoZonesTestTopology()
+			findResourceInfo(nrt.Zones[0].Resources, cpu).Available = resource.MustParse("-2")
+			nrtCache.FlushNodes("testNegativeAvailability", nrt)
+
+			if qty := findResourceInfo(nrt.Zones[0].Resources, cpu).Available; qty.Cmp(resource.MustParse("-2")) != 0 {
+				t.Errorf("the flushed object was changed: %s", qty.String())
+			}
+
+			cached := nrtCache.Store().GetNRTCopyByNodeName("node")
+			got := findResourceInfo(cached.Zones[0].Resources, cpu).Available
+			if got.Cmp(resource.MustParse(tt.expectedCPU)) != 0 {
+				t.Errorf("unexpected cached cpu availability: %s expected %s", got.String(), tt.expectedCPU)
+			}
+			// the other resources are never changed
+			if got := findResourceInfo(cached.Zones[1].Resources, cpu).Available; got.Cmp(resource.MustParse("20")) != 0 {
+				t.Errorf("unexpected cached cpu availability on node-1: %s", got.String())
+			}
+
+			for action, expected := range tt.expectedCount {
+				val, err := testutil.GetCounterMetricValue(nrtNegativeAvailability.WithLabelValues(cpu, action))
+				if err != nil || val != expected {
+					t.Errorf("unexpected %s metric: %v expected %v (err=%v)", action, val, expected, err)
+				}
+			}
+		})
+	}
+}
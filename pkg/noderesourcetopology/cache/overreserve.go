@@ -19,36 +19,90 @@ package cache
 import (
 	"errors"
 	"fmt"
-	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	k8scache "k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
 
 	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 	listerv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/listers/topology/v1alpha1"
 
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 
 	"github.com/k8stopologyawareschedwg/podfingerprint"
 )
 
 type OverReserve struct {
-	lock             sync.Mutex
+	// lock protects both the nrtStore and the resourceStores, and tracks its contention. See LockContentionStats().
+	lock             instrumentedMutex
 	nrts             *nrtStore
 	assumedResources map[string]*resourceStore // nodeName -> resourceStore
 	// nodesMaybeOverreserved counts how many times a node is filtered out. This is used as trigger condition to try
 	// to resync nodes. See The documentation of Resync() below for more details.
 	nodesMaybeOverreserved counter
 	nodesWithForeignPods   counter
-	nrtLister              listerv1alpha1.NodeResourceTopologyLister
-	nodeIndexer            NodeIndexer
+	// nodesWithMismatches counts the consecutive podset fingerprint mismatches detected by Resync(). Past
+	// mismatchThreshold, the node is moved to nodesInPassthrough, and its NRT data is read from the lister as-is,
+	// dropping the overreserve discount, until the fingerprint matches again. See Resync() below.
+	nodesWithMismatches counter
+	nodesInPassthrough  counter
+	mismatchThreshold   int
+	// deltaSequences tracks the sequence number of the last availability delta applied to each node. See ApplyDelta().
+	deltaSequences map[string]uint64 // nodeName -> sequence number
+	// nodesWithDeltaGaps tracks the nodes which missed availability deltas, and need a full update before taking deltas again.
+	nodesWithDeltaGaps counter
+	nrtLister          listerv1alpha1.NodeResourceTopologyLister
+	nodeIndexer        NodeIndexer
+	// Granularity sets the minimum allocation unit of resources, like whole CPUs or devices. The available resources
+	// are floored to a multiple of their unit after the assumed resources are subtracted, so unusable fractional residue
+	// does not accumulate. Resources without granularity are not changed. Must be set before the cache is used.
+	Granularity map[corev1.ResourceName]resource.Quantity
+	// headroom tracks the availability reported by the NRT objects the cache is flushed with.
+	headroom *headroomTracker
+	// RebuildInterval sets how often the cached availability of all the nodes is recomputed from the NRT data
+	// in the apiserver and the reserved pods, to bound the drift of the accounting. Checked on each Resync(),
+	// so the effective interval is rounded up to the resync period. Zero disables. Must be set before the cache is used.
+	RebuildInterval time.Duration
+	// IgnoredResources lists the resources which are not NUMA-local, like ephemeral-storage, and are thus skipped
+	// entirely when the assumed resources are subtracted from the zones. Must be set before the cache is used.
+	IgnoredResources sets.String
+	// DiscountedResources, if not empty, limits the overreserve discount to the named resources: the availability of
+	// the other resources is used as reported by the nodes, without subtracting the reserved pods. Must be set before
+	// the cache is used.
+	DiscountedResources sets.String
+	// DiscountTerminatingPods skips the accounting of the terminating pods when a pod with the same controller, like
+	// the new pod of a rolling update, is tracked on the same node, so the resources the old pod is releasing are not
+	// counted twice. Must be set before the cache is used.
+	DiscountTerminatingPods bool
+	// EvictionRelease sets when the resources of the terminating pods, like the evicted pods, are released.
+	// See EvictionReleasePolicy. Must be set before the cache is used.
+	EvictionRelease EvictionReleasePolicy
+	// NRTTrustDelay, if greater than zero, makes the nodes appearing in the cache after its creation, and the known nodes
+	// whose NRT object is recreated or whose podset fingerprint is reset, like after their topology exporter restarted,
+	// stay in passthrough mode until their podset fingerprint stays the same for this long, because the first data
+	// reported may be incomplete. Must be set before the cache is used.
+	NRTTrustDelay time.Duration
+	// untrustedNodes tracks the nodes in their trust delay. See NRTTrustDelay.
+	untrustedNodes map[string]nodeTrust
+	// initialFingerprints holds the podset fingerprints of the NRT data the cache was prepopulated with. See Prepopulate.
+	initialFingerprints map[string]string
+	// StrictAvailability rejects the NRT updates reporting a negative availability, which is an exporter bug, keeping
+	// the data cached so far. Otherwise, the negative availability is clamped to zero. Must be set before the cache is used.
+	StrictAvailability bool
+	lastRebuild        time.Time
+	clock              clock.PassiveClock
 }
 
-func NewOverReserve(lister listerv1alpha1.NodeResourceTopologyLister, indexer NodeIndexer) (*OverReserve, error) {
+// NewOverReserve creates a new OverReserve cache. If mismatchThreshold is greater than zero, nodes whose podset fingerprint
+// mismatches for more than mismatchThreshold consecutive resync attempts are served in passthrough mode until a match.
+func NewOverReserve(lister listerv1alpha1.NodeResourceTopologyLister, indexer NodeIndexer, mismatchThreshold int) (*OverReserve, error) {
 	if lister == nil || indexer == nil {
 		return nil, fmt.Errorf("nrtcache: received nil references")
 	}
@@ -58,15 +112,26 @@ func NewOverReserve(lister listerv1alpha1.NodeResourceTopologyLister, indexer No
 		return nil, err
 	}
 
-	klog.V(3).InfoS("nrtcache: initializing", "objects", len(nrtObjs))
+	klog.V(3).InfoS("nrtcache: initializing", "objects", len(nrtObjs), "mismatchThreshold", mismatchThreshold)
 	obj := &OverReserve{
-		nrts:                   newNrtStore(nrtObjs),
+		nrts:                   newNrtStore(nil),
 		assumedResources:       make(map[string]*resourceStore),
 		nodesMaybeOverreserved: newCounter(),
 		nodesWithForeignPods:   newCounter(),
+		nodesWithMismatches:    newCounter(),
+		nodesInPassthrough:     newCounter(),
+		mismatchThreshold:      mismatchThreshold,
+		deltaSequences:         make(map[string]uint64),
+		nodesWithDeltaGaps:     newCounter(),
+		untrustedNodes:         make(map[string]nodeTrust),
+		initialFingerprints:    make(map[string]string),
 		nrtLister:              lister,
 		nodeIndexer:            indexer,
+		headroom:               newHeadroomTracker(headroomWindowSize),
+		clock:                  clock.RealClock{},
 	}
+	obj.lastRebuild = obj.clock.Now()
+	obj.Prepopulate(nrtObjs)
 	return obj, nil
 }
 
@@ -76,11 +141,21 @@ func (ov *OverReserve) GetCachedNRTCopy(nodeName string, pod *corev1.Pod) (*topo
 	if ov.nodesWithForeignPods.IsSet(nodeName) {
 		return nil, false
 	}
+	if ov.isNodeUntrusted(nodeName) {
+		// the cached data can't be used yet, but the node must stay usable like without the cache
+		klog.V(5).InfoS("nrtcache: node data not trusted yet", "logID", klog.KObj(pod), "node", nodeName)
+		return ov.passthroughNRTCopy(nodeName, pod)
+	}
+	if ov.nodesInPassthrough.IsSet(nodeName) {
+		return ov.passthroughNRTCopy(nodeName, pod)
+	}
 
 	nrt := ov.nrts.GetNRTCopyByNodeName(nodeName)
 	if nrt == nil {
 		return nil, true
 	}
+	subtractReservedFromZones(klog.KObj(pod).String(), nrt)
+	subtractSharedCPUPools(klog.KObj(pod).String(), nrt)
 	nodeAssumedResources, ok := ov.assumedResources[nodeName]
 	if !ok {
 		return nrt, true
@@ -88,11 +163,27 @@ func (ov *OverReserve) GetCachedNRTCopy(nodeName string, pod *corev1.Pod) (*topo
 
 	klog.V(6).InfoS("nrtcache NRT", "logID", klog.KObj(pod), "vanilla", stringify.NodeResourceTopologyResources(nrt))
 	nodeAssumedResources.UpdateNRT(klog.KObj(pod).String(), nrt)
+	applyGranularity(nrt, ov.Granularity)
 
 	klog.V(5).InfoS("nrtcache NRT", "logID", klog.KObj(pod), "updated", stringify.NodeResourceTopologyResources(nrt))
 	return nrt, true
 }
 
+// passthroughNRTCopy returns a copy of the NRT data of the given node as reported by the lister, ignoring the pods
+// reserved by the scheduler. Must be called with the lock held.
+func (ov *OverReserve) passthroughNRTCopy(nodeName string, pod *corev1.Pod) (*topologyv1alpha1.NodeResourceTopology, bool) {
+	nrt, err := ov.nrtLister.Get(nodeName)
+	if err != nil {
+		klog.V(5).ErrorS(err, "nrtcache: cannot get NodeTopology in passthrough mode", "logID", klog.KObj(pod), "node", nodeName)
+		return nil, true
+	}
+	klog.V(5).InfoS("nrtcache NRT", "logID", klog.KObj(pod), "node", nodeName, "passthrough", stringify.NodeResourceTopologyResources(nrt))
+	nrt = nrt.DeepCopy()
+	subtractReservedFromZones(klog.KObj(pod).String(), nrt)
+	subtractSharedCPUPools(klog.KObj(pod).String(), nrt)
+	return nrt, true
+}
+
 func (ov *OverReserve) NodeMaybeOverReserved(nodeName string, pod *corev1.Pod) {
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
@@ -111,39 +202,112 @@ func (ov *OverReserve) NodeHasForeignPods(nodeName string, pod *corev1.Pod) {
 	klog.V(4).InfoS("nrtcache: marked with foreign pods", "logID", klog.KObj(pod), "node", nodeName, "count", val)
 }
 
-func (ov *OverReserve) ReserveNodeResources(nodeName string, pod *corev1.Pod) {
+func (ov *OverReserve) HasForeignPods(nodeName string) bool {
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
+	return ov.nodesWithForeignPods.IsSet(nodeName)
+}
+
+func (ov *OverReserve) ReserveNodeResources(nodeName string, pod *corev1.Pod, targetZones ...string) (bool, error) {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	if err := ov.checkZonesCapacity(nodeName, pod); err != nil {
+		klog.V(2).InfoS("nrtcache: cannot reserve pod", "logID", klog.KObj(pod), "node", nodeName, "error", err)
+		return false, err
+	}
+
 	nodeAssumedResources, ok := ov.assumedResources[nodeName]
 	if !ok {
-		nodeAssumedResources = newResourceStore()
+		nodeAssumedResources = ov.newNodeResourceStore()
 		ov.assumedResources[nodeName] = nodeAssumedResources
 	}
 
+	ov.nodesMaybeOverreserved.Delete(nodeName)
+	klog.V(6).InfoS("nrtcache: reset discard counter", "logID", klog.KObj(pod), "node", nodeName)
+
+	if nodeAssumedResources.Contains(pod.Namespace + "/" + pod.Name) {
+		klog.V(4).InfoS("nrtcache: pod already reserved", "logID", klog.KObj(pod), "node", nodeName)
+		if len(targetZones) > 0 {
+			ov.setPodTargetZones(nodeName, pod, targetZones)
+		}
+		return true, nil
+	}
+
 	nodeAssumedResources.AddPod(pod)
+	if len(targetZones) > 0 {
+		ov.setPodTargetZones(nodeName, pod, targetZones)
+	}
 	klog.V(5).InfoS("nrtcache post reserve", "logID", klog.KObj(pod), "node", nodeName, "assumedResources", nodeAssumedResources.String())
 
 	ov.nodeIndexer.TrackReservedPod(pod, nodeName)
+	return false, nil
+}
 
-	ov.nodesMaybeOverreserved.Delete(nodeName)
-	klog.V(6).InfoS("nrtcache: reset discard counter", "logID", klog.KObj(pod), "node", nodeName)
+// newNodeResourceStore creates a resourceStore to track the pods reserved on a node, applying the cache settings.
+func (ov *OverReserve) newNodeResourceStore() *resourceStore {
+	rs := newResourceStore()
+	rs.ignored = ov.IgnoredResources
+	rs.discountTerminating = ov.DiscountTerminatingPods
+	rs.discountedResources = ov.DiscountedResources
+	rs.evictionRelease = ov.EvictionRelease
+	rs.clock = ov.clock
+	return rs
 }
 
-func (ov *OverReserve) UnreserveNodeResources(nodeName string, pod *corev1.Pod) {
+// checkZonesCapacity returns error if the pod requests more of any resource than the total capacity of the NUMA zones
+// of the node, which no placement can ever satisfy, so reserving the pod would put the accounting in an impossible state.
+// Resources not reported by the zones, or ignored, are not checked. Must be called with ov.lock held, because it reads
+// the cached NRT data without copying it.
+func (ov *OverReserve) checkZonesCapacity(nodeName string, pod *corev1.Pod) error {
+	nrt := ov.nrts.GetNRTReadOnly(nodeName)
+	if nrt == nil {
+		return nil
+	}
+	capacity := make(map[string]resource.Quantity)
+	for _, zone := range ZonesOfType(nrt, ZoneTypeNUMANode) {
+		for _, zr := range zone.Resources {
+			qty := capacity[zr.Name]
+			qty.Add(ResourceCapacity(zr))
+			capacity[zr.Name] = qty
+		}
+	}
+	for resName, qty := range util.GetPodEffectiveRequest(pod) {
+		if ov.IgnoredResources.Has(string(resName)) {
+			continue
+		}
+		zonesCapacity, ok := capacity[string(resName)]
+		if !ok {
+			continue
+		}
+		if qty.Cmp(zonesCapacity) > 0 {
+			return fmt.Errorf("pod %s requests %s %s exceeding the capacity %s of the zones of node %s", klog.KObj(pod), qty.String(), resName, zonesCapacity.String(), nodeName)
+		}
+	}
+	return nil
+}
+
+// UnreserveNodeResources is safe to call any number of times, and even if ReserveNodeResources failed
+// or was never called for the pod: the framework runs Unreserve whenever any plugin fails the pod after
+// the Reserve phase started, including when our own Reserve rejected it before tracking anything.
+// Only the first call after a successful reserve releases the resources.
+func (ov *OverReserve) UnreserveNodeResources(nodeName string, pod *corev1.Pod) bool {
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
 	nodeAssumedResources, ok := ov.assumedResources[nodeName]
 	if !ok {
-		// this should not happen, so we're vocal about it
-		// we don't return error because not much to do to recover anyway
-		klog.V(3).InfoS("nrtcache: no resources tracked", "logID", klog.KObj(pod), "node", nodeName)
-		return
+		// nothing was ever reserved on this node, e.g. the reserve was rejected by the capacity check
+		klog.V(4).InfoS("nrtcache: no resources tracked", "logID", klog.KObj(pod), "node", nodeName)
+		return false
 	}
 
-	nodeAssumedResources.DeletePod(pod)
+	if !nodeAssumedResources.DeletePod(pod) {
+		klog.V(4).InfoS("nrtcache: pod not reserved", "logID", klog.KObj(pod), "node", nodeName)
+		return false
+	}
 	klog.V(5).InfoS("nrtcache post release", "logID", klog.KObj(pod), "node", nodeName, "assumedResources", nodeAssumedResources.String())
 
 	ov.nodeIndexer.UntrackReservedPod(pod, nodeName)
+	return true
 }
 
 // NodesMaybeOverReserved returns a slice of all the node names which have been discarded previously,
@@ -169,6 +333,16 @@ func (ov *OverReserve) NodesMaybeOverReserved(logID string) []string {
 		nodes.Incr(node)
 	}
 
+	// nodes in passthrough mode must be checked until their fingerprint matches again
+	for _, node := range ov.nodesInPassthrough.Keys() {
+		nodes.Incr(node)
+	}
+
+	// untrusted nodes must be checked until their data is stable
+	for node := range ov.untrustedNodes {
+		nodes.Incr(node)
+	}
+
 	if nodes.Len() > 0 {
 		klog.V(4).InfoS("nrtcache: found dirty nodes", "logID", logID, "foreign", foreignCount, "discarded", nodes.Len()-foreignCount, "total", nodes.Len())
 	}
@@ -183,10 +357,19 @@ func (ov *OverReserve) NodesMaybeOverReserved(logID string) []string {
 // If *both* a node has pessimistic overallocation accounted to it *and* was discarded "too many" (how much is too much is a runtime parameter
 // which needs to be set and tuned) times, then it becomes a candidate for resync. Just using one of these two factors would lead to
 // too aggressive resync attempts, so to more, likely unnecessary, computation work on the scheduler side.
+// If the podset fingerprint of a node keeps mismatching, the cached state of the node can't be trusted anymore, so past the configured
+// threshold of consecutive mismatches the node is switched to passthrough mode until a resync attempt succeeds.
 func (ov *OverReserve) Resync() {
 	// we are not working with a specific pod, so we need a unique key to track this flow
 	logID := logIDFromTime()
 
+	// resync runs periodically, so this is the right place to let the staleness metric grow
+	ov.lock.Lock()
+	ov.nrts.UpdateStalenessMetric()
+	ov.lock.Unlock()
+
+	ov.rebuildIfDue(logID)
+
 	nodeNames := ov.NodesMaybeOverReserved(logID)
 	// avoid as much as we can unnecessary work and logs.
 	if len(nodeNames) == 0 {
@@ -197,7 +380,6 @@ func (ov *OverReserve) Resync() {
 	klog.V(6).InfoS("nrtcache: resync NodeTopology cache starting", "logID", logID)
 	defer klog.V(6).InfoS("nrtcache: resync NodeTopology cache complete", "logID", logID)
 
-	var nrtUpdates []*topologyv1alpha1.NodeResourceTopology
 	for _, nodeName := range nodeNames {
 		nrtCandidate, err := ov.nrtLister.Get(nodeName)
 		if err != nil {
@@ -209,44 +391,246 @@ func (ov *OverReserve) Resync() {
 			continue
 		}
 
-		pfpExpected := podFingerprintForNodeTopology(nrtCandidate)
-		if pfpExpected == "" {
-			klog.V(3).InfoS("nrtcache: missing NodeTopology podset fingerprint data", "logID", logID, "node", nodeName)
+		if ov.isNodeUntrustedLocked(nodeName) {
+			ov.observeTrust(logID, nrtCandidate)
 			continue
 		}
 
-		klog.V(6).InfoS("nrtcache: trying to resync NodeTopology", "logID", logID, "node", nodeName, "fingerprint", pfpExpected)
+		ov.reconcile(logID, nodeName, nrtCandidate)
+	}
+}
 
-		err = checkPodFingerprintForNode(logID, ov.nodeIndexer, nodeName, pfpExpected)
-		if errors.Is(err, podfingerprint.ErrSignatureMismatch) {
-			// can happen, not critical
-			klog.V(5).InfoS("nrtcache: NodeTopology podset fingerprint mismatch", "logID", logID, "node", nodeName)
-			continue
-		}
-		if err != nil {
-			// should never happen, let's be vocal
-			klog.V(3).ErrorS(err, "nrtcache: checking NodeTopology podset fingerprint", "logID", logID, "node", nodeName)
-			continue
-		}
+// reconcile checks the podset fingerprint of the given NRT data against the pods known to be on the node, including
+// the pods reserved by the scheduler. On match, the NRT data fully accounts for all these pods, so the reservations are
+// dropped to prevent subtracting their resources twice, and the cached data is replaced with the given NRT data.
+// Returns true if the node was reconciled, false otherwise.
+func (ov *OverReserve) reconcile(logID, nodeName string, nrt *topologyv1alpha1.NodeResourceTopology) bool {
+	pfpExpected := podFingerprintForNodeTopology(nrt)
+	if pfpExpected == "" {
+		klog.V(3).InfoS("nrtcache: missing NodeTopology podset fingerprint data", "logID", logID, "node", nodeName)
+		return false
+	}
 
-		klog.V(4).InfoS("nrtcache: overriding cached info", "logID", logID, "node", nodeName)
-		nrtUpdates = append(nrtUpdates, nrtCandidate)
+	klog.V(6).InfoS("nrtcache: trying to resync NodeTopology", "logID", logID, "node", nodeName, "fingerprint", pfpExpected)
+
+	err := checkPodFingerprintForNode(logID, ov.nodeIndexer, nodeName, pfpExpected)
+	if errors.Is(err, podfingerprint.ErrSignatureMismatch) {
+		// can happen, not critical
+		klog.V(5).InfoS("nrtcache: NodeTopology podset fingerprint mismatch", "logID", logID, "node", nodeName)
+		ov.nodeFingerprintMismatch(logID, nodeName)
+		return false
+	}
+	if err != nil {
+		// should never happen, let's be vocal
+		klog.V(3).ErrorS(err, "nrtcache: checking NodeTopology podset fingerprint", "logID", logID, "node", nodeName)
+		return false
 	}
 
-	ov.FlushNodes(logID, nrtUpdates...)
+	klog.V(4).InfoS("nrtcache: overriding cached info", "logID", logID, "node", nodeName)
+	ov.FlushNodes(logID, nrt)
+	return true
 }
 
 // FlushNodes drops all the cached information about a given node, resetting its state clean.
 func (ov *OverReserve) FlushNodes(logID string, nrts ...*topologyv1alpha1.NodeResourceTopology) {
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
-	for _, nrt := range nrts {
+	for _, obj := range nrts {
+		nrt, ok := sanitizeAvailability(logID, obj, ov.StrictAvailability)
+		if !ok {
+			klog.V(2).InfoS("nrtcache: rejected update with negative availability", "logID", logID, "node", obj.Name)
+			continue
+		}
 		klog.V(4).InfoS("nrtcache: flushing", "logID", logID, "node", nrt.Name)
+		if ov.needsTrustDelay(nrt) {
+			ov.distrustNode(logID, nrt)
+		}
 		ov.nrts.Update(nrt)
+		ov.headroom.Record(nrt)
 		delete(ov.assumedResources, nrt.Name)
 		ov.nodesMaybeOverreserved.Delete(nrt.Name)
 		ov.nodesWithForeignPods.Delete(nrt.Name)
+		ov.nodesWithMismatches.Delete(nrt.Name)
+		ov.nodesInPassthrough.Delete(nrt.Name)
+		delete(ov.deltaSequences, nrt.Name)
+		ov.nodesWithDeltaGaps.Delete(nrt.Name)
+	}
+}
+
+// reloadNodeTopology replaces the cached NRT data of the given node with the data from the apiserver, keeping the
+// reservations, so the availability is recomputed from scratch, dropping any accounting drift. Unlike FlushNodes,
+// the reservations are kept because, without a podset fingerprint match, we can't tell if the apiserver data already
+// accounts for the reserved pods, so the result is still pessimistic. The reloaded data is a full update, so the
+// deltas applied so far are forgotten. Returns false if the data can't be recomputed.
+func (ov *OverReserve) reloadNodeTopology(logID, nodeName string) bool {
+	nrt, err := ov.nrtLister.Get(nodeName)
+	if err != nil {
+		klog.V(3).InfoS("nrtcache: failed to get NodeTopology", "logID", logID, "node", nodeName, "error", err)
+		return false
+	}
+	if nrt == nil {
+		klog.V(3).InfoS("nrtcache: missing NodeTopology", "logID", logID, "node", nodeName)
+		return false
+	}
+
+	nrt, ok := sanitizeAvailability(logID, nrt, ov.StrictAvailability)
+	if !ok {
+		klog.V(2).InfoS("nrtcache: rejected update with negative availability", "logID", logID, "node", nodeName)
+		return false
+	}
+
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	if ov.needsTrustDelay(nrt) {
+		ov.distrustNode(logID, nrt)
+	}
+	ov.nrts.Update(nrt)
+	delete(ov.deltaSequences, nodeName)
+	ov.nodesWithDeltaGaps.Delete(nodeName)
+	klog.V(5).InfoS("nrtcache: reloaded NodeTopology", "logID", logID, "node", nodeName, "reservations", ov.assumedResources[nodeName])
+	return true
+}
+
+// rebuildIfDue recomputes the availability of all the cached nodes, if more than RebuildInterval passed since the last time.
+// The overreserve view is never stored: GetCachedNRTCopy derives it on each read from the cached NRT data minus the
+// tracked reservations (see resourceStore.UpdateNRT), so the only state which can drift is the cached NRT data itself,
+// like after applying deltas or missing updates. The data reported by the nodes is the capacity of each zone minus the
+// resources the node knows to be allocated, so reloading it, while keeping the reservations, rebuilds the view from the
+// capacity minus the allocated and the reserved resources. Subtracting the reservations here too would count them twice.
+func (ov *OverReserve) rebuildIfDue(logID string) {
+	if ov.RebuildInterval <= 0 {
+		return
+	}
+
+	ov.lock.Lock()
+	now := ov.clock.Now()
+	if now.Sub(ov.lastRebuild) < ov.RebuildInterval {
+		ov.lock.Unlock()
+		return
+	}
+	ov.lastRebuild = now
+	nodeNames := make([]string, 0, len(ov.nrts.data))
+	for nodeName := range ov.nrts.data {
+		if ov.nodesInPassthrough.IsSet(nodeName) {
+			// served from the apiserver data anyway
+			continue
+		}
+		nodeNames = append(nodeNames, nodeName)
+	}
+	ov.lock.Unlock()
+
+	klog.V(4).InfoS("nrtcache: rebuilding the cached availability", "logID", logID, "nodes", len(nodeNames))
+	for _, nodeName := range nodeNames {
+		ov.reloadNodeTopology(logID, nodeName)
+	}
+}
+
+// nodeFingerprintMismatch records a podset fingerprint mismatch for the given node, switching the node
+// to passthrough mode once the consecutive mismatches exceed the configured threshold.
+// The counters are reset only when the node is flushed, which happens when the fingerprint matches again.
+func (ov *OverReserve) nodeFingerprintMismatch(logID, nodeName string) {
+	if ov.mismatchThreshold <= 0 {
+		return
+	}
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	val := ov.nodesWithMismatches.Incr(nodeName)
+	if val <= ov.mismatchThreshold || ov.nodesInPassthrough.IsSet(nodeName) {
+		return
+	}
+	ov.nodesInPassthrough.Incr(nodeName)
+	klog.V(3).InfoS("nrtcache: too many podset fingerprint mismatches, switching to passthrough", "logID", logID, "node", nodeName, "count", val)
+}
+
+// isNodeUntrustedLocked is like isNodeUntrusted, but takes the lock.
+func (ov *OverReserve) isNodeUntrustedLocked(nodeName string) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	return ov.isNodeUntrusted(nodeName)
+}
+
+// isNodeInPassthrough returns true if the data of the given node is read as-is from the lister.
+func (ov *OverReserve) isNodeInPassthrough(nodeName string) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	return ov.nodesInPassthrough.IsSet(nodeName)
+}
+
+// Coverage returns the ratio of the given nodes which have NRT data in the cache, or zero if no nodes are given.
+func (ov *OverReserve) Coverage(allNodeNames []string) float64 {
+	if len(allNodeNames) == 0 {
+		return 0
+	}
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	covered := 0
+	for _, nodeName := range allNodeNames {
+		if ov.nrts.Contains(nodeName) {
+			covered++
+		}
+	}
+	return float64(covered) / float64(len(allNodeNames))
+}
+
+// SimulateAddNode temporarily injects the given NRT object in the cache, replacing the cached data of the node if any,
+// so callers (e.g. autoscaler simulations) can evaluate the feasibility of pending pods as if the node was part of the cluster.
+// The caller must invoke the returned function to undo the injection and restore the previous cached data of the node.
+// The reservations of the node are not changed.
+func (ov *OverReserve) SimulateAddNode(nrt *topologyv1alpha1.NodeResourceTopology) func() {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	var prev *topologyv1alpha1.NodeResourceTopology
+	if ov.nrts.Contains(nrt.Name) {
+		prev = ov.nrts.GetNRTCopyByNodeName(nrt.Name)
+	}
+	klog.V(4).InfoS("nrtcache: simulating node", "node", nrt.Name, "replacing", prev != nil)
+	ov.nrts.Update(nrt)
+
+	return func() {
+		ov.lock.Lock()
+		defer ov.lock.Unlock()
+		klog.V(4).InfoS("nrtcache: undoing simulated node", "node", nrt.Name, "restoring", prev != nil)
+		if prev == nil {
+			ov.nrts.Delete(nrt.Name)
+			return
+		}
+		ov.nrts.Update(prev)
+	}
+}
+
+// LockContentionStats returns how the lock protecting the cached NRT data and the reservations was acquired so far.
+// Useful to tune the scheduler at scale, when many scheduling cycles compete for the cache.
+func (ov *OverReserve) LockContentionStats() ContentionStats {
+	return ov.lock.Stats()
+}
+
+// HeadroomTrend tells if the free capacity of the given resource on the given node is trending up (positive value)
+// or down (negative value), as the change per update of the availability reported over the last updates.
+// Returns zero if the node is unknown or not enough updates were received.
+func (ov *OverReserve) HeadroomTrend(nodeName, resourceName string) float64 {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	return ov.headroom.Trend(nodeName, resourceName)
+}
+
+// InfeasibilityRisk estimates the probability, in the [0, 1] range, the given node can no longer fit the given pod within
+// horizon updates of its NRT data, projecting the availability reported over the last updates along its trend. The risk
+// is the highest among the resources requested by the pod, ignoring the IgnoredResources. The availability is aggregated
+// over the zones, so the NUMA alignment is not considered. Returns zero if the node is unknown or not enough updates were received.
+func (ov *OverReserve) InfeasibilityRisk(nodeName string, pod *corev1.Pod, horizon int) float64 {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	var risk float64
+	for resName, qty := range util.GetPodEffectiveRequest(pod) {
+		if qty.IsZero() || ov.IgnoredResources.Has(string(resName)) {
+			continue
+		}
+		if resRisk := ov.headroom.Risk(nodeName, string(resName), qty.AsApproximateFloat64(), horizon); resRisk > risk {
+			risk = resRisk
+		}
 	}
+	return risk
 }
 
 func InformerFromHandle(handle framework.Handle) k8scache.SharedInformer {
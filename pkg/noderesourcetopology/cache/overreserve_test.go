@@ -18,9 +18,12 @@ package cache
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
@@ -31,6 +34,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	testutil "sigs.k8s.io/scheduler-plugins/test/util"
 )
 
 const (
@@ -45,12 +53,12 @@ func TestInitEmptyLister(t *testing.T) {
 	fakeIndex := &fakePodByNodeNameIndex{}
 
 	var err error
-	_, err = NewOverReserve(nil, fakeIndex)
+	_, err = NewOverReserve(nil, fakeIndex, 0)
 	if err == nil {
 		t.Fatalf("accepted nil lister")
 	}
 
-	_, err = NewOverReserve(fakeInformer.Lister(), nil)
+	_, err = NewOverReserve(fakeInformer.Lister(), nil, 0)
 	if err == nil {
 		t.Fatalf("accepted nil indexer")
 	}
@@ -238,68 +246,1466 @@ func TestGetCachedNRTCopyReserve(t *testing.T) {
 	}
 }
 
-func TestGetCachedNRTCopyReleaseNone(t *testing.T) {
+func TestGetCachedNRTCopyReservedAttributes(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nrt := makeTwoZonesTestTopology()
+	nrt.Zones[0].Attributes = topologyv1alpha1.AttributeList{
+		{Name: AttributeReservedPrefix + cpu, Value: "2"},
+		{Name: AttributeReservedPrefix + "vendor.com/missing", Value: "1"},
+	}
+	nrt.Zones[1].Attributes = topologyv1alpha1.AttributeList{
+		{Name: AttributeReservedPrefix + memory, Value: "not-a-quantity"},
+	}
+	nrtCache.Store().Update(nrt)
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("4"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	type expectedAvailable struct {
+		zone     string
+		resource string
+		quantity string
+	}
+	check := func(t *testing.T, expected []expectedAvailable) {
+		t.Helper()
+		obj, ok := nrtCache.GetCachedNRTCopy("node", testPod)
+		if !ok {
+			t.Fatalf("expected node in cache")
+		}
+		for _, exp := range expected {
+			for _, zone := range obj.Zones {
+				if zone.Name != exp.zone {
+					continue
+				}
+				if got := findResourceInfo(zone.Resources, exp.resource).Available; got.Cmp(resource.MustParse(exp.quantity)) != 0 {
+					t.Errorf("zone %s resource %s: got available %s expected %s", exp.zone, exp.resource, got.String(), exp.quantity)
+				}
+			}
+		}
+	}
+
+	check(t, []expectedAvailable{
+		{"node-0", cpu, "18"},
+		{"node-0", memory, "32Gi"},
+		{"node-1", cpu, "20"},
+		{"node-1", memory, "32Gi"},
+	})
+
+	// the reserved amount adds up with the pessimistic overallocation
+	nrtCache.ReserveNodeResources("node", testPod)
+	check(t, []expectedAvailable{
+		{"node-0", cpu, "14"},
+		{"node-1", cpu, "16"},
+	})
+
+	// the stored data is left untouched
+	if got := findResourceInfo(nrtCache.Store().GetNRTCopyByNodeName("node").Zones[0].Resources, cpu).Available; got.Cmp(resource.MustParse("20")) != 0 {
+		t.Errorf("stored data changed: got available cpu %s", got.String())
+	}
+}
+
+func TestGetCachedNRTCopyReleaseNone(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.UnreserveNodeResources("node1", testPod)
+
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
+	if !reflect.DeepEqual(nrtObj, nodeTopologies[0]) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(nodeTopologies[0]))
+	}
+}
+
+func TestGetCachedNRTCopyReserveRelease(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.ReserveNodeResources("node1", testPod)
+	nrtCache.UnreserveNodeResources("node1", testPod)
+
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
+	if !reflect.DeepEqual(nrtObj, nodeTopologies[0]) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(nodeTopologies[0]))
+	}
+}
+
+func TestGetCachedNRTCopyReserveInARow(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+	// the pods are reserved, but not bound yet: each lookup must account all the previous reservations
+	for idx, expectedCPUs := range []string{"16", "12", "8"} {
+		pod := makeOwnedPod(fmt.Sprintf("pod-%d", idx), types.UID("rs-1"), "4")
+		if _, err := nrtCache.ReserveNodeResources("node", pod); err != nil {
+			t.Fatalf("cannot reserve pod %q: %v", pod.Name, err)
+		}
+
+		nrtObj, _ := nrtCache.GetCachedNRTCopy("node", pod)
+		for _, zone := range nrtObj.Zones {
+			got := findResourceInfo(zone.Resources, cpu).Available
+			if got.Cmp(resource.MustParse(expectedCPUs)) != 0 {
+				t.Errorf("after reserving %q: unexpected cpu available on zone %s: %s expected %s", pod.Name, zone.Name, got.String(), expectedCPUs)
+			}
+		}
+	}
+}
+
+func TestReserveUnreserveIdempotent(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if nrtCache.UnreserveNodeResources("node1", testPod) {
+		t.Errorf("unreserved a pod never reserved")
+	}
+
+	if reserved, err := nrtCache.ReserveNodeResources("node1", testPod); err != nil || reserved {
+		t.Errorf("first reserve reported the pod as already reserved (err=%v)", err)
+	}
+	if reserved, err := nrtCache.ReserveNodeResources("node1", testPod); err != nil || !reserved {
+		t.Errorf("second reserve did not report the pod as already reserved (err=%v)", err)
+	}
+
+	expectedNRT := nodeTopologies[0].DeepCopy()
+	for zi := range expectedNRT.Zones {
+		expectedNRT.Zones[zi].Resources = topologyv1alpha1.ResourceInfoList{
+			MakeTopologyResInfo(cpu, "32", "22"),
+			MakeTopologyResInfo(memory, "64Gi", "44Gi"),
+			MakeTopologyResInfo(nicResourceName, "16", "16"),
+		}
+	}
+
+	// the double reserve must not account the pod twice
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
+	if dumpNRT(nrtObj) != dumpNRT(expectedNRT) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(expectedNRT))
+	}
+
+	if !nrtCache.UnreserveNodeResources("node1", testPod) {
+		t.Errorf("first unreserve did not report the pod as released")
+	}
+	if nrtCache.UnreserveNodeResources("node1", testPod) {
+		t.Errorf("second unreserve reported the pod as released")
+	}
+
+	nrtObj, _ = nrtCache.GetCachedNRTCopy("node1", testPod)
+	if !reflect.DeepEqual(nrtObj, nodeTopologies[0]) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(nodeTopologies[0]))
+	}
+}
+
+func TestUnreserveTwiceAfterRejectedPod(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		nrtCache.Store().Update(obj)
+	}
+
+	// our Reserve rejects the pod before tracking anything: the framework still runs Unreserve
+	hugePod := makeOwnedPod("pod-huge", "rs-0", "100")
+	if _, err := nrtCache.ReserveNodeResources("node1", hugePod); err == nil {
+		t.Fatalf("reserved a pod exceeding the zones capacity")
+	}
+	if nrtCache.UnreserveNodeResources("node1", hugePod) {
+		t.Errorf("unreserved a pod whose reserve failed")
+	}
+
+	// another plugin fails the pod after our Reserve: Unreserve may run more than once
+	testPod := makeOwnedPod("pod-0", "rs-0", "8")
+	otherPod := makeOwnedPod("pod-1", "rs-0", "4")
+	for _, pod := range []*corev1.Pod{testPod, otherPod} {
+		if _, err := nrtCache.ReserveNodeResources("node1", pod); err != nil {
+			t.Fatalf("failed to reserve %q: %v", pod.Name, err)
+		}
+	}
+
+	if !nrtCache.UnreserveNodeResources("node1", testPod) {
+		t.Errorf("first unreserve did not report the pod as released")
+	}
+	if nrtCache.UnreserveNodeResources("node1", testPod) {
+		t.Errorf("second unreserve reported the pod as released")
+	}
+
+	// the resources of the released pod are given back exactly once, the other pod is still accounted
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
+	for _, zone := range nrtObj.Zones {
+		got := findResourceInfo(zone.Resources, cpu).Available
+		if got.Cmp(resource.MustParse("26")) != 0 {
+			t.Errorf("unexpected cpu available on zone %s: %s expected 26", zone.Name, got.String())
+		}
+	}
+
+	if !nrtCache.UnreserveNodeResources("node1", otherPod) {
+		t.Errorf("unreserve did not report the other pod as released")
+	}
+	nrtObj, _ = nrtCache.GetCachedNRTCopy("node1", testPod)
+	if !reflect.DeepEqual(nrtObj, nodeTopologies[0]) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(nodeTopologies[0]))
+	}
+}
+
+func TestReserveNodeResourcesTargetZones(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+	testPod := makeOwnedPod("pod-0", "rs-0", "4")
+	if _, err := nrtCache.ReserveNodeResources("node", testPod, "node-1"); err != nil {
+		t.Fatalf("failed to reserve: %v", err)
+	}
+
+	expected := []string{
+		"zone node-0: cpu 20/20, memory 32Gi/32Gi",
+		"zone node-1: cpu 16/20, memory 32Gi/32Gi, " + nicName + " 8/8",
+	}
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node", testPod)
+	for zi, zone := range nrtObj.Zones {
+		if got := stringify.Zone(zone); got != expected[zi] {
+			t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, expected[zi])
+		}
+	}
+
+	// pods reserved without target zones are still charged to all the zones
+	otherPod := makeOwnedPod("pod-1", "rs-0", "2")
+	if _, err := nrtCache.ReserveNodeResources("node", otherPod); err != nil {
+		t.Fatalf("failed to reserve: %v", err)
+	}
+	expected = []string{
+		"zone node-0: cpu 18/20, memory 32Gi/32Gi",
+		"zone node-1: cpu 14/20, memory 32Gi/32Gi, " + nicName + " 8/8",
+	}
+	nrtObj, _ = nrtCache.GetCachedNRTCopy("node", testPod)
+	for zi, zone := range nrtObj.Zones {
+		if got := stringify.Zone(zone); got != expected[zi] {
+			t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, expected[zi])
+		}
+	}
+
+	// the target zones go away with the reservation
+	nrtCache.UnreserveNodeResources("node", testPod)
+	if _, err := nrtCache.ReserveNodeResources("node", testPod); err != nil {
+		t.Fatalf("failed to reserve: %v", err)
+	}
+	expected = []string{
+		"zone node-0: cpu 14/20, memory 32Gi/32Gi",
+		"zone node-1: cpu 14/20, memory 32Gi/32Gi, " + nicName + " 8/8",
+	}
+	nrtObj, _ = nrtCache.GetCachedNRTCopy("node", testPod)
+	for zi, zone := range nrtObj.Zones {
+		if got := stringify.Zone(zone); got != expected[zi] {
+			t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, expected[zi])
+		}
+	}
+}
+
+func TestGetCachedNRTCopyReserveExclusiveZone(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nodeTopology := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "30"),
+					MakeTopologyResInfo(memory, "64Gi", "60Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "32"),
+					MakeTopologyResInfo(memory, "64Gi", "64Gi"),
+				},
+			},
+		},
+	}
+	nrtCache.Store().Update(nodeTopology)
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+			Annotations: map[string]string{
+				AnnotationExclusiveZone: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.ReserveNodeResources("node1", testPod)
+
+	// the partially used zone is left untouched, the unused one is fully consumed
+	expectedNRT := nodeTopology.DeepCopy()
+	expectedNRT.Zones[1].Resources = topologyv1alpha1.ResourceInfoList{
+		MakeTopologyResInfo(cpu, "32", "0"),
+		MakeTopologyResInfo(memory, "64Gi", "0"),
+	}
+
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
+	if dumpNRT(nrtObj) != dumpNRT(expectedNRT) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(expectedNRT))
+	}
+
+	// no unused zones left, so the next exclusive pod is accounted pessimistically
+	otherPod := testPod.DeepCopy()
+	otherPod.Name = "pod2"
+	nrtCache.ReserveNodeResources("node1", otherPod)
+
+	expectedNRT.Zones[0].Resources = topologyv1alpha1.ResourceInfoList{
+		MakeTopologyResInfo(cpu, "32", "22"),
+		MakeTopologyResInfo(memory, "64Gi", "44Gi"),
+	}
+
+	nrtObj, _ = nrtCache.GetCachedNRTCopy("node1", testPod)
+	if dumpNRT(nrtObj) != dumpNRT(expectedNRT) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(expectedNRT))
+	}
+
+	nrtCache.UnreserveNodeResources("node1", testPod)
+	nrtCache.UnreserveNodeResources("node1", otherPod)
+
+	nrtObj, _ = nrtCache.GetCachedNRTCopy("node1", testPod)
+	if !reflect.DeepEqual(nrtObj, nodeTopology) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(nodeTopology))
+	}
+}
+
+func TestReserveNodeResourcesOversizedPod(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+	makePodWithRequests := func(name string, requests corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace1",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: requests,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// the zones have 40 cpus in total, so the pod can only be spread, but it can fit
+	if _, err := nrtCache.ReserveNodeResources("node", makePodWithRequests("pod-spread", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("40"),
+	})); err != nil {
+		t.Errorf("unexpected error reserving a pod fitting the node: %v", err)
+	}
+	// resources not reported by the zones are not checked
+	if _, err := nrtCache.ReserveNodeResources("node", makePodWithRequests("pod-storage", corev1.ResourceList{
+		corev1.ResourceEphemeralStorage: resource.MustParse("1Ti"),
+	})); err != nil {
+		t.Errorf("unexpected error reserving a pod with non NUMA resources: %v", err)
+	}
+	// nodes without data are not checked
+	if _, err := nrtCache.ReserveNodeResources("node-unknown", makePodWithRequests("pod-unknown", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("100"),
+	})); err != nil {
+		t.Errorf("unexpected error reserving a pod on a node without data: %v", err)
+	}
+
+	oversized := makePodWithRequests("pod-oversized", corev1.ResourceList{
+		corev1.ResourceCPU:                      resource.MustParse("4"),
+		corev1.ResourceName("vendor_A.com/nic"): resource.MustParse("9"),
+	})
+	if _, err := nrtCache.ReserveNodeResources("node", oversized); err == nil {
+		t.Fatalf("reserved a pod exceeding the capacity of the zones")
+	}
+
+	// the oversized pod is not accounted
+	nrt, _ := nrtCache.GetCachedNRTCopy("node", oversized)
+	if qty := findResourceInfo(nrt.Zones[1].Resources, "vendor_A.com/nic").Available; qty.Cmp(resource.MustParse("8")) != 0 {
+		t.Errorf("unexpected nic availability: %s", qty.String())
+	}
+}
+
+func TestGetCachedNRTCopyDiscountedResources(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace1",
+			Name:      "pod1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:                      resource.MustParse("4"),
+							corev1.ResourceMemory:                   resource.MustParse("8Gi"),
+							corev1.ResourceName("vendor_A.com/nic"): resource.MustParse("2"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		discounted  sets.String
+		expectedCPU string
+		expectedMem string
+		expectedNIC string
+	}{
+		{
+			name:        "all resources",
+			expectedCPU: "16",
+			expectedMem: "24Gi",
+			expectedNIC: "6",
+		},
+		{
+			name:        "memory only",
+			discounted:  sets.NewString(memory),
+			expectedCPU: "20",
+			expectedMem: "24Gi",
+			expectedNIC: "8",
+		},
+		{
+			name:        "memory and devices",
+			discounted:  sets.NewString(memory, "vendor_A.com/nic"),
+			expectedCPU: "20",
+			expectedMem: "24Gi",
+			expectedNIC: "6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeIndex := &fakePodByNodeNameIndex{}
+
+			nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+			nrtCache.DiscountedResources = tt.discounted
+			nrtCache.Store().Update(makeTwoZonesTestTopology())
+			nrtCache.ReserveNodeResources("node", pod)
+
+			nrt, ok := nrtCache.GetCachedNRTCopy("node", pod)
+			if !ok || nrt == nil {
+				t.Fatalf("missing cached data")
+			}
+			for _, zone := range nrt.Zones {
+				if got := findResourceInfo(zone.Resources, cpu).Available; got.Cmp(resource.MustParse(tt.expectedCPU)) != 0 {
+					t.Errorf("unexpected cpu availability on zone %s: %s expected %s", zone.Name, got.String(), tt.expectedCPU)
+				}
+				if got := findResourceInfo(zone.Resources, memory).Available; got.Cmp(resource.MustParse(tt.expectedMem)) != 0 {
+					t.Errorf("unexpected memory availability on zone %s: %s expected %s", zone.Name, got.String(), tt.expectedMem)
+				}
+			}
+			if got := findResourceInfo(nrt.Zones[1].Resources, "vendor_A.com/nic").Available; got.Cmp(resource.MustParse(tt.expectedNIC)) != 0 {
+				t.Errorf("unexpected nic availability: %s expected %s", got.String(), tt.expectedNIC)
+			}
+		})
+	}
+}
+
+func TestReserveNodeResourcesUnsetCapacity(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	// the agent reports only the availability, so the capacity is estimated from it
+	nrtCache.Store().Update(testutil.NewNRTBuilder("node").
+		WithPolicy(topologyv1alpha1.SingleNUMANodePodLevel).
+		WithZone("node-0").
+		WithResource(cpu, "0", "8").
+		WithResource(memory, "0", "8Gi").
+		Build())
+
+	makePodWithCPU := func(name, cpus string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace1",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse(cpus),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	if _, err := nrtCache.ReserveNodeResources("node", makePodWithCPU("pod-fit", "4")); err != nil {
+		t.Errorf("unexpected error reserving a pod fitting the availability: %v", err)
+	}
+	if _, err := nrtCache.ReserveNodeResources("node", makePodWithCPU("pod-oversized", "9")); err == nil {
+		t.Errorf("reserved a pod exceeding the availability")
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	if cov := nrtCache.Coverage(nil); cov != 0 {
+		t.Errorf("unexpected coverage with no nodes: %v", cov)
+	}
+
+	for _, nodeName := range []string{"node-0", "node-2", "node-4"} {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		}
+		nrtCache.Store().Update(nrt)
+		fakeInformer.Informer().GetStore().Add(nrt)
+	}
+
+	allNodeNames := []string{"node-0", "node-1", "node-2", "node-3", "node-4"}
+	if cov := nrtCache.Coverage(allNodeNames); cov != 0.6 {
+		t.Errorf("unexpected coverage: %v expected 0.6", cov)
+	}
+
+	pt := NewPassthrough(fakeInformer.Lister()).(Passthrough)
+	if cov := pt.Coverage(allNodeNames); cov != 0.6 {
+		t.Errorf("unexpected passthrough coverage: %v expected 0.6", cov)
+	}
+}
+
+func TestSchedulabilityFingerprint(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	if fp := nrtCache.SchedulabilityFingerprint("node"); fp != 0 {
+		t.Errorf("unexpected fingerprint for unknown node: %v", fp)
+	}
+
+	nrt := makeTwoZonesTestTopology()
+	nrtCache.Store().Update(nrt)
+	fp := nrtCache.SchedulabilityFingerprint("node")
+	if fp == 0 {
+		t.Fatalf("missing fingerprint for known node")
+	}
+	if fp2 := nrtCache.SchedulabilityFingerprint("node"); fp2 != fp {
+		t.Errorf("fingerprint not stable: %v then %v", fp, fp2)
+	}
+
+	annotated := nrt.DeepCopy()
+	annotated.Annotations = map[string]string{"test": "annotation"}
+	nrtCache.Store().Update(annotated)
+	if fp2 := nrtCache.SchedulabilityFingerprint("node"); fp2 != fp {
+		t.Errorf("fingerprint changed on annotation change: %v then %v", fp, fp2)
+	}
+
+	updated := annotated.DeepCopy()
+	findResourceInfo(updated.Zones[0].Resources, cpu).Available = resource.MustParse("10")
+	nrtCache.Store().Update(updated)
+	if fp2 := nrtCache.SchedulabilityFingerprint("node"); fp2 == fp {
+		t.Errorf("fingerprint not changed on availability change: %v", fp)
+	}
+}
+
+func TestHeadroomTrend(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	if trend := nrtCache.HeadroomTrend("node1", cpu); trend != 0 {
+		t.Errorf("unexpected trend for unknown node: %v", trend)
+	}
+
+	for _, availCPU := range []string{"30", "26", "24", "20", "16"} {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "30", availCPU),
+						MakeTopologyResInfo(memory, "60Gi", "60Gi"),
+					},
+				},
+			},
+		}
+		nrtCache.FlushNodes("testHeadroomTrend", nrt)
+	}
+
+	if trend := nrtCache.HeadroomTrend("node1", cpu); trend >= 0 {
+		t.Errorf("expected negative cpu trend, got %v", trend)
+	}
+	if trend := nrtCache.HeadroomTrend("node1", memory); trend != 0 {
+		t.Errorf("expected flat memory trend, got %v", trend)
+	}
+}
+
+func TestInfeasibilityRisk(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	if risk := nrtCache.InfeasibilityRisk("node1", pod, 3); risk != 0 {
+		t.Errorf("unexpected risk for unknown node: %v", risk)
+	}
+
+	// steep downward trend: the cpu availability is expected to drop below 8 within 3 updates
+	for _, availCPU := range []string{"30", "26", "24", "20", "16"} {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "30", availCPU),
+						MakeTopologyResInfo(memory, "60Gi", "60Gi"),
+					},
+				},
+			},
+		}
+		nrtCache.FlushNodes("testInfeasibilityRisk", nrt)
+	}
+
+	if risk := nrtCache.InfeasibilityRisk("node1", pod, 3); risk < 0.9 {
+		t.Errorf("expected elevated risk within 3 updates, got %v", risk)
+	}
+	if risk := nrtCache.InfeasibilityRisk("node1", pod, 0); risk > 0.1 {
+		t.Errorf("expected low risk at the current update, got %v", risk)
+	}
+}
+
+func TestHeadroomTrackerWindow(t *testing.T) {
+	ht := newHeadroomTracker(3)
+	for _, availCPU := range []string{"2", "4", "16", "12", "8"} {
+		ht.Record(&topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "16", availCPU),
+					},
+				},
+			},
+		})
+	}
+	// only the last 3 samples (16, 12, 8) are considered
+	if trend := ht.Trend("node1", cpu); trend != -4 {
+		t.Errorf("unexpected trend: %v expected -4", trend)
+	}
+}
+
+func TestSimulateAddNode(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace1",
+			Name:      "pod1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	podFits := func(nrt *topologyv1alpha1.NodeResourceTopology) bool {
+		if nrt == nil {
+			return false
+		}
+		for _, zone := range nrt.Zones {
+			if zoneCanFit(zone, pod.Spec.Containers[0].Resources.Requests) {
+				return true
+			}
+		}
+		return false
+	}
+
+	nrt, _ := nrtCache.GetCachedNRTCopy("node1", pod)
+	if podFits(nrt) {
+		t.Fatalf("pod fits unknown node")
+	}
+
+	undo := nrtCache.SimulateAddNode(&topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "30"),
+					MakeTopologyResInfo(memory, "64Gi", "60Gi"),
+				},
+			},
+		},
+	})
+	nrt, _ = nrtCache.GetCachedNRTCopy("node1", pod)
+	if !podFits(nrt) {
+		t.Fatalf("pod does not fit the simulated node")
+	}
+
+	undo()
+	nrt, _ = nrtCache.GetCachedNRTCopy("node1", pod)
+	if nrt != nil {
+		t.Fatalf("simulated node not removed: %s", dumpNRT(nrt))
+	}
+}
+
+func TestSimulateAddNodeRestoresPrevious(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nrtOrig := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "2"),
+					MakeTopologyResInfo(memory, "64Gi", "4Gi"),
+				},
+			},
+		},
+	}
+	nrtCache.Store().Update(nrtOrig)
+
+	nrtSim := nrtOrig.DeepCopy()
+	nrtSim.Zones[0].Resources = topologyv1alpha1.ResourceInfoList{
+		MakeTopologyResInfo(cpu, "32", "30"),
+		MakeTopologyResInfo(memory, "64Gi", "60Gi"),
+	}
+	undo := nrtCache.SimulateAddNode(nrtSim)
+
+	pod := &corev1.Pod{}
+	nrt, _ := nrtCache.GetCachedNRTCopy("node1", pod)
+	if dumpNRT(nrt) != dumpNRT(nrtSim) {
+		t.Fatalf("unexpected simulated data: %s", dumpNRT(nrt))
+	}
+
+	undo()
+	nrt, _ = nrtCache.GetCachedNRTCopy("node1", pod)
+	if dumpNRT(nrt) != dumpNRT(nrtOrig) {
+		t.Fatalf("previous data not restored: %s", dumpNRT(nrt))
+	}
+}
+
+func TestPodsByMisalignment(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	nrt := makeTwoZonesTestTopology()
+	nrtCache.Store().Update(nrt)
+
+	makePodWithCPU := func(name, cpuQty string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace1",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse(cpuQty),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// each zone has 20 cpus, so the first pod must be spread across both zones
+	nrtCache.ReserveNodeResources("node", makePodWithCPU("pod-aligned", "4"))
+	nrtCache.ReserveNodeResources("node", makePodWithCPU("pod-spread", "30"))
+
+	got := nrtCache.PodsByMisalignment(nrt)
+	expected := []string{"namespace1/pod-spread", "namespace1/pod-aligned"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected pods order: got %v expected %v", got, expected)
+	}
+
+	if got := nrtCache.PodsByMisalignment(&topologyv1alpha1.NodeResourceTopology{ObjectMeta: metav1.ObjectMeta{Name: "unknown"}}); len(got) != 0 {
+		t.Fatalf("unexpected pods for unknown node: %v", got)
+	}
+}
+
+func TestCanEvacuate(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	for _, nodeName := range []string{"node-a", "node-b", "node-c"} {
+		nrt := makeTwoZonesTestTopology()
+		nrt.Name = nodeName
+		nrtCache.Store().Update(nrt)
+	}
+
+	makePodWithCPU := func(name, cpuQty string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace1",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse(cpuQty),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	nrtCache.ReserveNodeResources("node-a", makePodWithCPU("pod-small", "8"))
+	nrtCache.ReserveNodeResources("node-a", makePodWithCPU("pod-medium", "12"))
+	// every zone has 20 cpus, so this pod can't fit in any single zone
+	nrtCache.ReserveNodeResources("node-a", makePodWithCPU("pod-huge", "30"))
+	// the targets are partially used already: 10 cpus are left in each zone
+	nrtCache.ReserveNodeResources("node-b", makePodWithCPU("pod-other", "10"))
+
+	ok, unplaced := nrtCache.CanEvacuate("node-a", []string{"node-a", "node-b"})
+	if ok {
+		t.Fatalf("expected evacuation to fail")
+	}
+	expected := []string{"namespace1/pod-huge", "namespace1/pod-medium"}
+	if !reflect.DeepEqual(unplaced, expected) {
+		t.Fatalf("unexpected unplaced pods: got %v expected %v", unplaced, expected)
+	}
+
+	ok, unplaced = nrtCache.CanEvacuate("node-a", []string{"node-b", "node-c"})
+	if ok {
+		t.Fatalf("expected evacuation to fail")
+	}
+	expected = []string{"namespace1/pod-huge"}
+	if !reflect.DeepEqual(unplaced, expected) {
+		t.Fatalf("unexpected unplaced pods: got %v expected %v", unplaced, expected)
+	}
+
+	nrtCache.UnreserveNodeResources("node-a", makePodWithCPU("pod-huge", "30"))
+	ok, unplaced = nrtCache.CanEvacuate("node-a", []string{"node-b", "node-c"})
+	if !ok || len(unplaced) != 0 {
+		t.Fatalf("expected evacuation to succeed, unplaced: %v", unplaced)
+	}
+
+	// the check is speculative: the targets are left untouched
+	obj, _ := nrtCache.GetCachedNRTCopy("node-c", &corev1.Pod{})
+	for _, zone := range obj.Zones {
+		if got := findResourceInfo(zone.Resources, cpu).Available; got.Cmp(resource.MustParse("20")) != 0 {
+			t.Errorf("zone %s: unexpected available cpu %s", zone.Name, got.String())
+		}
+	}
+}
+
+func TestAssertMatches(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+		other := obj.DeepCopy()
+		other.Name = "node2"
+		nrtCache.Store().Update(other)
+	}
+
+	makePodWithCPU := func(name, cpuQty string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace1",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse(cpuQty),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	nrtCache.ReserveNodeResources("node1", makePodWithCPU("pod1", "2"))
+	nrtCache.NodeMaybeOverReserved("node2", makePodWithCPU("pod2", "2"))
+
+	golden := nrtCache.Snapshot()
+	// the snapshot is meant to be stored as fixture
+	data, err := json.Marshal(golden)
+	if err != nil {
+		t.Fatalf("cannot serialize the snapshot: %v", err)
+	}
+	golden = StateSnapshot{}
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("cannot deserialize the snapshot: %v", err)
+	}
+
+	if errs := nrtCache.AssertMatches(golden); len(errs) != 0 {
+		t.Fatalf("unexpected discrepancies: %v", errs)
+	}
+
+	nrtCache.UnreserveNodeResources("node1", makePodWithCPU("pod1", "2"))
+	// reserving resets the discard counter of the node
+	nrtCache.ReserveNodeResources("node2", makePodWithCPU("pod3", "4"))
+	nrt := nrtCache.Store().GetNRTCopyByNodeName("node2")
+	nrt.Zones[0].Resources[0].Available = resource.MustParse("1")
+	nrtCache.Store().Update(nrt)
+
+	var got []string
+	for _, err := range nrtCache.AssertMatches(golden) {
+		got = append(got, err.Error())
+	}
+	expected := []string{
+		`node "node2": NodeTopology differs`,
+		`node "node1": missing reservation for pod "namespace1/pod1"`,
+		`node "node2": unexpected reservation for pod "namespace1/pod3"`,
+		`node "node2": expected maybe overreserved`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected discrepancies:\ngot:      %v\nexpected: %v", got, expected)
+	}
+}
+
+func TestDump(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+		other := obj.DeepCopy()
+		other.Name = "node2"
+		nrtCache.Store().Update(other)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace1",
+			Name:      "pod1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("2"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.ReserveNodeResources("node1", pod)
+	nrtCache.NodeHasForeignPods("node2", pod)
+	nrtCache.NodeHasForeignPods("node2", pod)
+
+	data, err := nrtCache.Dump()
+	if err != nil {
+		t.Fatalf("cannot dump the cache: %v", err)
+	}
+	data2, err := nrtCache.Dump()
+	if err != nil {
+		t.Fatalf("cannot dump the cache: %v", err)
+	}
+	if string(data) != string(data2) {
+		t.Errorf("dump is not stable:\n%s\n%s", data, data2)
+	}
+
+	var dump StateDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		t.Fatalf("cannot deserialize the dump: %v", err)
+	}
+	if nodeNames := sets.StringKeySet(dump.NodeTopologies).List(); !reflect.DeepEqual(nodeNames, []string{"node1", "node2"}) {
+		t.Errorf("unexpected nodes: %v", nodeNames)
+	}
+	res, ok := dump.Reservations["node1"]["namespace1/pod1"]
+	if !ok || !res.Cpu().Equal(resource.MustParse("2")) {
+		t.Errorf("unexpected reservations: %v", dump.Reservations)
+	}
+	if !reflect.DeepEqual(dump.ForeignPods, map[string]int{"node2": 2}) {
+		t.Errorf("unexpected foreign pods: %v", dump.ForeignPods)
+	}
+	if errs := nrtCache.AssertMatches(dump.StateSnapshot); len(errs) != 0 {
+		t.Errorf("dump does not match the cache: %v", errs)
+	}
+}
+
+func TestLockContentionStats(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	nrtCache.Store().Update(&topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "30"),
+					MakeTopologyResInfo(memory, "64Gi", "60Gi"),
+				},
+			},
+		},
+	})
+
+	const workers = 8
+	const iterations = 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "namespace1",
+						Name:      fmt.Sprintf("pod-%d-%d", w, i),
+					},
+				}
+				nrtCache.ReserveNodeResources("node1", pod)
+				nrtCache.GetCachedNRTCopy("node1", pod)
+				nrtCache.UnreserveNodeResources("node1", pod)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats := nrtCache.LockContentionStats()
+	if stats.Acquisitions < workers*iterations*3 {
+		t.Errorf("too few acquisitions recorded: %d", stats.Acquisitions)
+	}
+	if stats.Contended > stats.Acquisitions {
+		t.Errorf("more contended acquisitions (%d) than acquisitions (%d)", stats.Contended, stats.Acquisitions)
+	}
+	if stats.MaxWait > stats.TotalWait {
+		t.Errorf("max wait %v exceeds total wait %v", stats.MaxWait, stats.TotalWait)
+	}
+}
+
+func TestOverReserveConcurrentAccess(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		fakeInformer.Informer().GetStore().Add(obj)
+	}
+
+	nrtCache, err := NewOverReserve(fakeInformer.Lister(), fakeIndex, 1)
+	if err != nil {
+		t.Fatalf("unexpected error creating cache: %v", err)
+	}
+	nrtCache.RebuildInterval = time.Nanosecond
+
+	// the stores and the counters are not thread safe on their own: all the access must happen through
+	// the cache, which serializes it. Run with the race detector to catch any unprotected access.
+	const workers = 8
+	const iterations = 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				nodeName := nodeTopologies[(w+i)%len(nodeTopologies)].Name
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "namespace1",
+						Name:      fmt.Sprintf("pod-%d-%d", w, i),
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU: resource.MustParse("1"),
+									},
+								},
+							},
+						},
+					},
+				}
+				switch w % 4 {
+				case 0:
+					nrtCache.ReserveNodeResources(nodeName, pod)
+					nrtCache.GetCachedNRTCopy(nodeName, pod)
+					nrtCache.UnreserveNodeResources(nodeName, pod)
+				case 1:
+					nrtCache.NodeMaybeOverReserved(nodeName, pod)
+					nrtCache.NodesMaybeOverReserved("testing")
+				case 2:
+					nrtCache.NodeHasForeignPods(nodeName, pod)
+					nrtCache.Resync()
+				case 3:
+					nrtCache.FlushNodes("testing", nodeTopologies[i%len(nodeTopologies)])
+					nrtCache.Coverage([]string{nodeName})
+					nrtCache.HeadroomTrend(nodeName, cpu)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// all the workers are done, so no locking is needed anymore
+	for _, obj := range nodeTopologies {
+		if !nrtCache.Store().Contains(obj.Name) {
+			t.Errorf("node %q missing from cache", obj.Name)
+		}
+	}
+}
+
+func TestInstrumentedMutexContended(t *testing.T) {
+	var im instrumentedMutex
+	im.Lock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		im.Lock()
+		im.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	im.Unlock()
+	<-done
+
+	stats := im.Stats()
+	if stats.Acquisitions != 2 {
+		t.Errorf("unexpected acquisitions: %d", stats.Acquisitions)
+	}
+	if stats.Contended != 1 {
+		t.Errorf("unexpected contended acquisitions: %d", stats.Contended)
+	}
+	if stats.MaxWait <= 0 || stats.MaxWait != stats.TotalWait {
+		t.Errorf("unexpected wait times: max %v total %v", stats.MaxWait, stats.TotalWait)
+	}
+}
+
+func TestResyncRebuildsAvailability(t *testing.T) {
 	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
 	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
 	fakeIndex := &fakePodByNodeNameIndex{}
 
-	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	nrt := makeTwoZonesTestTopology()
+	fakeInformer.Informer().GetStore().Add(nrt)
 
-	nodeTopologies := makeDefaultTestTopology()
-	for _, obj := range nodeTopologies {
-		nrtCache.Store().Update(obj)
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	nrtCache.clock = fakeClock
+	nrtCache.lastRebuild = fakeClock.Now()
+	nrtCache.RebuildInterval = 5 * time.Minute
+
+	// inject drift: the deltas make the cached data claim less availability than the apiserver data
+	drift := ZoneResourceDelta{Sequence: 5, Zones: map[string]corev1.ResourceList{}}
+	for _, zone := range nrt.Zones {
+		drift.Zones[zone.Name] = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("-14")}
+	}
+	if !nrtCache.ApplyDelta("node", drift) {
+		t.Fatalf("drift delta not applied")
 	}
 
 	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
 					Resources: corev1.ResourceRequirements{
-						Limits: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("8"),
-							corev1.ResourceMemory: resource.MustParse("16Gi"),
-						},
 						Requests: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("8"),
-							corev1.ResourceMemory: resource.MustParse("16Gi"),
+							corev1.ResourceCPU: resource.MustParse("4"),
 						},
 					},
 				},
 			},
 		},
 	}
-	nrtCache.UnreserveNodeResources("node1", testPod)
+	nrtCache.ReserveNodeResources("node", testPod)
 
-	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
-	if !reflect.DeepEqual(nrtObj, nodeTopologies[0]) {
-		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(nodeTopologies[0]))
+	availableCPU := func() int64 {
+		t.Helper()
+		obj, ok := nrtCache.GetCachedNRTCopy("node", testPod)
+		if !ok {
+			t.Fatalf("expected node in cache")
+		}
+		var total int64
+		for _, zone := range obj.Zones {
+			total += findResourceInfo(zone.Resources, cpu).Available.Value()
+		}
+		return total
+	}
+
+	// cached drifted availability (6+6) minus the reservation (4+4, the owning zone is unknown)
+	if got := availableCPU(); got != 4 {
+		t.Fatalf("unexpected available cpu before rebuild: got %d expected 4", got)
+	}
+
+	fakeClock.Step(4 * time.Minute)
+	nrtCache.Resync()
+	if got := availableCPU(); got != 4 {
+		t.Fatalf("unexpected available cpu before the rebuild interval: got %d expected 4", got)
+	}
+
+	fakeClock.Step(2 * time.Minute)
+	nrtCache.Resync()
+	// apiserver availability (20+20) minus the reservation, which must survive the rebuild
+	if got := availableCPU(); got != 32 {
+		t.Fatalf("unexpected available cpu after rebuild: got %d expected 32", got)
+	}
+
+	// the rebuild is a full update, so the next delta starts a new sequence
+	if !nrtCache.ApplyDelta("node", ZoneResourceDelta{Sequence: 1, Zones: map[string]corev1.ResourceList{
+		nrt.Zones[0].Name: {corev1.ResourceCPU: resource.MustParse("-2")},
+	}}) {
+		t.Fatalf("delta after the rebuild not applied")
+	}
+	if got := availableCPU(); got != 30 {
+		t.Fatalf("unexpected available cpu after the delta: got %d expected 30", got)
 	}
 }
 
-func TestGetCachedNRTCopyReserveRelease(t *testing.T) {
+func TestGetCachedNRTCopyReserveGranularity(t *testing.T) {
 	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
 	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
 	fakeIndex := &fakePodByNodeNameIndex{}
 
 	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	nrtCache.Granularity = map[corev1.ResourceName]resource.Quantity{
+		corev1.ResourceCPU: resource.MustParse("1"),
+	}
 
-	nodeTopologies := makeDefaultTestTopology()
-	for _, obj := range nodeTopologies {
-		nrtCache.Store().Update(obj)
+	nodeTopology := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "20"),
+					MakeTopologyResInfo(memory, "64Gi", "60Gi"),
+				},
+			},
+		},
 	}
+	nrtCache.Store().Update(nodeTopology)
 
 	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
 					Resources: corev1.ResourceRequirements{
-						Limits: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("8"),
-							corev1.ResourceMemory: resource.MustParse("16Gi"),
-						},
 						Requests: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("8"),
-							corev1.ResourceMemory: resource.MustParse("16Gi"),
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("1536Mi"),
 						},
 					},
 				},
@@ -307,11 +1713,16 @@ func TestGetCachedNRTCopyReserveRelease(t *testing.T) {
 		},
 	}
 	nrtCache.ReserveNodeResources("node1", testPod)
-	nrtCache.UnreserveNodeResources("node1", testPod)
 
 	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
-	if !reflect.DeepEqual(nrtObj, nodeTopologies[0]) {
-		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(nodeTopologies[0]))
+	cpuInfo := findResourceInfo(nrtObj.Zones[0].Resources, cpu)
+	if expected := resource.MustParse("19"); cpuInfo.Available.Cmp(expected) != 0 {
+		t.Errorf("unexpected cpu available: got %s expected %s", cpuInfo.Available.String(), expected.String())
+	}
+	// no granularity set, so no flooring
+	memInfo := findResourceInfo(nrtObj.Zones[0].Resources, memory)
+	if expected := resource.MustParse("59904Mi"); memInfo.Available.Cmp(expected) != 0 {
+		t.Errorf("unexpected memory available: got %s expected %s", memInfo.Available.String(), expected.String())
 	}
 }
 
@@ -548,6 +1959,226 @@ func TestResyncMatchFingerprint(t *testing.T) {
 	}
 }
 
+func TestReconcileDropsReservations(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.ReserveNodeResources("node1", testPod)
+	fakeIndex.Add(testPod)
+
+	// the NRT data already accounts for the reserved pod on the second zone
+	updatedNodeTopology := nodeTopologies[0].DeepCopy()
+	updatedNodeTopology.Annotations = map[string]string{
+		podfingerprint.Annotation: "pfp0v0019e0420efb37746c6",
+	}
+	updatedNodeTopology.Zones[1].Resources = topologyv1alpha1.ResourceInfoList{
+		MakeTopologyResInfo(cpu, "32", "22"),
+		MakeTopologyResInfo(memory, "64Gi", "44Gi"),
+		MakeTopologyResInfo(nicResourceName, "16", "16"),
+	}
+
+	mismatchingNodeTopology := updatedNodeTopology.DeepCopy()
+	mismatchingNodeTopology.Annotations[podfingerprint.Annotation] = "pfp0v001ffffffffffffffff"
+	if nrtCache.reconcile("testing", "node1", mismatchingNodeTopology) {
+		t.Fatalf("reconciled node with mismatching fingerprint")
+	}
+	if !nrtCache.Store().Contains("node1") || nrtCache.assumedResources["node1"] == nil {
+		t.Fatalf("reservations dropped on fingerprint mismatch")
+	}
+
+	if !nrtCache.reconcile("testing", "node1", updatedNodeTopology) {
+		t.Fatalf("failed to reconcile node with matching fingerprint")
+	}
+
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
+	if !reflect.DeepEqual(nrtObj, updatedNodeTopology) {
+		t.Fatalf("unexpected object from cache\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(updatedNodeTopology))
+	}
+}
+
+func TestResyncMismatchFingerprintPassthrough(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	mismatchThreshold := 3
+	nrtCache, err := NewOverReserve(fakeInformer.Lister(), fakeIndex, mismatchThreshold)
+	if err != nil {
+		t.Fatalf("unexpected error creating cache: %v", err)
+	}
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.ReserveNodeResources("node1", testPod)
+	nrtCache.NodeMaybeOverReserved("node1", testPod)
+
+	updatedNodeTopology := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+			Annotations: map[string]string{
+				podfingerprint.Annotation: "pfp0v001ffffffffffffffff",
+			},
+		},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "30"),
+					MakeTopologyResInfo(memory, "64Gi", "60Gi"),
+					MakeTopologyResInfo(nicResourceName, "16", "16"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "32", "22"),
+					MakeTopologyResInfo(memory, "64Gi", "44Gi"),
+					MakeTopologyResInfo(nicResourceName, "16", "16"),
+				},
+			},
+		},
+	}
+
+	fakeInformer.Informer().GetStore().Add(updatedNodeTopology)
+	fakeIndex.Add(testPod)
+
+	for i := 1; i < mismatchThreshold; i++ {
+		nrtCache.Resync()
+		if nrtCache.isNodeInPassthrough("node1") {
+			t.Fatalf("node in passthrough mode after %d mismatches", i)
+		}
+	}
+
+	// reaching the threshold is still tolerated
+	nrtCache.Resync()
+	if nrtCache.isNodeInPassthrough("node1") {
+		t.Fatalf("node in passthrough mode after %d mismatches", mismatchThreshold)
+	}
+
+	nrtCache.Resync()
+	if !nrtCache.isNodeInPassthrough("node1") {
+		t.Fatalf("node not in passthrough mode after %d mismatches", mismatchThreshold+1)
+	}
+
+	dirtyNodes := nrtCache.NodesMaybeOverReserved("testing")
+	if len(dirtyNodes) != 1 || dirtyNodes[0] != "node1" {
+		t.Errorf("passthrough node not considered dirty: %v", dirtyNodes)
+	}
+
+	// in passthrough mode we expect the NRT data as-is, without any overreserve discount
+	nrtObj, _ := nrtCache.GetCachedNRTCopy("node1", testPod)
+	if !reflect.DeepEqual(nrtObj, updatedNodeTopology) {
+		t.Fatalf("unexpected object from cache in passthrough mode\ngot: %s\nexpected: %s\n", dumpNRT(nrtObj), dumpNRT(updatedNodeTopology))
+	}
+
+	matchingNodeTopology := updatedNodeTopology.DeepCopy()
+	matchingNodeTopology.Annotations[podfingerprint.Annotation] = "pfp0v0019e0420efb37746c6"
+	fakeInformer.Informer().GetStore().Update(matchingNodeTopology)
+
+	nrtCache.Resync()
+	if nrtCache.isNodeInPassthrough("node1") {
+		t.Fatalf("node still in passthrough mode after a fingerprint match")
+	}
+
+	dirtyNodes = nrtCache.NodesMaybeOverReserved("testing")
+	if len(dirtyNodes) > 0 {
+		t.Errorf("node still dirty after resyncing with good data: %v", dirtyNodes)
+	}
+}
+
+func TestResyncMismatchFingerprintPassthroughDisabled(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	nodeTopologies := makeDefaultTestTopology()
+	for _, obj := range nodeTopologies {
+		nrtCache.Store().Update(obj)
+		updated := obj.DeepCopy()
+		updated.Annotations = map[string]string{
+			podfingerprint.Annotation: "pfp0v001ffffffffffffffff",
+		}
+		fakeInformer.Informer().GetStore().Add(updated)
+	}
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "namespace1",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+		},
+	}
+	nrtCache.NodeMaybeOverReserved("node1", testPod)
+	fakeIndex.Add(testPod)
+
+	for i := 0; i < 10; i++ {
+		nrtCache.Resync()
+	}
+	if nrtCache.isNodeInPassthrough("node1") {
+		t.Fatalf("node in passthrough mode with mismatch threshold disabled")
+	}
+}
+
 func TestUnknownNodeWithForeignPods(t *testing.T) {
 	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
 	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
@@ -638,6 +2269,34 @@ func TestNodeWithForeignPods(t *testing.T) {
 	}
 }
 
+func TestHasForeignPods(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+
+	nrt := makeDefaultTestTopology()[0]
+	nrtCache.Store().Update(nrt)
+
+	if nrtCache.HasForeignPods(nrt.Name) {
+		t.Fatalf("node %q marked with foreign pods before any mark", nrt.Name)
+	}
+
+	nrtCache.NodeHasForeignPods(nrt.Name, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foreign-1"}})
+	nrtCache.NodeHasForeignPods(nrt.Name, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foreign-2"}})
+	if !nrtCache.HasForeignPods(nrt.Name) {
+		t.Fatalf("node %q not marked with foreign pods", nrt.Name)
+	}
+	if nrtCache.HasForeignPods("node-bogus") {
+		t.Errorf("unknown node marked with foreign pods")
+	}
+
+	nrtCache.FlushNodes("testing", nrt.DeepCopy())
+	if nrtCache.HasForeignPods(nrt.Name) {
+		t.Errorf("node %q still marked with foreign pods after flush", nrt.Name)
+	}
+}
+
 func dumpNRT(nrtObj *topologyv1alpha1.NodeResourceTopology) string {
 	nrtJson, err := json.MarshalIndent(nrtObj, "", " ")
 	if err != nil {
@@ -714,9 +2373,27 @@ func makeDefaultTestTopology() []*topologyv1alpha1.NodeResourceTopology {
 }
 
 func mustOverReserve(t *testing.T, lister listerv1alpha1.NodeResourceTopologyLister, indexer NodeIndexer) *OverReserve {
-	obj, err := NewOverReserve(lister, indexer)
+	obj, err := NewOverReserve(lister, indexer, 0)
 	if err != nil {
 		t.Fatalf("unexpected error creating cache: %v", err)
 	}
 	return obj
 }
+
+func TestNRTBuilderTwoZonesTestTopology(t *testing.T) {
+	got := testutil.NewNRTBuilder("node").
+		WithPolicy(topologyv1alpha1.SingleNUMANodePodLevel).
+		WithZone("node-0").
+		WithResource(cpu, "20", "20").
+		WithResource(memory, "32Gi", "32Gi").
+		WithZone("node-1").
+		WithResource(cpu, "20", "20").
+		WithResource(memory, "32Gi", "32Gi").
+		WithResource(nicName, "8", "8").
+		Build()
+
+	expected := makeTwoZonesTestTopology()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("built NRT differs from the fixture:\ngot=%s\nexpected=%s", stringify.NodeResourceTopologyResources(got), stringify.NodeResourceTopologyResources(expected))
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// SetPodZones records the names of the NUMA zones the given pod, reserved on the given node, is expected to be
+// allocated from. The accounting is not changed: the reserved resources are still subtracted from all the zones.
+// Returns false if the pod is not reserved on the node, or requests no resources.
+func (ov *OverReserve) SetPodZones(nodeName string, pod *corev1.Pod, zoneNames []string) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	nodeAssumedResources, ok := ov.assumedResources[nodeName]
+	if !ok {
+		return false
+	}
+	key := pod.Namespace + "/" + pod.Name
+	if _, ok := nodeAssumedResources.data[key]; !ok {
+		return false
+	}
+	nodeAssumedResources.zones[key] = append([]string{}, zoneNames...)
+	klog.V(5).InfoS("nrtcache: set pod zones", "logID", klog.KObj(pod), "node", nodeName, "zones", zoneNames)
+	return true
+}
+
+// setPodTargetZones records the names of the NUMA zones the given pod, reserved on the given node, is charged to.
+// Unlike SetPodZones, this changes the accounting: the reserved resources are subtracted only from these zones.
+// Must be called with the lock held. Returns false if the pod is not reserved on the node, or requests no resources.
+func (ov *OverReserve) setPodTargetZones(nodeName string, pod *corev1.Pod, zoneNames []string) bool {
+	nodeAssumedResources, ok := ov.assumedResources[nodeName]
+	if !ok {
+		return false
+	}
+	key := pod.Namespace + "/" + pod.Name
+	if _, ok := nodeAssumedResources.data[key]; !ok {
+		return false
+	}
+	nodeAssumedResources.targetZones[key] = append([]string{}, zoneNames...)
+	klog.V(5).InfoS("nrtcache: set pod target zones", "logID", klog.KObj(pod), "node", nodeName, "zones", zoneNames)
+	return true
+}
+
+// OwnerPodsPerZone returns how many pods owned by the same controller of the given pod are expected to be allocated
+// from each NUMA zone of the given node, among the pods reserved on the node with known zones. The given pod itself
+// is not counted. Returns nil if the pod has no controller.
+func (ov *OverReserve) OwnerPodsPerZone(nodeName string, pod *corev1.Pod) map[string]int {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil
+	}
+
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	nodeAssumedResources, ok := ov.assumedResources[nodeName]
+	if !ok {
+		return nil
+	}
+	podKey := pod.Namespace + "/" + pod.Name
+	counts := make(map[string]int)
+	for key, zoneNames := range nodeAssumedResources.zones {
+		if key == podKey || nodeAssumedResources.owners[key].UID != owner.UID {
+			continue
+		}
+		for _, zoneName := range zoneNames {
+			counts[zoneName]++
+		}
+	}
+	return counts
+}
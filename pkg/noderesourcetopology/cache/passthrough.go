@@ -44,7 +44,28 @@ func (pt Passthrough) GetCachedNRTCopy(nodeName string, _ *corev1.Pod) (*topolog
 	return nrt, true
 }
 
-func (pt Passthrough) NodeMaybeOverReserved(nodeName string, pod *corev1.Pod)  {}
-func (pt Passthrough) NodeHasForeignPods(nodeName string, pod *corev1.Pod)     {}
-func (pt Passthrough) ReserveNodeResources(nodeName string, pod *corev1.Pod)   {}
-func (pt Passthrough) UnreserveNodeResources(nodeName string, pod *corev1.Pod) {}
+// Coverage returns the ratio of the given nodes which have NRT data, or zero if no nodes are given.
+func (pt Passthrough) Coverage(allNodeNames []string) float64 {
+	if len(allNodeNames) == 0 {
+		return 0
+	}
+	covered := 0
+	for _, nodeName := range allNodeNames {
+		if _, err := pt.lister.Get(nodeName); err == nil {
+			covered++
+		}
+	}
+	return float64(covered) / float64(len(allNodeNames))
+}
+
+func (pt Passthrough) NodeMaybeOverReserved(nodeName string, pod *corev1.Pod) {}
+func (pt Passthrough) NodeHasForeignPods(nodeName string, pod *corev1.Pod)    {}
+func (pt Passthrough) HasForeignPods(nodeName string) bool                    { return false }
+func (pt Passthrough) ReserveNodeResources(nodeName string, pod *corev1.Pod, targetZones ...string) (bool, error) {
+	return false, nil
+}
+func (pt Passthrough) UnreserveNodeResources(nodeName string, pod *corev1.Pod) bool { return false }
+func (pt Passthrough) SetPodZones(nodeName string, pod *corev1.Pod, zoneNames []string) bool {
+	return false
+}
+func (pt Passthrough) OwnerPodsPerZone(nodeName string, pod *corev1.Pod) map[string]int { return nil }
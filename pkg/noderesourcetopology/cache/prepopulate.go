@@ -0,0 +1,54 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"k8s.io/klog/v2"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// Prepopulate seeds the cache with the given NRT data in bulk, like at startup, replacing the data already cached
+// for the same nodes. The update time and the podset fingerprint of each node are recorded, so the cold start state
+// is known. The seeded data is trusted immediately, regardless of NRTTrustDelay. Returns how many nodes were seeded.
+func (ov *OverReserve) Prepopulate(nrts []*topologyv1alpha1.NodeResourceTopology) int {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	seeded := 0
+	for _, nrt := range nrts {
+		if nrt == nil {
+			continue
+		}
+		ov.nrts.Update(nrt)
+		ov.headroom.Record(nrt)
+		ov.initialFingerprints[nrt.Name] = podFingerprintForNodeTopology(nrt)
+		delete(ov.untrustedNodes, nrt.Name)
+		seeded++
+	}
+	klog.V(4).InfoS("nrtcache: prepopulated", "objects", seeded)
+	return seeded
+}
+
+// InitialFingerprint returns the podset fingerprint of the NRT data the cache was prepopulated with for the given
+// node, if any. The fingerprint is empty if the data didn't carry one.
+func (ov *OverReserve) InitialFingerprint(nodeName string) (string, bool) {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	pfp, ok := ov.initialFingerprints[nodeName]
+	return pfp, ok
+}
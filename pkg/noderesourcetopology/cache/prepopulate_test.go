@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+	corev1 "k8s.io/api/core/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestPrepopulate(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	nrtCache.Store().clock = fakeClock
+	// seeding bypasses the trust delay
+	nrtCache.NRTTrustDelay = time.Minute
+
+	expectedFingerprints := map[string]string{
+		"node-a": "pfp0v001aaaaaaaaaaaaaaaa",
+		"node-b": "pfp0v001bbbbbbbbbbbbbbbb",
+		"node-c": "",
+	}
+	var nrts []*topologyv1alpha1.NodeResourceTopology
+	for _, nodeName := range []string{"node-a", "node-b", "node-c"} {
+		nrt := makeTwoZonesTestTopology()
+		nrt.Name = nodeName
+		if pfp := expectedFingerprints[nodeName]; pfp != "" {
+			nrt.Annotations = map[string]string{
+				podfingerprint.Annotation: pfp,
+			}
+		}
+		nrts = append(nrts, nrt)
+	}
+
+	if seeded := nrtCache.Prepopulate(nrts); seeded != len(nrts) {
+		t.Fatalf("seeded %d nodes expected %d", seeded, len(nrts))
+	}
+	fakeClock.Step(10 * time.Second)
+
+	for nodeName, expectedPfp := range expectedFingerprints {
+		nrtCopy, ok := nrtCache.GetCachedNRTCopy(nodeName, &corev1.Pod{})
+		if nrtCopy == nil || !ok {
+			t.Fatalf("missing cached data for node %q", nodeName)
+		}
+		if got := podFingerprintForNodeTopology(nrtCopy); got != expectedPfp {
+			t.Errorf("node %q: cached fingerprint %q expected %q", nodeName, got, expectedPfp)
+		}
+		pfp, ok := nrtCache.InitialFingerprint(nodeName)
+		if !ok {
+			t.Fatalf("node %q: missing initial fingerprint", nodeName)
+		}
+		if pfp != expectedPfp {
+			t.Errorf("node %q: initial fingerprint %q expected %q", nodeName, pfp, expectedPfp)
+		}
+	}
+
+	if _, ok := nrtCache.InitialFingerprint("node-missing"); ok {
+		t.Errorf("unexpected initial fingerprint for a node never seeded")
+	}
+	if got := nrtCache.Store().MaxStaleness(); got != 10*time.Second {
+		t.Errorf("unexpected staleness %v expected %v", got, 10*time.Second)
+	}
+	if dirtyNodes := nrtCache.NodesMaybeOverReserved("testing"); len(dirtyNodes) > 0 {
+		t.Errorf("unexpected dirty nodes after seeding: %v", dirtyNodes)
+	}
+}
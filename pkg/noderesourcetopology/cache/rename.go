@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+// ZoneDelta describes how the availability of a zone changes when a resource is renamed.
+type ZoneDelta struct {
+	Node string `json:"node"`
+	Zone string `json:"zone"`
+	// Before is the available amount of the resource under the old name, net of the reserved pods.
+	Before resource.Quantity `json:"before"`
+	// After is the available amount of the resource under the new name, net of the reserved pods.
+	After resource.Quantity `json:"after"`
+}
+
+// StateDelta describes how the cache accounting changes when a resource is renamed.
+type StateDelta struct {
+	// Zones lists the zones reporting the resource under the old or the new name, sorted by node and zone.
+	Zones []ZoneDelta `json:"zones,omitempty"`
+	// MatchingPods lists by node the keys (namespace + "/" + name) of the reserved pods whose requests
+	// match the new resource name once renamed, sorted.
+	MatchingPods map[string][]string `json:"matchingPods,omitempty"`
+}
+
+// SimulateResourceRename computes how the accounting of the cached nodes would change if the resource oldName
+// was renamed to newName, both in the NRT data and in the requests of the reserved pods, like when a device plugin
+// migrates to a new resource name. If a zone or a pod already reports the new name, the amounts are summed up.
+// The cache is not changed.
+func (ov *OverReserve) SimulateResourceRename(oldName, newName corev1.ResourceName) StateDelta {
+	logID := "rename-" + string(oldName)
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	delta := StateDelta{
+		MatchingPods: make(map[string][]string),
+	}
+	nodeNames := make([]string, 0, len(ov.nrts.data))
+	for nodeName := range ov.nrts.data {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	for _, nodeName := range nodeNames {
+		nrt := ov.nrts.GetNRTCopyByNodeName(nodeName)
+		renamed := nrt.DeepCopy()
+		renameZonesResource(renamed, oldName, newName)
+
+		if nodeAssumedResources, ok := ov.assumedResources[nodeName]; ok {
+			nodeAssumedResources.UpdateNRT(logID, nrt)
+			renamedResources := nodeAssumedResources.Clone()
+			if keys := renamedResources.renameResource(oldName, newName); len(keys) > 0 {
+				delta.MatchingPods[nodeName] = keys
+			}
+			renamedResources.UpdateNRT(logID, renamed)
+		}
+
+		for zi := range nrt.Zones {
+			before, hasBefore := zoneAvailable(nrt.Zones[zi], oldName)
+			after, hasAfter := zoneAvailable(renamed.Zones[zi], newName)
+			if !hasBefore && !hasAfter {
+				continue
+			}
+			delta.Zones = append(delta.Zones, ZoneDelta{
+				Node:   nodeName,
+				Zone:   nrt.Zones[zi].Name,
+				Before: before,
+				After:  after,
+			})
+		}
+	}
+	klog.V(4).InfoS("nrtcache: simulated resource rename", "logID", logID, "newName", newName, "zones", len(delta.Zones), "nodesWithPods", len(delta.MatchingPods))
+	return delta
+}
+
+// renameResource renames the given resource in the requests of the tracked pods. Returns the keys of the pods
+// whose requests were renamed, sorted.
+func (rs *resourceStore) renameResource(oldName, newName corev1.ResourceName) []string {
+	var keys []string
+	for key, res := range rs.data {
+		qty, ok := res[oldName]
+		if !ok {
+			continue
+		}
+		total := res[newName]
+		total.Add(qty)
+		res[newName] = total
+		delete(res, oldName)
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renameZonesResource renames the given resource in all the zones of the NRT object.
+func renameZonesResource(nrt *topologyv1alpha1.NodeResourceTopology, oldName, newName corev1.ResourceName) {
+	for zi := range nrt.Zones {
+		zone := &nrt.Zones[zi] // shortcut
+		oldIdx, newIdx := -1, -1
+		for ri, resInfo := range zone.Resources {
+			switch corev1.ResourceName(resInfo.Name) {
+			case oldName:
+				oldIdx = ri
+			case newName:
+				newIdx = ri
+			}
+		}
+		if oldIdx == -1 {
+			continue
+		}
+		if newIdx == -1 {
+			zone.Resources[oldIdx].Name = string(newName)
+			continue
+		}
+		oldInfo := zone.Resources[oldIdx]
+		newInfo := &zone.Resources[newIdx]
+		newInfo.Capacity.Add(oldInfo.Capacity)
+		newInfo.Allocatable.Add(oldInfo.Allocatable)
+		newInfo.Available.Add(oldInfo.Available)
+		zone.Resources = append(zone.Resources[:oldIdx], zone.Resources[oldIdx+1:]...)
+	}
+}
+
+func zoneAvailable(zone topologyv1alpha1.Zone, resourceName corev1.ResourceName) (resource.Quantity, bool) {
+	for _, resInfo := range zone.Resources {
+		if corev1.ResourceName(resInfo.Name) == resourceName {
+			return resInfo.Available.DeepCopy(), true
+		}
+	}
+	return resource.Quantity{}, false
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSimulateResourceRename(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	nrt := makeTwoZonesTestTopology()
+	nrtCache.Store().Update(nrt)
+
+	makePod := func(name string, res corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "cnt",
+						Resources: corev1.ResourceRequirements{
+							Requests: res,
+						},
+					},
+				},
+			},
+		}
+	}
+	nrtCache.ReserveNodeResources(nrt.Name, makePod("pod-nic", corev1.ResourceList{
+		corev1.ResourceCPU:           resource.MustParse("2"),
+		corev1.ResourceName(nicName): resource.MustParse("2"),
+	}))
+	nrtCache.ReserveNodeResources(nrt.Name, makePod("pod-cpu", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("2"),
+	}))
+
+	newName := corev1.ResourceName("vendor_B.com/nic")
+	delta := nrtCache.SimulateResourceRename(corev1.ResourceName(nicName), newName)
+
+	expectedPods := map[string][]string{
+		nrt.Name: {"ns/pod-nic"},
+	}
+	if !reflect.DeepEqual(delta.MatchingPods, expectedPods) {
+		t.Errorf("unexpected matching pods: %v expected %v", delta.MatchingPods, expectedPods)
+	}
+
+	if len(delta.Zones) != 1 {
+		t.Fatalf("unexpected zones: %v", delta.Zones)
+	}
+	zd := delta.Zones[0]
+	if zd.Node != nrt.Name || zd.Zone != "node-1" {
+		t.Errorf("unexpected zone: %s/%s", zd.Node, zd.Zone)
+	}
+	// the reserved pod now requests the new name, so it is still accounted
+	if zd.Before.Cmp(resource.MustParse("6")) != 0 || zd.After.Cmp(resource.MustParse("6")) != 0 {
+		t.Errorf("unexpected availability: before %s after %s, expected 6 and 6", zd.Before.String(), zd.After.String())
+	}
+
+	// the cache is unchanged
+	got, _ := nrtCache.GetCachedNRTCopy(nrt.Name, &corev1.Pod{})
+	if findResourceInfo(got.Zones[1].Resources, nicName) == nil {
+		t.Errorf("resource %q renamed in the cache", nicName)
+	}
+	if findResourceInfo(got.Zones[1].Resources, string(newName)) != nil {
+		t.Errorf("resource %q added to the cache", newName)
+	}
+	delta = nrtCache.SimulateResourceRename(corev1.ResourceName(nicName), newName)
+	if !reflect.DeepEqual(delta.MatchingPods, expectedPods) {
+		t.Errorf("reserved pods changed by the simulation: %v expected %v", delta.MatchingPods, expectedPods)
+	}
+}
+
+func TestRenameZonesResourceMerge(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+	nrt.Zones[1].Resources = append(nrt.Zones[1].Resources, MakeTopologyResInfo("vendor_B.com/nic", "4", "3"))
+
+	renameZonesResource(nrt, corev1.ResourceName(nicName), "vendor_B.com/nic")
+
+	if findResourceInfo(nrt.Zones[1].Resources, nicName) != nil {
+		t.Errorf("resource %q still reported", nicName)
+	}
+	merged := findResourceInfo(nrt.Zones[1].Resources, "vendor_B.com/nic")
+	if merged == nil {
+		t.Fatalf("renamed resource missing")
+	}
+	if merged.Capacity.Cmp(resource.MustParse("12")) != 0 || merged.Available.Cmp(resource.MustParse("11")) != 0 {
+		t.Errorf("unexpected merged resource: capacity %s available %s", merged.Capacity.String(), merged.Available.String())
+	}
+}
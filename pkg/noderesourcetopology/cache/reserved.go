@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strings"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+// Starting with v1alpha2, the zones can report attributes hinting the amount of resources reserved on the node
+// (e.g. for the system or the kubelet) and not yet reflected in the available resources. The hints are reported
+// as zone attributes named after the resource, like "reserved.cpu", whose value is a resource quantity.
+
+// AttributeReservedPrefix prefixes the names of the zone attributes reporting the reserved amount of a resource.
+const AttributeReservedPrefix = "reserved."
+
+// subtractReservedFromZones decrements the available resources of each zone by the reserved amounts reported
+// in the zone attributes. Malformed attributes and attributes about resources the zone doesn't report are ignored.
+func subtractReservedFromZones(logID string, nrt *topologyv1alpha1.NodeResourceTopology) {
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		zone := &nrt.Zones[zi] // shortcut
+		for _, attr := range zone.Attributes {
+			if !strings.HasPrefix(attr.Name, AttributeReservedPrefix) {
+				continue
+			}
+			resourceName := strings.TrimPrefix(attr.Name, AttributeReservedPrefix)
+			qty, err := resource.ParseQuantity(attr.Value)
+			if err != nil {
+				klog.V(3).InfoS("nrtcache: malformed reserved attribute", "logID", logID, "node", nrt.Name, "zone", zone.Name, "attribute", attr.Name, "value", attr.Value, "error", err)
+				continue
+			}
+			subtractReservedFromZone(logID, nrt.Name, zone, resourceName, qty)
+		}
+	}
+}
+
+func subtractReservedFromZone(logID, nodeName string, zone *topologyv1alpha1.Zone, resourceName string, qty resource.Quantity) {
+	for ri := 0; ri < len(zone.Resources); ri++ {
+		zr := &zone.Resources[ri] // shortcut
+		if zr.Name != resourceName {
+			continue
+		}
+		if zr.Available.Cmp(qty) < 0 {
+			klog.V(3).InfoS("nrtcache: reserved amount exceeds the available resource", "logID", logID, "node", nodeName, "zone", zone.Name, "resource", zr.Name, "available", zr.Available.String(), "reserved", qty.String())
+			zr.Available = resource.Quantity{}
+			return
+		}
+		zr.Available.Sub(qty)
+		klog.V(5).InfoS("nrtcache: accounted reserved resource", "logID", logID, "node", nodeName, "zone", zone.Name, "resource", zr.Name, "reserved", qty.String(), "availableAfter", zr.Available.String())
+		return
+	}
+	klog.V(5).InfoS("nrtcache: reserved attribute for unreported resource", "logID", logID, "node", nodeName, "zone", zone.Name, "resource", resourceName)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// MarkPodTerminating records that the given pod, tracked on the given node, is being deleted. During rolling updates
+// the old pods terminate while the new pods of the same controller are created; see DiscountTerminatingPods.
+// Returns false if the pod is not tracked on the node.
+func (ov *OverReserve) MarkPodTerminating(nodeName string, pod *corev1.Pod) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	nodeAssumedResources, ok := ov.assumedResources[nodeName]
+	if !ok {
+		return false
+	}
+	key := pod.Namespace + "/" + pod.Name
+	if _, ok := nodeAssumedResources.data[key]; !ok {
+		return false
+	}
+	nodeAssumedResources.terminating.Insert(key)
+	if pod.DeletionTimestamp != nil {
+		nodeAssumedResources.deletionDeadlines[key] = pod.DeletionTimestamp.Time
+	}
+	klog.V(5).InfoS("nrtcache: marked terminating", "logID", klog.KObj(pod), "node", nodeName)
+	return true
+}
+
+// discountedTerminatingPods returns the keys of the terminating pods which are replaced by non terminating pods of
+// the same controller, one terminating pod for each replacement, so their resources are not accounted twice.
+// The terminating pods are picked in key order, so the result is stable. Returns an empty set if disabled.
+func (rs *resourceStore) discountedTerminatingPods() sets.String {
+	discounted := sets.NewString()
+	if !rs.discountTerminating || rs.terminating.Len() == 0 {
+		return discounted
+	}
+
+	replacements := make(map[types.UID]int)
+	for key, owner := range rs.owners {
+		if rs.terminating.Has(key) {
+			continue
+		}
+		replacements[owner.UID]++
+	}
+	for _, key := range rs.terminating.List() {
+		owner, ok := rs.owners[key]
+		if !ok || replacements[owner.UID] == 0 {
+			continue
+		}
+		replacements[owner.UID]--
+		discounted.Insert(key)
+		klog.V(5).InfoS("nrtcache: discounting terminating pod", "logID", key, "owner", owner.Name, "ownerUID", owner.UID)
+	}
+	return discounted
+}
+
+// EvictionReleasePolicy sets when the resources of the terminating pods are released, that is no longer accounted.
+type EvictionReleasePolicy string
+
+const (
+	// EvictionReleaseOnDeletion keeps accounting the terminating pods until they are gone. This is the default.
+	EvictionReleaseOnDeletion EvictionReleasePolicy = ""
+	// EvictionReleaseImmediate releases the resources as soon as the pods start terminating.
+	EvictionReleaseImmediate EvictionReleasePolicy = "Immediate"
+	// EvictionReleaseAtGraceExpiry releases the resources once the grace period of the pods expires, that is
+	// past their deletion timestamp. Pods whose deletion timestamp is unknown are accounted until they are gone.
+	EvictionReleaseAtGraceExpiry EvictionReleasePolicy = "AtGraceExpiry"
+)
+
+// releasedTerminatingPods returns the keys of the terminating pods whose resources are released according to the
+// eviction release policy.
+func (rs *resourceStore) releasedTerminatingPods() sets.String {
+	released := sets.NewString()
+	switch rs.evictionRelease {
+	case EvictionReleaseImmediate:
+		released.Insert(rs.terminating.UnsortedList()...)
+	case EvictionReleaseAtGraceExpiry:
+		now := rs.clock.Now()
+		for _, key := range rs.terminating.UnsortedList() {
+			deadline, ok := rs.deletionDeadlines[key]
+			if ok && !now.Before(deadline) {
+				released.Insert(key)
+			}
+		}
+	}
+	if released.Len() > 0 {
+		klog.V(5).InfoS("nrtcache: released terminating pods", "policy", rs.evictionRelease, "pods", released.List())
+	}
+	return released
+}
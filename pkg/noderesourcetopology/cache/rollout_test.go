@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func makeOwnedPod(name string, ownerUID types.UID, cpuQty string) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace1",
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       string(ownerUID),
+					UID:        ownerUID,
+					Controller: &controller,
+				},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse(cpuQty),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDiscountTerminatingPods(t *testing.T) {
+	tests := []struct {
+		name         string
+		discount     bool
+		expectedCPUs string
+	}{
+		{
+			name:         "disabled",
+			expectedCPUs: "10",
+		},
+		{
+			name:         "enabled",
+			discount:     true,
+			expectedCPUs: "14",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeIndex := &fakePodByNodeNameIndex{}
+
+			nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+			nrtCache.DiscountTerminatingPods = tt.discount
+			nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+			oldPod := makeOwnedPod("pod-old", "rs-1", "4")
+			nrtCache.ReserveNodeResources("node", oldPod)
+			// terminating, but not replaced by a pod of the same controller
+			otherPod := makeOwnedPod("pod-other", "rs-2", "2")
+			nrtCache.ReserveNodeResources("node", otherPod)
+
+			if !nrtCache.MarkPodTerminating("node", oldPod) || !nrtCache.MarkPodTerminating("node", otherPod) {
+				t.Fatalf("cannot mark the tracked pods as terminating")
+			}
+			if nrtCache.MarkPodTerminating("node", makeOwnedPod("pod-untracked", "rs-1", "4")) {
+				t.Errorf("marked an untracked pod as terminating")
+			}
+
+			newPod := makeOwnedPod("pod-new", "rs-1", "4")
+			nrtCache.ReserveNodeResources("node", newPod)
+
+			nrt, ok := nrtCache.GetCachedNRTCopy("node", newPod)
+			if !ok || nrt == nil {
+				t.Fatalf("missing cached data")
+			}
+			for _, zone := range nrt.Zones {
+				got := findResourceInfo(zone.Resources, cpu).Available
+				if got.Cmp(resource.MustParse(tt.expectedCPUs)) != 0 {
+					t.Errorf("unexpected cpu availability on zone %s: %s expected %s", zone.Name, got.String(), tt.expectedCPUs)
+				}
+			}
+		})
+	}
+}
+
+func TestEvictionRelease(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		policy       EvictionReleasePolicy
+		elapsed      time.Duration
+		expectedCPUs string
+	}{
+		{
+			name:         "on deletion",
+			policy:       EvictionReleaseOnDeletion,
+			elapsed:      time.Minute,
+			expectedCPUs: "12",
+		},
+		{
+			name:         "immediate",
+			policy:       EvictionReleaseImmediate,
+			expectedCPUs: "16",
+		},
+		{
+			name:         "at grace expiry, grace period running",
+			policy:       EvictionReleaseAtGraceExpiry,
+			elapsed:      20 * time.Second,
+			expectedCPUs: "12",
+		},
+		{
+			name:         "at grace expiry, grace period expired",
+			policy:       EvictionReleaseAtGraceExpiry,
+			elapsed:      30 * time.Second,
+			expectedCPUs: "16",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeIndex := &fakePodByNodeNameIndex{}
+
+			fakeClock := clocktesting.NewFakeClock(now)
+			nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+			nrtCache.clock = fakeClock
+			nrtCache.EvictionRelease = tt.policy
+			nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+			evictedPod := makeOwnedPod("pod-evicted", "rs-1", "4")
+			nrtCache.ReserveNodeResources("node", evictedPod)
+			nrtCache.ReserveNodeResources("node", makeOwnedPod("pod-running", "rs-2", "4"))
+
+			// evicted with a 30 seconds grace period
+			deletionTimestamp := metav1.NewTime(now.Add(30 * time.Second))
+			evictedPod.DeletionTimestamp = &deletionTimestamp
+			if !nrtCache.MarkPodTerminating("node", evictedPod) {
+				t.Fatalf("cannot mark the evicted pod as terminating")
+			}
+			fakeClock.Step(tt.elapsed)
+
+			nrt, ok := nrtCache.GetCachedNRTCopy("node", evictedPod)
+			if !ok || nrt == nil {
+				t.Fatalf("missing cached data")
+			}
+			for _, zone := range nrt.Zones {
+				got := findResourceInfo(zone.Resources, cpu).Available
+				if got.Cmp(resource.MustParse(tt.expectedCPUs)) != 0 {
+					t.Errorf("unexpected cpu availability on zone %s: %s expected %s", zone.Name, got.String(), tt.expectedCPUs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// FeasibleNodesWithSaturationLimit returns the sorted names of the cached nodes having at least one NUMA zone which
+// can fit the whole pod and whose utilization doesn't exceed maxZoneUtil (0-1), so pods can avoid crowding zones already
+// highly utilized. The availability includes the reserved resources. The utilization of a zone is the highest share of
+// the capacity in use among the resources the pod requests. Nodes in passthrough mode or with foreign pods are skipped.
+func (ov *OverReserve) FeasibleNodesWithSaturationLimit(pod *corev1.Pod, maxZoneUtil float64) []string {
+	ov.lock.Lock()
+	nodeNames := make([]string, 0, len(ov.nrts.data))
+	for nodeName := range ov.nrts.data {
+		if ov.nodesWithForeignPods.IsSet(nodeName) || ov.nodesInPassthrough.IsSet(nodeName) {
+			continue
+		}
+		nodeNames = append(nodeNames, nodeName)
+	}
+	ov.lock.Unlock()
+
+	res := util.GetPodEffectiveRequest(pod)
+	var feasible []string
+	for _, nodeName := range nodeNames {
+		nrt, ok := ov.GetCachedNRTCopy(nodeName, pod)
+		if !ok || nrt == nil {
+			continue
+		}
+		if !hasUnsaturatedFittingZone(nrt, res, maxZoneUtil) {
+			klog.V(5).InfoS("nrtcache: no unsaturated zone fits pod", "logID", klog.KObj(pod), "node", nodeName, "maxZoneUtil", maxZoneUtil)
+			continue
+		}
+		feasible = append(feasible, nodeName)
+	}
+	sort.Strings(feasible)
+	return feasible
+}
+
+func hasUnsaturatedFittingZone(nrt *topologyv1alpha1.NodeResourceTopology, res corev1.ResourceList, maxZoneUtil float64) bool {
+	for _, zone := range ZonesOfType(nrt, ZoneTypeNUMANode) {
+		if len(zone.Resources) == 0 || !zoneCanFit(*zone, res) {
+			continue
+		}
+		if zoneUtilization(zone, res) <= maxZoneUtil {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneUtilization returns the highest share of the capacity in use among the given resources reported by the zone.
+func zoneUtilization(zone *topologyv1alpha1.Zone, res corev1.ResourceList) float64 {
+	var highest float64
+	for _, zr := range zone.Resources {
+		if _, ok := res[corev1.ResourceName(zr.Name)]; !ok {
+			continue
+		}
+		capacity := ResourceCapacity(zr)
+		if capacity.IsZero() {
+			continue
+		}
+		used := capacity.DeepCopy()
+		used.Sub(zr.Available)
+		if val := used.AsApproximateFloat64() / capacity.AsApproximateFloat64(); val > highest {
+			highest = val
+		}
+	}
+	return highest
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFeasibleNodesWithSaturationLimit(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+
+	makeNRT := func(nodeName string, cpuAvailable ...string) *topologyv1alpha1.NodeResourceTopology {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: nodeName},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		}
+		for idx, avail := range cpuAvailable {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha1.Zone{
+				Name: fmt.Sprintf("node-%d", idx),
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "40", avail),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+
+	// only the nearly full zone can fit the pod: 38 out of 40 cpus are in use
+	nrtCache.Store().Update(makeNRT("node-saturated", "2", "1"))
+	// the nearly full zone fits, but the other zone fits as well
+	nrtCache.Store().Update(makeNRT("node-mixed", "2", "30"))
+	nrtCache.Store().Update(makeNRT("node-idle", "40", "40"))
+	// no zone can fit the pod at all
+	nrtCache.Store().Update(makeNRT("node-full", "1", "1"))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace1",
+			Name:      "pod1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	got := nrtCache.FeasibleNodesWithSaturationLimit(pod, 0.9)
+	expected := []string{"node-idle", "node-mixed"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected feasible nodes: %v expected %v", got, expected)
+	}
+
+	got = nrtCache.FeasibleNodesWithSaturationLimit(pod, 1)
+	expected = []string{"node-idle", "node-mixed", "node-saturated"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected feasible nodes without limit: %v expected %v", got, expected)
+	}
+
+	// the reservations count towards the utilization
+	nrtCache.ReserveNodeResources("node-idle", &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace1",
+			Name:      "pod-big",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("37"),
+						},
+					},
+				},
+			},
+		},
+	})
+	got = nrtCache.FeasibleNodesWithSaturationLimit(pod, 0.9)
+	expected = []string{"node-mixed"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected feasible nodes after reservation: %v expected %v", got, expected)
+	}
+}
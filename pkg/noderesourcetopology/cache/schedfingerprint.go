@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// SchedulabilityFingerprint returns a hash of the state of the given node relevant for the scheduling decisions:
+// the topology policies, the zones with their attributes and the capacity and the availability of their resources,
+// net of the resources reserved for the pods assumed on the node. The object metadata, like the annotations, is not
+// part of the state. Callers can compare the fingerprints to detect if the node changed. Returns zero for unknown nodes.
+func (ov *OverReserve) SchedulabilityFingerprint(nodeName string) uint64 {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	nrt := ov.nrts.GetNRTCopyByNodeName(nodeName)
+	if nrt == nil {
+		return 0
+	}
+	if nodeAssumedResources, ok := ov.assumedResources[nodeName]; ok {
+		nodeAssumedResources.UpdateNRT(nodeName, nrt)
+	}
+	return schedulabilityFingerprint(nrt)
+}
+
+// schedulabilityFingerprint hashes the scheduling relevant state of the given NRT object. The zones and their resources
+// are hashed in name order, so the fingerprint doesn't depend on the order the agent reports them.
+func schedulabilityFingerprint(nrt *topologyv1alpha1.NodeResourceTopology) uint64 {
+	h := fnv.New64a()
+	for _, policy := range nrt.TopologyPolicies {
+		fmt.Fprintf(h, "policy=%s\n", policy)
+	}
+
+	zones := make([]*topologyv1alpha1.Zone, 0, len(nrt.Zones))
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		zones = append(zones, &nrt.Zones[zi])
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+
+	for _, zone := range zones {
+		fmt.Fprintf(h, "zone=%s type=%s parent=%s\n", zone.Name, zone.Type, zone.Parent)
+		for _, attr := range zone.Attributes {
+			fmt.Fprintf(h, "attribute=%s value=%s\n", attr.Name, attr.Value)
+		}
+		resources := make([]topologyv1alpha1.ResourceInfo, len(zone.Resources))
+		copy(resources, zone.Resources)
+		sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+		for _, zr := range resources {
+			fmt.Fprintf(h, "resource=%s capacity=%s available=%s\n", zr.Name, zr.Capacity.String(), zr.Available.String())
+		}
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// Nodes can run both pods with exclusive (pinned) CPUs and pods running on the shared CPU pool. The zones can report
+// the size of their shared CPU pool using the AttributeSharedCPUPool attribute. The shared pool is not available to
+// the exclusive CPUs pods, while the shared CPUs pods draw from the shared pool, and reduce the CPUs available to the
+// exclusive CPUs pods only once they exceed the pool size. Zones not reporting the attribute have no shared pool,
+// so all the pods reduce the available CPUs.
+
+// AttributeSharedCPUPool names the zone attribute reporting the size of the shared CPU pool of the zone, as CPU quantity.
+const AttributeSharedCPUPool = "shared-cpu-pool"
+
+// IsSharedCPUPod returns true if the pod runs on the shared CPU pool, because it can't get exclusive CPUs:
+// only the Guaranteed pods requesting whole CPUs do.
+func IsSharedCPUPod(pod *corev1.Pod) bool {
+	if v1qos.GetPodQOS(pod) != corev1.PodQOSGuaranteed {
+		return true
+	}
+	cpus := util.GetPodEffectiveRequest(pod)[corev1.ResourceCPU]
+	return cpus.MilliValue()%1000 != 0
+}
+
+// sharedCPUPoolOfZone returns the size of the shared CPU pool of the zone, if the zone reports a well formed one.
+func sharedCPUPoolOfZone(zone topologyv1alpha1.Zone) (resource.Quantity, bool) {
+	for _, attr := range zone.Attributes {
+		if attr.Name != AttributeSharedCPUPool {
+			continue
+		}
+		qty, err := resource.ParseQuantity(attr.Value)
+		if err != nil {
+			klog.V(3).InfoS("nrtcache: malformed shared CPU pool attribute", "zone", zone.Name, "value", attr.Value, "error", err)
+			return resource.Quantity{}, false
+		}
+		return qty, true
+	}
+	return resource.Quantity{}, false
+}
+
+// subtractSharedCPUPools decrements the available CPUs of each zone by the size of its shared CPU pool,
+// so the available CPUs are the ones the exclusive CPUs pods can get.
+func subtractSharedCPUPools(logID string, nrt *topologyv1alpha1.NodeResourceTopology) {
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		zone := &nrt.Zones[zi] // shortcut
+		pool, ok := sharedCPUPoolOfZone(*zone)
+		if !ok {
+			continue
+		}
+		subtractReservedFromZone(logID, nrt.Name, zone, string(corev1.ResourceCPU), pool)
+	}
+}
+
+// subtractSharedCPUPoolsExcess decrements the available CPUs of each zone with a shared CPU pool by the amount
+// of CPUs requested by the shared CPUs pods exceeding the pool size. The shared CPUs pods are pessimistically
+// accounted on all the zones, like any other pod.
+func subtractSharedCPUPoolsExcess(logID, nodeName string, zones []*topologyv1alpha1.Zone, sharedCPUs resource.Quantity) {
+	for _, zone := range zones {
+		pool, ok := sharedCPUPoolOfZone(*zone)
+		if !ok {
+			continue
+		}
+		excess := sharedCPUs.DeepCopy()
+		excess.Sub(pool)
+		if excess.Sign() <= 0 {
+			klog.V(6).InfoS("nrtcache: shared CPU pods fit the pool", "logID", logID, "node", nodeName, "zone", zone.Name, "pool", pool.String(), "requested", sharedCPUs.String())
+			continue
+		}
+		subtractReservedFromZone(logID, nodeName, zone, string(corev1.ResourceCPU), excess)
+	}
+}
+
+// zonesWithSharedCPUPool splits the given zones between the ones with a shared CPU pool and the others.
+func zonesWithSharedCPUPool(zones []*topologyv1alpha1.Zone) ([]*topologyv1alpha1.Zone, []*topologyv1alpha1.Zone) {
+	var withPool, withoutPool []*topologyv1alpha1.Zone
+	for _, zone := range zones {
+		if _, ok := sharedCPUPoolOfZone(*zone); ok {
+			withPool = append(withPool, zone)
+		} else {
+			withoutPool = append(withoutPool, zone)
+		}
+	}
+	return withPool, withoutPool
+}
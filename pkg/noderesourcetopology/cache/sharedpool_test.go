@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsSharedCPUPod(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected bool
+	}{
+		{
+			name:     "burstable",
+			pod:      makeSharedPoolTestPod("burstable", "2", "4Gi", false),
+			expected: true,
+		},
+		{
+			name:     "guaranteed whole CPUs",
+			pod:      makeSharedPoolTestPod("whole", "2", "4Gi", true),
+			expected: false,
+		},
+		{
+			name:     "guaranteed fractional CPUs",
+			pod:      makeSharedPoolTestPod("fractional", "1500m", "4Gi", true),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSharedCPUPod(tt.pod); got != tt.expected {
+				t.Errorf("got %v expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetCachedNRTCopySharedCPUPool(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+
+	nrt := makeTwoZonesTestTopology()
+	for zi := range nrt.Zones {
+		nrt.Zones[zi].Attributes = topologyv1alpha1.AttributeList{
+			{Name: AttributeSharedCPUPool, Value: "4"},
+		}
+	}
+	nrtCache.Store().Update(nrt)
+
+	checkAvailable := func(step, resourceName, expected string) {
+		t.Helper()
+		got, _ := nrtCache.GetCachedNRTCopy(nrt.Name, &corev1.Pod{})
+		for _, zone := range got.Zones {
+			qty := findResourceInfo(zone.Resources, resourceName).Available
+			if qty.Cmp(resource.MustParse(expected)) != 0 {
+				t.Errorf("%s: zone %s: unexpected available %s %s expected %s", step, zone.Name, resourceName, qty.String(), expected)
+			}
+		}
+	}
+
+	// the shared pool is never available to the exclusive CPUs pods
+	checkAvailable("empty", cpu, "16")
+
+	nrtCache.ReserveNodeResources(nrt.Name, makeSharedPoolTestPod("shared-1", "1", "1Gi", false))
+	nrtCache.ReserveNodeResources(nrt.Name, makeSharedPoolTestPod("shared-2", "2", "1Gi", false))
+	checkAvailable("shared pods within the pool", cpu, "16")
+	// only the CPUs are drawn from the shared pool
+	checkAvailable("shared pods within the pool", memory, "30Gi")
+
+	nrtCache.ReserveNodeResources(nrt.Name, makeSharedPoolTestPod("shared-3", "3", "1Gi", false))
+	checkAvailable("shared pods exceeding the pool", cpu, "14")
+
+	nrtCache.ReserveNodeResources(nrt.Name, makeSharedPoolTestPod("exclusive-1", "4", "1Gi", true))
+	checkAvailable("exclusive pod", cpu, "10")
+
+	nrtCache.UnreserveNodeResources(nrt.Name, makeSharedPoolTestPod("shared-3", "3", "1Gi", false))
+	checkAvailable("exclusive pod and shared pods within the pool", cpu, "12")
+}
+
+func makeSharedPoolTestPod(name, cpuQty, memoryQty string, guaranteed bool) *corev1.Pod {
+	res := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpuQty),
+		corev1.ResourceMemory: resource.MustParse(memoryQty),
+	}
+	reqs := corev1.ResourceRequirements{
+		Requests: res,
+	}
+	if guaranteed {
+		reqs.Limits = res.DeepCopy()
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:      "cnt",
+					Resources: reqs,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// StateSnapshot is a point-in-time copy of the cache state. It can be serialized, so a known good state
+// can be stored as a fixture and compared against the cache later on, e.g. in regression tests.
+type StateSnapshot struct {
+	// NodeTopologies are the cached NRT data, by node name, without the reservations applied.
+	NodeTopologies map[string]*topologyv1alpha1.NodeResourceTopology `json:"nodeTopologies,omitempty"`
+	// Reservations are the resources reserved on each node, by pod key (namespace + "/" + name).
+	Reservations map[string]map[string]corev1.ResourceList `json:"reservations,omitempty"`
+	// MaybeOverReserved are the names of the nodes which may be overreserved, sorted.
+	MaybeOverReserved []string `json:"maybeOverReserved,omitempty"`
+	// WithForeignPods are the names of the nodes running foreign pods, sorted.
+	WithForeignPods []string `json:"withForeignPods,omitempty"`
+	// InPassthrough are the names of the nodes in passthrough mode, sorted.
+	InPassthrough []string `json:"inPassthrough,omitempty"`
+}
+
+// Snapshot returns a copy of the current cache state.
+func (ov *OverReserve) Snapshot() StateSnapshot {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	return ov.snapshot()
+}
+
+// StateDump is the cache state serialized by Dump. Besides the state snapshot, it reports how many times
+// the nodes running foreign pods were marked since their last resync.
+type StateDump struct {
+	StateSnapshot
+	// ForeignPods maps the names of the nodes running foreign pods to the times they were marked.
+	ForeignPods map[string]int `json:"foreignPods,omitempty"`
+}
+
+// Dump returns the JSON serialization of a consistent snapshot of the current cache state, for live debugging,
+// e.g. from an admin tool or a debug handler. The map keys are serialized sorted, so the output is stable.
+func (ov *OverReserve) Dump() ([]byte, error) {
+	ov.lock.Lock()
+	dump := StateDump{
+		StateSnapshot: ov.snapshot(),
+		ForeignPods:   ov.nodesWithForeignPods.Clone(),
+	}
+	ov.lock.Unlock()
+	return json.Marshal(dump)
+}
+
+// snapshot returns a copy of the current cache state. Must be called with the lock held.
+func (ov *OverReserve) snapshot() StateSnapshot {
+	snap := StateSnapshot{
+		NodeTopologies:    make(map[string]*topologyv1alpha1.NodeResourceTopology, len(ov.nrts.data)),
+		Reservations:      make(map[string]map[string]corev1.ResourceList, len(ov.assumedResources)),
+		MaybeOverReserved: sortedKeys(ov.nodesMaybeOverreserved),
+		WithForeignPods:   sortedKeys(ov.nodesWithForeignPods),
+		InPassthrough:     sortedKeys(ov.nodesInPassthrough),
+	}
+	for nodeName, nrt := range ov.nrts.data {
+		snap.NodeTopologies[nodeName] = nrt.DeepCopy()
+	}
+	for nodeName, rs := range ov.assumedResources {
+		reservations := make(map[string]corev1.ResourceList, len(rs.data)+rs.requestless.Len())
+		for key, res := range rs.data {
+			reservations[key] = res.DeepCopy()
+		}
+		for _, key := range rs.requestless.UnsortedList() {
+			reservations[key] = corev1.ResourceList{}
+		}
+		snap.Reservations[nodeName] = reservations
+	}
+	return snap
+}
+
+// AssertMatches compares the current cache state with the given golden state, and returns one error
+// for each discrepancy found. Returns no errors if the states match.
+func (ov *OverReserve) AssertMatches(golden StateSnapshot) []error {
+	return compareSnapshots(golden, ov.Snapshot())
+}
+
+func compareSnapshots(golden, current StateSnapshot) []error {
+	var errs []error
+	for _, nodeName := range sets.StringKeySet(golden.NodeTopologies).Union(sets.StringKeySet(current.NodeTopologies)).List() {
+		expected, expOk := golden.NodeTopologies[nodeName]
+		got, gotOk := current.NodeTopologies[nodeName]
+		switch {
+		case !gotOk:
+			errs = append(errs, fmt.Errorf("node %q: missing NodeTopology", nodeName))
+		case !expOk:
+			errs = append(errs, fmt.Errorf("node %q: unexpected NodeTopology", nodeName))
+		case !apiequality.Semantic.DeepEqual(expected, got):
+			errs = append(errs, fmt.Errorf("node %q: NodeTopology differs", nodeName))
+		}
+	}
+	for _, nodeName := range sets.StringKeySet(golden.Reservations).Union(sets.StringKeySet(current.Reservations)).List() {
+		expected := golden.Reservations[nodeName]
+		got := current.Reservations[nodeName]
+		for _, key := range sets.StringKeySet(expected).Union(sets.StringKeySet(got)).List() {
+			expRes, expOk := expected[key]
+			gotRes, gotOk := got[key]
+			switch {
+			case !gotOk:
+				errs = append(errs, fmt.Errorf("node %q: missing reservation for pod %q", nodeName, key))
+			case !expOk:
+				errs = append(errs, fmt.Errorf("node %q: unexpected reservation for pod %q", nodeName, key))
+			case !apiequality.Semantic.DeepEqual(expRes, gotRes):
+				errs = append(errs, fmt.Errorf("node %q: reservation for pod %q differs", nodeName, key))
+			}
+		}
+	}
+	errs = append(errs, compareNodeSets("maybe overreserved", golden.MaybeOverReserved, current.MaybeOverReserved)...)
+	errs = append(errs, compareNodeSets("with foreign pods", golden.WithForeignPods, current.WithForeignPods)...)
+	errs = append(errs, compareNodeSets("in passthrough", golden.InPassthrough, current.InPassthrough)...)
+	return errs
+}
+
+func compareNodeSets(desc string, golden, current []string) []error {
+	expected := sets.NewString(golden...)
+	got := sets.NewString(current...)
+	var errs []error
+	for _, nodeName := range expected.Difference(got).List() {
+		errs = append(errs, fmt.Errorf("node %q: expected %s", nodeName, desc))
+	}
+	for _, nodeName := range got.Difference(expected).List() {
+		errs = append(errs, fmt.Errorf("node %q: unexpected %s", nodeName, desc))
+	}
+	return errs
+}
+
+func sortedKeys(cnt counter) []string {
+	keys := cnt.Keys()
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// SeedStartupPods accounts for the pods already running on the given node when the scheduler starts.
+// The NRT data reported by the node already reflects these pods, so adding all of them to the reserved resources
+// would subtract their resources twice. If the podset fingerprint of the cached NRT data matches the pods known
+// to be on the node, the NRT data fully accounts for them, so nothing is seeded. Otherwise, only the pods managed
+// by the registered scheduler profiles are seeded, while the DaemonSet pods, the static pods and the pods bound by
+// other schedulers are left to the NRT data; the node is marked for resync, so the reservations are dropped once the
+// fingerprint matches. Returns how many pods were seeded.
+func (ov *OverReserve) SeedStartupPods(logID, nodeName string, pods []*corev1.Pod) int {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	nrt := ov.nrts.GetNRTReadOnly(nodeName)
+	if nrt == nil {
+		klog.V(5).InfoS("nrtcache: startup: missing NodeTopology", "logID", logID, "node", nodeName)
+		return 0
+	}
+	if pfpExpected := podFingerprintForNodeTopology(nrt); pfpExpected != "" {
+		if err := checkPodFingerprintForNode(logID, ov.nodeIndexer, nodeName, pfpExpected); err == nil {
+			klog.V(4).InfoS("nrtcache: startup: NodeTopology accounts for all the pods", "logID", logID, "node", nodeName)
+			return 0
+		}
+	}
+
+	seeded := 0
+	for _, pod := range pods {
+		if !isStartupPodManaged(pod) {
+			continue
+		}
+		nodeAssumedResources, ok := ov.assumedResources[nodeName]
+		if !ok {
+			nodeAssumedResources = ov.newNodeResourceStore()
+			ov.assumedResources[nodeName] = nodeAssumedResources
+		}
+		nodeAssumedResources.AddPod(pod)
+		seeded++
+		klog.V(5).InfoS("nrtcache: startup: seeded pod", "logID", klog.KObj(pod), "node", nodeName)
+	}
+	if seeded > 0 {
+		ov.nodesMaybeOverreserved.Incr(nodeName)
+	}
+	klog.V(4).InfoS("nrtcache: startup: seeded pods", "logID", logID, "node", nodeName, "seeded", seeded, "total", len(pods))
+	return seeded
+}
+
+// isStartupPodManaged returns true if the pod is managed by the registered scheduler profiles, so its reservation
+// is tracked by the cache. The pods placed on the node without scheduling, like the DaemonSet and the static pods,
+// are never tracked.
+func isStartupPodManaged(pod *corev1.Pod) bool {
+	if IsForeignPod(pod) {
+		return false
+	}
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
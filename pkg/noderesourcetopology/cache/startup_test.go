@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSeedStartupPods(t *testing.T) {
+	profileName := "test-scheduler"
+	RegisterSchedulerProfileName(profileName)
+	defer CleanRegisteredSchedulerProfileNames()
+
+	dsPod := makeOwnedPod("pod-ds", types.UID("ds-1"), "2")
+	dsPod.OwnerReferences[0].Kind = "DaemonSet"
+	dsPod.Spec.SchedulerName = profileName
+
+	staticPod := makeOwnedPod("pod-static", types.UID("node"), "2")
+	staticPod.OwnerReferences[0].Kind = "Node"
+	staticPod.Annotations = map[string]string{
+		corev1.MirrorPodAnnotationKey: "mirror",
+	}
+
+	foreignPod := makeOwnedPod("pod-foreign", types.UID("rs-1"), "2")
+	foreignPod.Spec.SchedulerName = "other-scheduler"
+
+	managedPod := makeOwnedPod("pod-managed", types.UID("rs-2"), "4")
+	managedPod.Spec.SchedulerName = profileName
+
+	pods := []*corev1.Pod{dsPod, staticPod, foreignPod, managedPod}
+	fp := podfingerprint.NewFingerprint(len(pods))
+	for _, pod := range pods {
+		pod.Spec.NodeName = "node"
+		fp.Add(pod.Namespace, pod.Name)
+	}
+
+	tests := []struct {
+		name           string
+		fingerprint    string
+		expectedSeeded int
+		expectedCPUs   string
+		expectedDirty  bool
+	}{
+		{
+			name:           "matching fingerprint",
+			fingerprint:    fp.Sign(),
+			expectedSeeded: 0,
+			expectedCPUs:   "20",
+		},
+		{
+			name:           "mismatching fingerprint",
+			fingerprint:    "pfp0v001ffffffffffffffff",
+			expectedSeeded: 1,
+			expectedCPUs:   "16",
+			expectedDirty:  true,
+		},
+		{
+			name:           "missing fingerprint",
+			expectedSeeded: 1,
+			expectedCPUs:   "16",
+			expectedDirty:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeIndex := &fakePodByNodeNameIndex{}
+			for _, pod := range pods {
+				fakeIndex.Add(pod)
+			}
+
+			// the NRT data reported by the node already accounts for the running pods
+			nrt := makeTwoZonesTestTopology()
+			if tt.fingerprint != "" {
+				nrt.Annotations = map[string]string{
+					podfingerprint.Annotation: tt.fingerprint,
+				}
+			}
+			nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+			nrtCache.Store().Update(nrt)
+
+			seeded := nrtCache.SeedStartupPods("testing", "node", pods)
+			if seeded != tt.expectedSeeded {
+				t.Errorf("seeded %d pods expected %d", seeded, tt.expectedSeeded)
+			}
+
+			nrtCopy, _ := nrtCache.GetCachedNRTCopy("node", &corev1.Pod{})
+			if nrtCopy == nil {
+				t.Fatalf("missing cached data")
+			}
+			for _, zone := range nrtCopy.Zones {
+				got := findResourceInfo(zone.Resources, cpu).Available
+				if got.Cmp(resource.MustParse(tt.expectedCPUs)) != 0 {
+					t.Errorf("unexpected cpu availability on zone %s: %s expected %s", zone.Name, got.String(), tt.expectedCPUs)
+				}
+			}
+
+			dirty := len(nrtCache.NodesMaybeOverReserved("testing")) > 0
+			if dirty != tt.expectedDirty {
+				t.Errorf("node dirty=%v expected %v", dirty, tt.expectedDirty)
+			}
+		})
+	}
+}
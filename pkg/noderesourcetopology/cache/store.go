@@ -17,11 +17,20 @@ limitations under the License.
 package cache
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 
@@ -31,21 +40,33 @@ import (
 	"github.com/k8stopologyawareschedwg/podfingerprint"
 )
 
-// nrtStore maps the NRT data by node name. It is not thread safe and needs to be protected by a lock.
+// nrtStore maps the NRT data by node name. It is not thread safe and needs to be protected by a lock: OverReserve.lock.
 // data is intentionally copied each time it enters and exists the store. E.g, no pointer sharing.
 type nrtStore struct {
 	data map[string]*topologyv1alpha1.NodeResourceTopology
+	// lastUpdated tracks when the data of each node entered the store, to measure its staleness
+	lastUpdated map[string]time.Time
+	// seenResourceVersions tracks the newest resourceVersion seen for each node, to detect the stale updates
+	seenResourceVersions map[string]string
+	clock                clock.PassiveClock
 }
 
 // newNrtStore creates a new nrtStore and initializes it with copies of the provided Node Resource Topology data.
 func newNrtStore(nrts []*topologyv1alpha1.NodeResourceTopology) *nrtStore {
+	clk := clock.RealClock{}
+	now := clk.Now()
 	data := make(map[string]*topologyv1alpha1.NodeResourceTopology, len(nrts))
+	lastUpdated := make(map[string]time.Time, len(nrts))
 	for _, nrt := range nrts {
 		data[nrt.Name] = nrt.DeepCopy()
+		lastUpdated[nrt.Name] = now
 	}
 	klog.V(6).InfoS("nrtcache: initialized nrtStore", "objects", len(data))
 	return &nrtStore{
-		data: data,
+		data:                 data,
+		lastUpdated:          lastUpdated,
+		seenResourceVersions: make(map[string]string),
+		clock:                clk,
 	}
 }
 
@@ -60,26 +81,162 @@ func (nrs *nrtStore) GetNRTCopyByNodeName(nodeName string) *topologyv1alpha1.Nod
 	obj, ok := nrs.data[nodeName]
 	if !ok {
 		klog.V(3).InfoS("nrtcache: missing cached NodeTopology", "node", nodeName)
+		nrtLookups.WithLabelValues(lookupResultMiss).Inc()
 		return nil
 	}
+	nrtLookups.WithLabelValues(lookupResultHit).Inc()
 	return obj.DeepCopy()
 }
 
+// GetNRTReadOnly returns the stored Node Resource Topology data for the given node, or nil if no data is associated
+// to that node. Unlike GetNRTCopyByNodeName, this function does not copy the data, and returns a pointer shared with
+// the store which reflects the later Updates. Callers must NOT mutate the returned object, and must hold the lock
+// protecting the store, which is OverReserve.lock, for as long as they use it. Meant for hot read paths which only
+// inspect the values, like OverReserve.checkZonesCapacity.
+func (nrs *nrtStore) GetNRTReadOnly(nodeName string) *topologyv1alpha1.NodeResourceTopology {
+	obj, ok := nrs.data[nodeName]
+	if !ok {
+		klog.V(3).InfoS("nrtcache: missing cached NodeTopology", "node", nodeName)
+		return nil
+	}
+	return obj
+}
+
 // Update adds or replace the Node Resource Topology associated to a node. Always do a copy.
+// Existing objects are updated in place, so pointers obtained by GetNRTReadOnly see the new data.
+// Updates carrying the same data already stored only refresh the update time, unless the NRT object was recreated. Updates older than the stored
+// data, per their resourceVersion, are dropped, so events delivered out of order can't regress the cache.
 func (nrs *nrtStore) Update(nrt *topologyv1alpha1.NodeResourceTopology) {
-	nrs.data[nrt.Name] = nrt.DeepCopy()
+	if obj, ok := nrs.data[nrt.Name]; ok {
+		latestRV := nrs.latestResourceVersion(obj)
+		if isStaleResourceVersion(latestRV, nrt.ResourceVersion) {
+			klog.V(4).InfoS("nrtcache: ignoring stale NodeTopology", "node", nrt.Name, "resourceVersion", nrt.ResourceVersion, "latestResourceVersion", latestRV)
+			return
+		}
+		if NRTEqualIgnoringStatus(obj, nrt) && !nrtRecreated(obj, nrt) {
+			klog.V(6).InfoS("nrtcache: unchanged NodeTopology", "node", nrt.Name)
+		} else {
+			nrt.DeepCopyInto(obj)
+		}
+	} else {
+		nrs.data[nrt.Name] = nrt.DeepCopy()
+	}
+	nrs.lastUpdated[nrt.Name] = nrs.clock.Now()
+	nrs.seenResourceVersions[nrt.Name] = nrt.ResourceVersion
+	nrs.UpdateStalenessMetric()
 	klog.V(5).InfoS("nrtcache: updated cached NodeTopology", "node", nrt.Name)
 }
 
+// latestResourceVersion returns the newest resourceVersion seen for the node of the given stored object. Updates carrying
+// the same data don't replace the stored object, so its own resourceVersion may be older.
+func (nrs *nrtStore) latestResourceVersion(obj *topologyv1alpha1.NodeResourceTopology) string {
+	if rv, ok := nrs.seenResourceVersions[obj.Name]; ok {
+		return rv
+	}
+	return obj.ResourceVersion
+}
+
+// isStaleResourceVersion returns true if the incoming resourceVersion is older than the stored one. The resourceVersions
+// are opaque, but in practice they are increasing integers; anything which can't be compared as such is never stale.
+func isStaleResourceVersion(stored, incoming string) bool {
+	storedRV, err := strconv.ParseUint(stored, 10, 64)
+	if err != nil {
+		return false
+	}
+	incomingRV, err := strconv.ParseUint(incoming, 10, 64)
+	if err != nil {
+		return false
+	}
+	return incomingRV < storedRV
+}
+
+// NRTEqualIgnoringStatus returns true if the given Node Resource Topology objects carry the same data: name,
+// annotations (which include the podset fingerprint), topology policies and zones. The bookkeeping metadata,
+// like the resourceVersion or the managed fields, is ignored, so objects which are just re-sent compare equal.
+func NRTEqualIgnoringStatus(a, b *topologyv1alpha1.NodeResourceTopology) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name &&
+		apiequality.Semantic.DeepEqual(a.Annotations, b.Annotations) &&
+		apiequality.Semantic.DeepEqual(a.TopologyPolicies, b.TopologyPolicies) &&
+		apiequality.Semantic.DeepEqual(a.Zones, b.Zones)
+}
+
+// Delete removes the Node Resource Topology associated to a node, if any.
+func (nrs *nrtStore) Delete(nodeName string) {
+	delete(nrs.data, nodeName)
+	delete(nrs.lastUpdated, nodeName)
+	delete(nrs.seenResourceVersions, nodeName)
+	klog.V(5).InfoS("nrtcache: deleted cached NodeTopology", "node", nodeName)
+}
+
+// MaxStaleness returns the longest time since the data of a node was last updated, across all the stored nodes.
+func (nrs *nrtStore) MaxStaleness() time.Duration {
+	now := nrs.clock.Now()
+	var maxAge time.Duration
+	for _, ts := range nrs.lastUpdated {
+		if age := now.Sub(ts); age > maxAge {
+			maxAge = age
+		}
+	}
+	return maxAge
+}
+
+// UpdateStalenessMetric refreshes the staleness metric with the current data. The staleness grows even without
+// updates, so this should be called periodically besides on updates.
+func (nrs *nrtStore) UpdateStalenessMetric() {
+	nrtMaxStaleness.Set(nrs.MaxStaleness().Seconds())
+}
+
 // resourceStore maps the resource requested by pod by pod namespaed name. It is not thread safe and needs to be protected by a lock.
 type resourceStore struct {
 	// key: namespace + "/" name
 	data map[string]corev1.ResourceList
+	// exclusive holds the keys of the pods requesting an exclusive NUMA zone. See IsExclusiveZonePod.
+	exclusive sets.String
+	// requestless holds the keys of the pods requesting no resources (e.g. BestEffort pods). They don't change
+	// the NUMA availability, so there's nothing to account, but they are still tracked as present.
+	requestless sets.String
+	// sharedCPU holds the keys of the pods running on the shared CPU pool. See IsSharedCPUPod.
+	sharedCPU sets.String
+	// ignored holds the names of the resources which are not NUMA-local, thus never subtracted from the zones.
+	ignored sets.String
+	// labels holds the labels of the pods with requests, to group them by workload. See WorkloadEfficiencyReport.
+	labels map[string]map[string]string
+	// owners holds the reference to the controller owning the pods with requests, if any. See discountedTerminatingPods.
+	owners map[string]metav1.OwnerReference
+	// zones holds the names of the NUMA zones the pods with requests are expected to be allocated from, when known.
+	// See SetPodZones.
+	zones map[string][]string
+	// targetZones holds the names of the NUMA zones the pods with requests are charged to, when the zones were known
+	// at reserve time. The other pods are charged to all the NUMA zones. See setPodTargetZones.
+	targetZones map[string][]string
+	// terminating holds the keys of the pods being deleted.
+	terminating sets.String
+	// discountedResources, if not empty, limits the accounting to the named resources. See undiscountedResources.
+	discountedResources sets.String
+	// deletionDeadlines holds the time the terminating pods are deleted at, when known. See releasedTerminatingPods.
+	deletionDeadlines map[string]time.Time
+	evictionRelease   EvictionReleasePolicy
+	clock             clock.PassiveClock
+	// discountTerminating enables the discount of the terminating pods replaced by pods of the same owner.
+	discountTerminating bool
 }
 
 func newResourceStore() *resourceStore {
 	return &resourceStore{
-		data: make(map[string]corev1.ResourceList),
+		data:              make(map[string]corev1.ResourceList),
+		exclusive:         sets.NewString(),
+		requestless:       sets.NewString(),
+		sharedCPU:         sets.NewString(),
+		labels:            make(map[string]map[string]string),
+		owners:            make(map[string]metav1.OwnerReference),
+		zones:             make(map[string][]string),
+		targetZones:       make(map[string][]string),
+		terminating:       sets.NewString(),
+		deletionDeadlines: make(map[string]time.Time),
+		clock:             clock.RealClock{},
 	}
 }
 
@@ -91,67 +248,555 @@ func (rs *resourceStore) String() string {
 	return sb.String()
 }
 
-// AddPod returns true if updating existing pod, false if adding for the first time
+// AddPod returns true if updating existing pod, false if adding for the first time.
+// Pods requesting no resources are tracked as present, but their (empty) resources are not accounted.
 func (rs *resourceStore) AddPod(pod *corev1.Pod) bool {
 	key := pod.Namespace + "/" + pod.Name // this is also a valid logID
-	_, ok := rs.data[key]
+	ok := rs.Contains(key)
 	if ok {
 		// should not happen, so we log with a low level
 		klog.V(4).InfoS("updating existing entry", "key", key)
 	}
 	resData := util.GetPodEffectiveRequest(pod)
+	if isEmptyRequest(resData) {
+		klog.V(5).InfoS("nrtcache: resourcestore ADD without requests", "logID", key)
+		delete(rs.data, key)
+		delete(rs.labels, key)
+		delete(rs.owners, key)
+		delete(rs.zones, key)
+		delete(rs.targetZones, key)
+		rs.exclusive.Delete(key)
+		rs.sharedCPU.Delete(key)
+		rs.terminating.Delete(key)
+		delete(rs.deletionDeadlines, key)
+		rs.requestless.Insert(key)
+		return ok
+	}
+	rs.requestless.Delete(key)
 	klog.V(5).InfoS("nrtcache: resourcestore ADD", stringify.ResourceListToLoggable(key, resData)...)
 	rs.data[key] = resData
+	rs.labels[key] = pod.Labels
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		rs.owners[key] = *owner
+	} else {
+		delete(rs.owners, key)
+	}
+	if pod.DeletionTimestamp != nil {
+		rs.terminating.Insert(key)
+		rs.deletionDeadlines[key] = pod.DeletionTimestamp.Time
+	} else {
+		rs.terminating.Delete(key)
+		delete(rs.deletionDeadlines, key)
+	}
+	if IsExclusiveZonePod(pod) {
+		rs.exclusive.Insert(key)
+	} else {
+		rs.exclusive.Delete(key)
+	}
+	if IsSharedCPUPod(pod) {
+		rs.sharedCPU.Insert(key)
+	} else {
+		rs.sharedCPU.Delete(key)
+	}
 	return ok
 }
 
 // DeletePod returns true if deleted an existing pod, false otherwise
 func (rs *resourceStore) DeletePod(pod *corev1.Pod) bool {
-	key := pod.Namespace + "/" + pod.Name // this is also a valid logID
+	return rs.deleteKey(pod.Namespace + "/" + pod.Name) // this is also a valid logID
+}
+
+// Contains returns true if the pod identified by the given key (namespace + "/" + name) is tracked
+func (rs *resourceStore) Contains(key string) bool {
 	_, ok := rs.data[key]
-	if ok {
+	return ok || rs.requestless.Has(key)
+}
+
+// PodsInZone returns the pods expected to be allocated from the given NUMA zone, sorted by namespace and name.
+// Only the pods whose zones were recorded with SetPodZones are known.
+func (rs *resourceStore) PodsInZone(zone string) []types.NamespacedName {
+	var keys []string
+	for key, zoneNames := range rs.zones {
+		for _, zoneName := range zoneNames {
+			if zoneName == zone {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	pods := make([]types.NamespacedName, 0, len(keys))
+	for _, key := range keys {
+		namespace, name, _ := strings.Cut(key, "/")
+		pods = append(pods, types.NamespacedName{Namespace: namespace, Name: name})
+	}
+	return pods
+}
+
+func (rs *resourceStore) deleteKey(key string) bool {
+	if rs.requestless.Has(key) {
+		klog.V(5).InfoS("nrtcache: resourcestore DEL without requests", "logID", key)
+		rs.requestless.Delete(key)
+		return true
+	}
+	_, ok := rs.data[key]
+	if !ok {
 		// should not happen, so we log with a low level
 		klog.V(4).InfoS("removing missing entry", "key", key)
 	}
 	klog.V(5).InfoS("nrtcache: resourcestore DEL", stringify.ResourceListToLoggable(key, rs.data[key])...)
 	delete(rs.data, key)
+	delete(rs.labels, key)
+	delete(rs.owners, key)
+	delete(rs.zones, key)
+	delete(rs.targetZones, key)
+	rs.exclusive.Delete(key)
+	rs.sharedCPU.Delete(key)
+	rs.terminating.Delete(key)
+	delete(rs.deletionDeadlines, key)
 	return ok
 }
 
+// Clone returns a deep copy of this store, which can be changed without affecting this store,
+// e.g. to account a candidate pod speculatively.
+func (rs *resourceStore) Clone() *resourceStore {
+	ret := &resourceStore{
+		data:                make(map[string]corev1.ResourceList, len(rs.data)),
+		exclusive:           sets.NewString(rs.exclusive.UnsortedList()...),
+		requestless:         sets.NewString(rs.requestless.UnsortedList()...),
+		sharedCPU:           sets.NewString(rs.sharedCPU.UnsortedList()...),
+		ignored:             rs.ignored,
+		labels:              make(map[string]map[string]string, len(rs.labels)),
+		owners:              make(map[string]metav1.OwnerReference, len(rs.owners)),
+		zones:               make(map[string][]string, len(rs.zones)),
+		targetZones:         make(map[string][]string, len(rs.targetZones)),
+		terminating:         sets.NewString(rs.terminating.UnsortedList()...),
+		discountTerminating: rs.discountTerminating,
+		discountedResources: rs.discountedResources,
+		deletionDeadlines:   make(map[string]time.Time, len(rs.deletionDeadlines)),
+		evictionRelease:     rs.evictionRelease,
+		clock:               rs.clock,
+	}
+	for key, deadline := range rs.deletionDeadlines {
+		ret.deletionDeadlines[key] = deadline
+	}
+	for key, res := range rs.data {
+		ret.data[key] = res.DeepCopy()
+	}
+	// the labels are never changed once stored, so they can be shared
+	for key, labels := range rs.labels {
+		ret.labels[key] = labels
+	}
+	for key, owner := range rs.owners {
+		ret.owners[key] = owner
+	}
+	// like the labels, the zones are never changed once stored
+	for key, zoneNames := range rs.zones {
+		ret.zones[key] = zoneNames
+	}
+	for key, zoneNames := range rs.targetZones {
+		ret.targetZones[key] = zoneNames
+	}
+	return ret
+}
+
+// MergeFrom adds to this store the pods tracked in the other store, to compute the combined reserved footprint
+// across a set of nodes, like for coscheduled pod groups. Pods tracked in both stores are kept only once.
+func (rs *resourceStore) MergeFrom(other *resourceStore) {
+	if other == nil {
+		return
+	}
+	for key, res := range other.data {
+		if rs.Contains(key) {
+			klog.V(5).InfoS("nrtcache: resourcestore MERGE skipping duplicate", "key", key)
+			continue
+		}
+		rs.data[key] = res.DeepCopy()
+		rs.labels[key] = other.labels[key]
+		if owner, ok := other.owners[key]; ok {
+			rs.owners[key] = owner
+		}
+		if zoneNames, ok := other.zones[key]; ok {
+			rs.zones[key] = zoneNames
+		}
+		if zoneNames, ok := other.targetZones[key]; ok {
+			rs.targetZones[key] = zoneNames
+		}
+		if other.terminating.Has(key) {
+			rs.terminating.Insert(key)
+		}
+		if deadline, ok := other.deletionDeadlines[key]; ok {
+			rs.deletionDeadlines[key] = deadline
+		}
+		if other.exclusive.Has(key) {
+			rs.exclusive.Insert(key)
+		}
+		if other.sharedCPU.Has(key) {
+			rs.sharedCPU.Insert(key)
+		}
+	}
+	for _, key := range other.requestless.List() {
+		if rs.Contains(key) {
+			continue
+		}
+		rs.requestless.Insert(key)
+	}
+}
+
 // UpdateNRT updates the provided Node Resource Topology object with the resources tracked in this store,
-// performing pessimistic overallocation across all the NUMA zones.
+// performing pessimistic overallocation across all the NUMA zones, or only across the target zones of the pods
+// reserved with target zones, as long as the node reports them.
+// Pods requesting an exclusive NUMA zone are the exception: each one claims a whole unused zone, which
+// is accounted as fully consumed. If no unused zone can fit such a pod, it is accounted like any other pod.
+// Resources reported both at node scope (zones which are not NUMA nodes) and at NUMA zone scope are accounted
+// only at NUMA zone scope, to avoid counting them twice. Ignored resources are never accounted, and if the discounted
+// resources are set, the other resources are not accounted either.
+// The CPUs of the pods running on the shared CPU pool are accounted on the NUMA zones with a shared CPU pool
+// only for the amount exceeding the pool size. See AttributeSharedCPUPool.
+// If enabled, the terminating pods replaced by pods of the same controller are not accounted. See discountedTerminatingPods.
+// The terminating pods whose resources are released per the eviction release policy are not accounted either.
 func (rs *resourceStore) UpdateNRT(logID string, nrt *topologyv1alpha1.NodeResourceTopology) {
-	for key, res := range rs.data {
+	if nrt == nil {
+		return
+	}
+	if len(nrt.Zones) == 0 {
+		// partial data from the agent, nothing we can account
+		klog.V(3).InfoS("nrtcache: NodeTopology without zones", "logID", logID, "node", nrt.Name)
+		return
+	}
+
+	skip := rs.ignored.Union(undiscountedResources(nrt.Zones, rs.discountedResources))
+	discounted := rs.discountedTerminatingPods().Union(rs.releasedTerminatingPods())
+	reserved := make([]Reservation, 0, len(rs.data))
+	for _, key := range sets.StringKeySet(rs.data).List() {
+		if discounted.Has(key) {
+			continue
+		}
+		reserved = append(reserved, Reservation{
+			Key:       key,
+			Resources: rs.data[key],
+			Zones:     rs.targetZones[key],
+			Exclusive: rs.exclusive.Has(key),
+			SharedCPU: rs.sharedCPU.Has(key),
+		})
+	}
+	subtractReservations(logID, nrt, reserved, skip)
+}
+
+// Reservation describes the resources reserved on a node, and the NUMA zones they are charged to, if known.
+type Reservation struct {
+	// Key identifies the reservation in the logs, usually the namespace + "/" + name of the pod. Optional.
+	Key       string
+	Resources corev1.ResourceList
+	// Zones are the names of the NUMA zones the resources are charged to. If empty, or none is reported by the node,
+	// the resources are charged to all the NUMA zones.
+	Zones []string
+	// Exclusive is true if the resources claim a whole unused NUMA zone. See AnnotationExclusiveZone.
+	Exclusive bool
+	// SharedCPU is true if the CPUs are taken from the shared CPU pool. See AttributeSharedCPUPool.
+	SharedCPU bool
+}
+
+// subtractReservations decrements the given reservations from the available resources of the given NRT data, skipping
+// the resources named in the skip set, performing pessimistic overallocation across all the NUMA zones, or only across
+// the zones of the reservations, as long as the node reports them.
+// Exclusive reservations are the exception: each one claims a whole unused zone, which is accounted as fully consumed.
+// If no unused zone can fit such a reservation, it is accounted like any other reservation.
+func subtractReservations(logID string, nrt *topologyv1alpha1.NodeResourceTopology, reserved []Reservation, skip sets.String) {
+	// claim the exclusive zones first, before the pessimistic overallocation makes all the zones look used.
+	claimed := sets.NewInt()
+	for idx, rsv := range reserved {
+		if !rsv.Exclusive {
+			continue
+		}
+		zoneName, ok := claimUnusedZone(nrt.Zones, rsv.Resources)
+		if !ok {
+			klog.V(3).InfoS("nrtcache: cannot find an unused zone", "logID", logID, "node", nrt.Name, "requestor", reservationKey(idx, rsv))
+			continue
+		}
+		klog.V(5).InfoS("nrtcache: claimed exclusive zone", "logID", logID, "node", nrt.Name, "zone", zoneName, "requestor", reservationKey(idx, rsv))
+		claimed.Insert(idx)
+	}
+
+	numaZones, otherZones, numaScoped := reservationZones(logID, nrt)
+	otherZonesSkip := numaScoped.Union(skip)
+	poolZones, noPoolZones := zonesWithSharedCPUPool(numaZones)
+	poolZonesSkip := skip.Union(sets.NewString(string(corev1.ResourceCPU)))
+	var sharedCPUs resource.Quantity
+
+	for idx, rsv := range reserved {
+		if claimed.Has(idx) {
+			continue
+		}
+		key := reservationKey(idx, rsv)
 		// We cannot predict on which Zone the workload will be placed.
 		// And we should totally not guess. So the only safe (and conservative)
 		// choice is to decrement the available resources from *all* the zones.
 		// This can cause false negatives, but will never cause false positives,
-		// which are much worse.
-		for zi := 0; zi < len(nrt.Zones); zi++ {
-			zone := &nrt.Zones[zi] // shortcut
-			for ri := 0; ri < len(zone.Resources); ri++ {
-				zr := &zone.Resources[ri] // shortcut
-				qty, ok := res[corev1.ResourceName(zr.Name)]
-				if !ok {
-					// this is benign; it is totally possible some resources are not
-					// available on some zones (think PCI devices), hence we don't
-					// even report this error, being an expected condition
-					continue
-				}
-				if zr.Available.Cmp(qty) < 0 {
-					// this should happen rarely, and it is likely caused by
-					// a bug elsewhere.
-					klog.V(3).InfoS("nrtcache: cannot decrement resource", "logID", logID, "zone", zr.Name, "node", nrt.Name, "available", zr.Available, "requestor", key, "quantity", qty)
-					zr.Available = resource.Quantity{}
-					continue
-				}
-
-				zr.Available.Sub(qty)
+		// which are much worse. The exception are the reservations whose zones are known.
+		rsvNUMAZones, rsvPoolZones, rsvNoPoolZones := numaZones, poolZones, noPoolZones
+		if hasZonesNamed(numaZones, rsv.Zones) {
+			rsvNUMAZones = zonesNamed(numaZones, rsv.Zones)
+			rsvPoolZones, rsvNoPoolZones = zonesWithSharedCPUPool(rsvNUMAZones)
+		}
+		if rsv.SharedCPU && len(rsvPoolZones) > 0 {
+			// the shared pool is accounted as whole once all the reservations are known
+			sharedCPUs.Add(rsv.Resources[corev1.ResourceCPU])
+			subtractFromZones(logID, nrt.Name, key, rsv.Resources, rsvPoolZones, poolZonesSkip)
+			subtractFromZones(logID, nrt.Name, key, rsv.Resources, rsvNoPoolZones, skip)
+		} else {
+			subtractFromZones(logID, nrt.Name, key, rsv.Resources, rsvNUMAZones, skip)
+		}
+		// zones which are not NUMA nodes (e.g. sockets or the whole machine) are charged only for the resources
+		// the NUMA nodes don't report, otherwise the same request would be counted twice.
+		subtractFromZones(logID, nrt.Name, key, rsv.Resources, otherZones, otherZonesSkip)
+	}
+	if !sharedCPUs.IsZero() && !skip.Has(string(corev1.ResourceCPU)) {
+		subtractSharedCPUPoolsExcess(logID, nrt.Name, poolZones, sharedCPUs)
+	}
+}
+
+// reservationKey returns the key of the given reservation for the logs, making one up from its index if unset.
+func reservationKey(idx int, rsv Reservation) string {
+	if rsv.Key != "" {
+		return rsv.Key
+	}
+	return fmt.Sprintf("reserved-%d", idx)
+}
+
+// zonesNamed returns the zones among the given ones with any of the given names. The returned zones are shared.
+func zonesNamed(zones []*topologyv1alpha1.Zone, names []string) []*topologyv1alpha1.Zone {
+	wanted := sets.NewString(names...)
+	var ret []*topologyv1alpha1.Zone
+	for _, zone := range zones {
+		if wanted.Has(zone.Name) {
+			ret = append(ret, zone)
+		}
+	}
+	return ret
+}
+
+// hasZonesNamed returns true if any of the given zones has any of the given names.
+func hasZonesNamed(zones []*topologyv1alpha1.Zone, names []string) bool {
+	return len(zonesNamed(zones, names)) > 0
+}
+
+// reservationZones returns the zones of the given NRT data the reserved resources are subtracted from: the NUMA zones,
+// and the zones which are not NUMA nodes, which are charged only for the resources the NUMA zones don't report, also
+// returned. The returned zones are shared with the object.
+func reservationZones(logID string, nrt *topologyv1alpha1.NodeResourceTopology) ([]*topologyv1alpha1.Zone, []*topologyv1alpha1.Zone, sets.String) {
+	numaZones := zonesWithResources(logID, nrt.Name, ZonesOfType(nrt, ZoneTypeNUMANode))
+
+	var otherZones []*topologyv1alpha1.Zone
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		if !isNUMAZone(nrt.Zones[zi]) {
+			otherZones = append(otherZones, &nrt.Zones[zi])
+		}
+	}
+	otherZones = zonesWithResources(logID, nrt.Name, otherZones)
+	return numaZones, otherZones, numaScopedResources(nrt.Zones)
+}
+
+// undiscountedResources returns the names of the resources reported by the given zones which are not discounted,
+// so the reserved pods are not subtracted from them. If no discounted resources are given, all the resources are.
+func undiscountedResources(zones topologyv1alpha1.ZoneList, discounted sets.String) sets.String {
+	ret := sets.NewString()
+	if discounted.Len() == 0 {
+		return ret
+	}
+	for _, zone := range zones {
+		for _, zr := range zone.Resources {
+			if !discounted.Has(zr.Name) {
+				ret.Insert(zr.Name)
+			}
+		}
+	}
+	return ret
+}
+
+// isEmptyRequest returns true if the given resources request nothing, like for BestEffort pods.
+func isEmptyRequest(res corev1.ResourceList) bool {
+	for _, qty := range res {
+		if !qty.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// zonesWithResources filters out the zones reporting no resources, which can happen with partial data from the agent.
+func zonesWithResources(logID, nodeName string, zones []*topologyv1alpha1.Zone) []*topologyv1alpha1.Zone {
+	ret := make([]*topologyv1alpha1.Zone, 0, len(zones))
+	for _, zone := range zones {
+		if len(zone.Resources) == 0 {
+			klog.V(3).InfoS("nrtcache: zone without resources", "logID", logID, "node", nodeName, "zone", zone.Name)
+			continue
+		}
+		ret = append(ret, zone)
+	}
+	return ret
+}
+
+// subtractFromZones decrements the given resources from the available resources of all the given zones,
+// skipping the resources named in the skip set.
+func subtractFromZones(logID, nodeName, key string, res corev1.ResourceList, zones []*topologyv1alpha1.Zone, skip sets.String) {
+	for _, zone := range zones {
+		for ri := 0; ri < len(zone.Resources); ri++ {
+			zr := &zone.Resources[ri] // shortcut
+			if skip.Has(zr.Name) {
+				continue
+			}
+			qty, ok := res[corev1.ResourceName(zr.Name)]
+			if !ok {
+				// this is benign; it is totally possible some resources are not
+				// available on some zones (think PCI devices), hence we don't
+				// even report this error, being an expected condition
+				continue
+			}
+			availableBefore := zr.Available.DeepCopy()
+			subtracted := qty
+			if zr.Available.Cmp(qty) < 0 {
+				// this should happen rarely, and it is likely caused by
+				// a bug elsewhere.
+				klog.V(3).InfoS("nrtcache: cannot decrement resource", "logID", logID, "zone", zone.Name, "resource", zr.Name, "node", nodeName, "available", zr.Available, "requestor", key, "quantity", qty)
+				recordOverbookedZone(nodeName, zr.Name)
+				subtracted = availableBefore
+				zr.Available = resource.Quantity{}
+			} else {
+				zr.Available = subtractQuantity(zr.Available, qty)
+			}
+			klog.V(4).InfoS("nrtcache: accounted resource", "logID", logID, "node", nodeName, "zone", zone.Name, "resource", zr.Name, "requestor", key,
+				"capacity", zr.Capacity.String(), "availableBefore", availableBefore.String(), "subtracted", subtracted.String(), "availableAfter", zr.Available.String())
+		}
+	}
+}
+
+// ZoneTypeNUMANode is the type of the zones representing NUMA nodes, which are the zones the resources are aligned to.
+const ZoneTypeNUMANode = "Node"
+
+// ZonesOfType returns pointers to the zones of the given type of the Node Resource Topology object, in their original order.
+// The returned zones are shared with the object, so changing them changes the object.
+func ZonesOfType(nrt *topologyv1alpha1.NodeResourceTopology, zoneType string) []*topologyv1alpha1.Zone {
+	var zones []*topologyv1alpha1.Zone
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		if nrt.Zones[zi].Type == zoneType {
+			zones = append(zones, &nrt.Zones[zi])
+		}
+	}
+	return zones
+}
+
+// GetZoneByName returns a pointer to the zone with the given name of the Node Resource Topology object, or nil if there
+// is no such zone. Like for ZonesOfType, the returned zone is shared with the object.
+func GetZoneByName(nrt *topologyv1alpha1.NodeResourceTopology, name string) *topologyv1alpha1.Zone {
+	if nrt == nil {
+		return nil
+	}
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		if nrt.Zones[zi].Name == name {
+			return &nrt.Zones[zi]
+		}
+	}
+	return nil
+}
+
+// isNUMAZone returns true if the zone represents a NUMA node, false if it represents a wider scope, like the whole node.
+func isNUMAZone(zone topologyv1alpha1.Zone) bool {
+	return zone.Type == ZoneTypeNUMANode
+}
+
+// numaScopedResources returns the names of the resources reported by at least one NUMA zone.
+func numaScopedResources(zones topologyv1alpha1.ZoneList) sets.String {
+	names := sets.NewString()
+	for _, zone := range zones {
+		if !isNUMAZone(zone) {
+			continue
+		}
+		for _, zr := range zone.Resources {
+			names.Insert(zr.Name)
+		}
+	}
+	return names
+}
+
+// AggregateZoneAvailable returns the sum of the available amount of the given resource across all the zones
+// of the Node Resource Topology object, to be compared with the node allocatable. Like the resource accounting,
+// if the resource is reported both at node scope and at NUMA zone scope, only the NUMA zones are considered.
+func AggregateZoneAvailable(nrt *topologyv1alpha1.NodeResourceTopology, resourceName string) resource.Quantity {
+	return aggregateZoneResource(nrt, resourceName, func(zr topologyv1alpha1.ResourceInfo) resource.Quantity {
+		return zr.Available
+	})
+}
+
+// AggregateZoneCapacity returns the sum of the capacity of the given resource across all the zones
+// of the Node Resource Topology object. See AggregateZoneAvailable and ResourceCapacity for the details.
+func AggregateZoneCapacity(nrt *topologyv1alpha1.NodeResourceTopology, resourceName string) resource.Quantity {
+	return aggregateZoneResource(nrt, resourceName, ResourceCapacity)
+}
+
+// ResourceCapacity returns the capacity of the given zone resource. Some agents report only the availability
+// of a resource, leaving its capacity unset; in that case the availability is the best estimate we have
+// of the capacity, and using zero instead would make the resource unusable.
+func ResourceCapacity(zr topologyv1alpha1.ResourceInfo) resource.Quantity {
+	if zr.Capacity.IsZero() {
+		return zr.Available
+	}
+	return zr.Capacity
+}
+
+func aggregateZoneResource(nrt *topologyv1alpha1.NodeResourceTopology, resourceName string, value func(topologyv1alpha1.ResourceInfo) resource.Quantity) resource.Quantity {
+	total := resource.Quantity{}
+	numaScoped := numaScopedResources(nrt.Zones).Has(resourceName)
+	for _, zone := range nrt.Zones {
+		if numaScoped && !isNUMAZone(zone) {
+			continue
+		}
+		for _, zr := range zone.Resources {
+			if zr.Name != resourceName {
+				continue
 			}
+			total.Add(value(zr))
 		}
 	}
+	return total
 }
 
+// subtractQuantity returns the available quantity minus the requested quantity. The subtraction uses the canonical
+// values - bytes for memory-like resources, milli-units for fractional quantities like cpu - so mixing suffixes like
+// Gi and G can't produce surprising results, and the result keeps the format of the available quantity.
+func subtractQuantity(available, qty resource.Quantity) resource.Quantity {
+	if isFractional(available) || isFractional(qty) {
+		return *resource.NewMilliQuantity(available.MilliValue()-qty.MilliValue(), available.Format)
+	}
+	return *resource.NewQuantity(available.Value()-qty.Value(), available.Format)
+}
+
+func isFractional(qty resource.Quantity) bool {
+	return qty.MilliValue()%1000 != 0
+}
+
+// applyGranularity floors the available resources of all the zones of the provided Node Resource Topology object
+// to a multiple of the minimum allocation unit of each resource, if any.
+func applyGranularity(nrt *topologyv1alpha1.NodeResourceTopology, granularity map[corev1.ResourceName]resource.Quantity) {
+	if len(granularity) == 0 {
+		return
+	}
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		zone := &nrt.Zones[zi] // shortcut
+		for ri := 0; ri < len(zone.Resources); ri++ {
+			zr := &zone.Resources[ri] // shortcut
+			unit, ok := granularity[corev1.ResourceName(zr.Name)]
+			if !ok || unit.Sign() <= 0 {
+				continue
+			}
+			unitMilli := unit.MilliValue()
+			zr.Available = *resource.NewMilliQuantity((zr.Available.MilliValue()/unitMilli)*unitMilli, zr.Available.Format)
+		}
+	}
+}
+
+// counter counts events by key, usually node name. It is not thread safe and needs to be protected by a lock.
 type counter map[string]int
 
 func newCounter() counter {
@@ -172,6 +817,15 @@ func (cnt counter) Delete(key string) {
 	delete(cnt, key)
 }
 
+// Reset removes the given key, returning the value it had (0 if not set), so IsSet returns false afterwards.
+// Reading and removing in a single call lets periodic flushes consume the counts without losing the
+// increments happening between a separate read and Delete, as long as the lock is held across the call.
+func (cnt counter) Reset(key string) int {
+	val := cnt[key]
+	delete(cnt, key)
+	return val
+}
+
 func (cnt counter) Keys() []string {
 	keys := make([]string, 0, len(cnt))
 	for key := range cnt {
@@ -181,7 +835,7 @@ func (cnt counter) Keys() []string {
 }
 
 func (cnt counter) Clone() counter {
-	cloned := make(map[string]int)
+	cloned := make(map[string]int, len(cnt))
 	for key, val := range cnt {
 		cloned[key] = val
 	}
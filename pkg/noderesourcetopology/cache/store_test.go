@@ -17,16 +17,31 @@ limitations under the License.
 package cache
 
 import (
+	"flag"
+	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/klog/v2"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/k8stopologyawareschedwg/podfingerprint"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
 )
 
 func TestFingerprintFromNRT(t *testing.T) {
@@ -133,6 +148,163 @@ func TestNRTStoreUpdate(t *testing.T) {
 	}
 }
 
+func TestNRTEqualIgnoringStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(nrt *topologyv1alpha1.NodeResourceTopology)
+		expected bool
+	}{
+		{
+			name:     "identical",
+			mutate:   func(nrt *topologyv1alpha1.NodeResourceTopology) {},
+			expected: true,
+		},
+		{
+			name: "bookkeeping metadata changed",
+			mutate: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				nrt.ResourceVersion = "42"
+				nrt.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "agent"}}
+			},
+			expected: true,
+		},
+		{
+			name: "availability changed",
+			mutate: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				findResourceInfo(nrt.Zones[0].Resources, cpu).Available = resource.MustParse("10")
+			},
+			expected: false,
+		},
+		{
+			name: "policy changed",
+			mutate: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				nrt.TopologyPolicies = []string{string(topologyv1alpha1.RestrictedPodLevel)}
+			},
+			expected: false,
+		},
+		{
+			name: "fingerprint changed",
+			mutate: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				nrt.Annotations = map[string]string{podfingerprint.Annotation: "pfp0v001fe53c4dbd2c3f9"}
+			},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := makeTwoZonesTestTopology()
+			b := a.DeepCopy()
+			tt.mutate(b)
+			if got := NRTEqualIgnoringStatus(a, b); got != tt.expected {
+				t.Errorf("got %v expected %v", got, tt.expected)
+			}
+		})
+	}
+
+	if !NRTEqualIgnoringStatus(nil, nil) || NRTEqualIgnoringStatus(makeTwoZonesTestTopology(), nil) {
+		t.Errorf("unexpected result comparing nil objects")
+	}
+}
+
+func TestNRTStoreUpdateUnchanged(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+	nrt.ResourceVersion = "1"
+	ns := newNrtStore([]*topologyv1alpha1.NodeResourceTopology{nrt})
+
+	resent := nrt.DeepCopy()
+	resent.ResourceVersion = "2"
+	ns.Update(resent)
+	if got := ns.GetNRTReadOnly(nrt.Name).ResourceVersion; got != "1" {
+		t.Errorf("no-op update replaced the stored data: resourceVersion %q", got)
+	}
+
+	changed := resent.DeepCopy()
+	changed.ResourceVersion = "3"
+	findResourceInfo(changed.Zones[0].Resources, cpu).Available = resource.MustParse("10")
+	ns.Update(changed)
+	if got := ns.GetNRTReadOnly(nrt.Name).ResourceVersion; got != "3" {
+		t.Errorf("update not stored: resourceVersion %q", got)
+	}
+}
+
+func TestNRTStoreUpdateStaleResourceVersion(t *testing.T) {
+	ns := newNrtStore(nil)
+
+	nrt := makeTwoZonesTestTopology()
+	nrt.ResourceVersion = "10"
+	findResourceInfo(nrt.Zones[0].Resources, cpu).Available = resource.MustParse("10")
+	ns.Update(nrt)
+
+	stale := makeTwoZonesTestTopology()
+	stale.ResourceVersion = "5"
+	ns.Update(stale)
+	if got := findResourceInfo(ns.GetNRTReadOnly(nrt.Name).Zones[0].Resources, cpu).Available; got.Cmp(resource.MustParse("10")) != 0 {
+		t.Errorf("stale update regressed the availability to %s", got.String())
+	}
+	if got := ns.GetNRTReadOnly(nrt.Name).ResourceVersion; got != "10" {
+		t.Errorf("stale update stored: resourceVersion %q", got)
+	}
+
+	// the same data resent with a newer version moves the bar, even if the stored object is unchanged
+	resent := nrt.DeepCopy()
+	resent.ResourceVersion = "12"
+	ns.Update(resent)
+	stale.ResourceVersion = "11"
+	ns.Update(stale)
+	if got := findResourceInfo(ns.GetNRTReadOnly(nrt.Name).Zones[0].Resources, cpu).Available; got.Cmp(resource.MustParse("10")) != 0 {
+		t.Errorf("stale update regressed the availability to %s", got.String())
+	}
+
+	newer := makeTwoZonesTestTopology()
+	newer.ResourceVersion = "13"
+	ns.Update(newer)
+	if got := ns.GetNRTReadOnly(nrt.Name).ResourceVersion; got != "13" {
+		t.Errorf("newer update not stored: resourceVersion %q", got)
+	}
+
+	// versions which can't be compared are never considered stale
+	unversioned := makeTwoZonesTestTopology()
+	findResourceInfo(unversioned.Zones[0].Resources, cpu).Available = resource.MustParse("7")
+	ns.Update(unversioned)
+	if got := findResourceInfo(ns.GetNRTReadOnly(nrt.Name).Zones[0].Resources, cpu).Available; got.Cmp(resource.MustParse("7")) != 0 {
+		t.Errorf("unversioned update not stored: availability %s", got.String())
+	}
+}
+
+func TestNRTStoreGetReadOnly(t *testing.T) {
+	nrts := []*topologyv1alpha1.NodeResourceTopology{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-0",
+			},
+			TopologyPolicies: []string{
+				"best-effort",
+			},
+		},
+	}
+	ns := newNrtStore(nrts)
+
+	if ns.GetNRTReadOnly("node-missing") != nil {
+		t.Errorf("missing node returned non-nil data")
+	}
+
+	obj := ns.GetNRTReadOnly("node-0")
+	if obj.TopologyPolicies[0] != "best-effort" {
+		t.Errorf("unexpected value: %v", obj.TopologyPolicies)
+	}
+
+	nrt := nrts[0].DeepCopy()
+	nrt.TopologyPolicies[0] = "restricted"
+	ns.Update(nrt)
+	if obj.TopologyPolicies[0] != "restricted" {
+		t.Errorf("read-only object does not reflect the update: %v", obj.TopologyPolicies)
+	}
+
+	nrt.TopologyPolicies[0] = "none"
+	if obj.TopologyPolicies[0] != "restricted" {
+		t.Errorf("stored value is not an independent copy")
+	}
+}
+
 func TestNRTStoreGetMissing(t *testing.T) {
 	ns := newNrtStore(nil)
 	if ns.GetNRTCopyByNodeName("node-missing") != nil {
@@ -208,6 +380,38 @@ func TestCounterDelete(t *testing.T) {
 	}
 }
 
+func TestCounterReset(t *testing.T) {
+	cnt := newCounter()
+
+	cnt.Incr("aaa")
+	cnt.Incr("aaa")
+	cnt.Incr("bbb")
+
+	if val := cnt.Reset("aaa"); val != 2 {
+		t.Errorf("unexpected prior value: %d expected %d", val, 2)
+	}
+	if cnt.IsSet("aaa") {
+		t.Errorf("found unexpected key after reset: %q", "aaa")
+	}
+	if !cnt.IsSet("bbb") {
+		t.Errorf("missing expected key: %q", "bbb")
+	}
+
+	if val := cnt.Reset("aaa"); val != 0 {
+		t.Errorf("unexpected value resetting again: %d expected %d", val, 0)
+	}
+	if val := cnt.Reset("ccc"); val != 0 {
+		t.Errorf("unexpected value resetting missing key: %d expected %d", val, 0)
+	}
+	if cnt.IsSet("ccc") {
+		t.Errorf("reset created key: %q", "ccc")
+	}
+
+	if val := cnt.Incr("aaa"); val != 1 {
+		t.Errorf("unexpected value counting again after reset: %d expected %d", val, 1)
+	}
+}
+
 func TestCounterKeys(t *testing.T) {
 	cnt := newCounter()
 
@@ -296,11 +500,14 @@ func TestResourceStoreAddPod(t *testing.T) {
 	}
 }
 
-func TestResourceStoreDeletePod(t *testing.T) {
+func TestResourceStoreAddPodExclusiveZoneDropped(t *testing.T) {
 	pod := corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "ns-0",
 			Name:      "pod-0",
+			Annotations: map[string]string{
+				AnnotationExclusiveZone: "true",
+			},
 		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
@@ -308,7 +515,7 @@ func TestResourceStoreDeletePod(t *testing.T) {
 					Name: "cnt-0",
 					Resources: corev1.ResourceRequirements{
 						Requests: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceCPU:    resource.MustParse("4"),
 							corev1.ResourceMemory: resource.MustParse("4Gi"),
 						},
 					},
@@ -318,42 +525,75 @@ func TestResourceStoreDeletePod(t *testing.T) {
 	}
 
 	rs := newResourceStore()
-	existed := rs.DeletePod(&pod)
-	if existed {
-		t.Fatalf("deleted a pod into a empty resourceStore")
+	rs.AddPod(&pod)
+	if !rs.exclusive.Has("ns-0/pod-0") {
+		t.Fatalf("exclusive zone pod not tracked as exclusive")
 	}
+
+	pod.Annotations = nil
 	rs.AddPod(&pod)
-	existed = rs.DeletePod(&pod)
-	if !existed {
-		t.Fatalf("deleted a pod which was not supposed to be present")
+	if rs.exclusive.Has("ns-0/pod-0") {
+		t.Fatalf("pod still tracked as exclusive after being updated without the annotation")
+	}
+
+	// accounted pessimistically on all the zones, not claiming a whole zone
+	nrt := makeTwoZonesTestTopology()
+	rs.UpdateNRT("testing", nrt)
+	for _, zone := range nrt.Zones {
+		if avail := findResourceInfo(zone.Resources, cpu).Available; avail.Cmp(resource.MustParse("16")) != 0 {
+			t.Errorf("bad availability for resource %q on zone %s: expected %v got %v", cpu, zone.Name, "16", avail.String())
+		}
 	}
 }
 
-func TestResourceStoreUpdate(t *testing.T) {
-	nrt := &topologyv1alpha1.NodeResourceTopology{
-		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
-		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
-		Zones: topologyv1alpha1.ZoneList{
-			{
-				Name: "node-0",
-				Type: "Node",
-				Resources: topologyv1alpha1.ResourceInfoList{
-					MakeTopologyResInfo(cpu, "20", "20"),
-					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
-				},
-			},
-			{
-				Name: "node-1",
-				Type: "Node",
-				Resources: topologyv1alpha1.ResourceInfoList{
-					MakeTopologyResInfo(cpu, "20", "20"),
-					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
-					MakeTopologyResInfo(nicName, "8", "8"),
+func TestResourceStoreAddPodBestEffort(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-be",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
 				},
 			},
 		},
 	}
 
+	rs := newResourceStore()
+	existed := rs.AddPod(&pod)
+	if existed {
+		t.Fatalf("replaced a pod into a empty resourceStore")
+	}
+	if len(rs.data) != 0 {
+		t.Fatalf("accounted resources for a pod without requests: %v", rs.data)
+	}
+	if !rs.Contains("ns-0/pod-be") {
+		t.Fatalf("pod without requests not tracked")
+	}
+	existed = rs.AddPod(&pod)
+	if !existed {
+		t.Fatalf("added pod twice")
+	}
+
+	nrt := makeTwoZonesTestTopology()
+	expected := nrt.DeepCopy()
+	rs.UpdateNRT("testing", nrt)
+	if !reflect.DeepEqual(nrt, expected) {
+		t.Fatalf("availability changed by a pod without requests\ngot: %s\nexpected: %s\n", dumpNRT(nrt), dumpNRT(expected))
+	}
+
+	existed = rs.DeletePod(&pod)
+	if !existed {
+		t.Fatalf("deleted a pod which was not supposed to be present")
+	}
+	if rs.Contains("ns-0/pod-be") {
+		t.Fatalf("pod without requests still tracked after delete")
+	}
+}
+
+func TestResourceStoreUpdateLimitsOnly(t *testing.T) {
 	pod := corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "ns-0",
@@ -364,19 +604,44 @@ func TestResourceStoreUpdate(t *testing.T) {
 				{
 					Name: "cnt-0",
 					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							corev1.ResourceCPU:           resource.MustParse("16"),
-							corev1.ResourceMemory:        resource.MustParse("4Gi"),
-							corev1.ResourceName(nicName): resource.MustParse("2"),
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("4"),
 						},
 					},
 				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+	if got := rs.data["ns-0/pod-0"][corev1.ResourceCPU]; got.Cmp(resource.MustParse("4")) != 0 {
+		t.Fatalf("unexpected cpu reserved: got %s expected 4", got.String())
+	}
+
+	nrt := makeTwoZonesTestTopology()
+	rs.UpdateNRT("testing", nrt)
+	for _, zone := range nrt.Zones {
+		if got := findResourceInfo(zone.Resources, cpu).Available; got.Cmp(resource.MustParse("16")) != 0 {
+			t.Errorf("zone %s: unexpected available cpu %s", zone.Name, got.String())
+		}
+	}
+}
+
+func TestResourceStoreUpdateIgnoredResources(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
 				{
-					Name: "cnt-1",
+					Name: "cnt-0",
 					Resources: corev1.ResourceRequirements{
 						Requests: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("2"),
-							corev1.ResourceMemory: resource.MustParse("2Gi"),
+							corev1.ResourceCPU:              resource.MustParse("4"),
+							corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
 						},
 					},
 				},
@@ -384,67 +649,1245 @@ func TestResourceStoreUpdate(t *testing.T) {
 		},
 	}
 
-	rs := newResourceStore()
-	existed := rs.AddPod(&pod)
-	if existed {
-		t.Fatalf("replacing a pod into a empty resourceStore")
+	makeNRT := func() *topologyv1alpha1.NodeResourceTopology {
+		nrt := makeTwoZonesTestTopology()
+		for zi := range nrt.Zones {
+			nrt.Zones[zi].Resources = append(nrt.Zones[zi].Resources, MakeTopologyResInfo(string(corev1.ResourceEphemeralStorage), "100Gi", "100Gi"))
+		}
+		return nrt
 	}
 
-	logID := "testResourceStoreUpdate"
-	rs.UpdateNRT(logID, nrt)
-
-	cpuInfo0 := findResourceInfo(nrt.Zones[0].Resources, cpu)
-	if cpuInfo0.Capacity.Cmp(resource.MustParse("20")) != 0 {
-		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", cpu, 0, "20", cpuInfo0.Capacity)
-	}
-	if cpuInfo0.Available.Cmp(resource.MustParse("2")) != 0 {
-		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", cpu, 0, "2", cpuInfo0.Available)
-	}
-	cpuInfo1 := findResourceInfo(nrt.Zones[1].Resources, cpu)
-	if cpuInfo1.Capacity.Cmp(resource.MustParse("20")) != 0 {
-		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", cpu, 1, "20", cpuInfo1.Capacity)
-	}
-	if cpuInfo1.Available.Cmp(resource.MustParse("2")) != 0 {
-		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", cpu, 1, "2", cpuInfo1.Available)
+	tests := []struct {
+		name              string
+		ignored           sets.String
+		expectedEphemeral string
+	}{
+		{
+			name:              "accounted",
+			expectedEphemeral: "90Gi",
+		},
+		{
+			name:              "ignored",
+			ignored:           sets.NewString(string(corev1.ResourceEphemeralStorage), string(corev1.ResourcePods)),
+			expectedEphemeral: "100Gi",
+		},
 	}
 
-	memInfo0 := findResourceInfo(nrt.Zones[0].Resources, memory)
-	if memInfo0.Capacity.Cmp(resource.MustParse("32Gi")) != 0 {
-		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", memory, 0, "32Gi", memInfo0.Capacity)
-	}
-	if memInfo0.Available.Cmp(resource.MustParse("26Gi")) != 0 {
-		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", memory, 0, "26Gi", memInfo0.Available)
-	}
-	memInfo1 := findResourceInfo(nrt.Zones[1].Resources, memory)
-	if memInfo1.Capacity.Cmp(resource.MustParse("32Gi")) != 0 {
-		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", memory, 1, "32Gi", memInfo1.Capacity)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := newResourceStore()
+			rs.ignored = tt.ignored
+			rs.AddPod(&pod)
+
+			nrt := makeNRT()
+			rs.UpdateNRT("testing", nrt)
+			for _, zone := range nrt.Zones {
+				if got := findResourceInfo(zone.Resources, cpu).Available; got.Cmp(resource.MustParse("16")) != 0 {
+					t.Errorf("zone %s: unexpected available cpu %s", zone.Name, got.String())
+				}
+				got := findResourceInfo(zone.Resources, string(corev1.ResourceEphemeralStorage)).Available
+				if got.Cmp(resource.MustParse(tt.expectedEphemeral)) != 0 {
+					t.Errorf("zone %s: unexpected available ephemeral-storage %s expected %s", zone.Name, got.String(), tt.expectedEphemeral)
+				}
+			}
+		})
 	}
-	if memInfo1.Available.Cmp(resource.MustParse("26Gi")) != 0 {
-		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", memory, 1, "26Gi", memInfo1.Available)
+}
+
+func TestResourceStoreClone(t *testing.T) {
+	makePodWithCPU := func(name, cpuQty string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns-0",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "cnt-0",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse(cpuQty),
+							},
+						},
+					},
+				},
+			},
+		}
 	}
 
-	devInfo0 := findResourceInfo(nrt.Zones[0].Resources, nicName)
-	if devInfo0 != nil {
-		t.Errorf("unexpected device %q on zone %d", nicName, 0)
+	rs := newResourceStore()
+	rs.AddPod(makePodWithCPU("pod-0", "4"))
+
+	cloned := rs.Clone()
+	if !reflect.DeepEqual(rs, cloned) {
+		t.Fatalf("clone differs from the original:\n%s\nvs\n%s", cloned.String(), rs.String())
 	}
 
-	devInfo1 := findResourceInfo(nrt.Zones[1].Resources, nicName)
-	if devInfo1 == nil {
-		t.Fatalf("expected device %q on zone %d, but missing", nicName, 1)
+	// speculative accounting of a candidate pod
+	cloned.AddPod(makePodWithCPU("pod-1", "8"))
+	qty := cloned.data["ns-0/pod-0"][corev1.ResourceCPU]
+	qty.Add(resource.MustParse("1"))
+	cloned.data["ns-0/pod-0"][corev1.ResourceCPU] = qty
+
+	nrt := makeTwoZonesTestTopology()
+	cloned.UpdateNRT("testing", nrt.DeepCopy())
+
+	if rs.Contains("ns-0/pod-1") {
+		t.Errorf("pod added to the clone is tracked in the original")
 	}
-	if devInfo1.Capacity.Cmp(resource.MustParse("8")) != 0 {
-		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", nicName, 1, "8", devInfo1.Capacity)
+	if got := rs.data["ns-0/pod-0"][corev1.ResourceCPU]; got.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("resources changed in the clone changed the original: %s", got.String())
 	}
-	if devInfo1.Available.Cmp(resource.MustParse("6")) != 0 {
-		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", nicName, 1, "6", devInfo1.Available)
+
+	rs.UpdateNRT("testing", nrt)
+	for _, zone := range nrt.Zones {
+		if got := findResourceInfo(zone.Resources, cpu).Available; got.Cmp(resource.MustParse("16")) != 0 {
+			t.Errorf("zone %s: unexpected available cpu %s", zone.Name, got.String())
+		}
 	}
 }
 
-func findResourceInfo(rinfos []topologyv1alpha1.ResourceInfo, name string) *topologyv1alpha1.ResourceInfo {
-	for idx := 0; idx < len(rinfos); idx++ {
-		if rinfos[idx].Name == name {
-			return &rinfos[idx]
+func TestResourceStoreMergeFrom(t *testing.T) {
+	makePod := func(name, cpu, memory string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns-0",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "cnt-0",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse(cpu),
+								corev1.ResourceMemory: resource.MustParse(memory),
+							},
+						},
+					},
+				},
+			},
 		}
 	}
-	return nil
+
+	totals := func(rs *resourceStore) corev1.ResourceList {
+		res := corev1.ResourceList{}
+		for _, podRes := range rs.data {
+			for name, qty := range podRes {
+				total := res[name]
+				total.Add(qty)
+				res[name] = total
+			}
+		}
+		return res
+	}
+
+	tests := []struct {
+		name          string
+		pods          []*corev1.Pod
+		otherPods     []*corev1.Pod
+		expectedPods  int
+		expectedTotal corev1.ResourceList
+	}{
+		{
+			name:      "disjoint",
+			pods:      []*corev1.Pod{makePod("pod-0", "2", "4Gi")},
+			otherPods: []*corev1.Pod{makePod("pod-1", "4", "8Gi"), makePod("pod-2", "1", "1Gi")},
+
+			expectedPods: 3,
+			expectedTotal: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("7"),
+				corev1.ResourceMemory: resource.MustParse("13Gi"),
+			},
+		},
+		{
+			name:      "overlapping",
+			pods:      []*corev1.Pod{makePod("pod-0", "2", "4Gi"), makePod("pod-1", "4", "8Gi")},
+			otherPods: []*corev1.Pod{makePod("pod-1", "4", "8Gi"), makePod("pod-2", "1", "1Gi")},
+
+			expectedPods: 3,
+			expectedTotal: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("7"),
+				corev1.ResourceMemory: resource.MustParse("13Gi"),
+			},
+		},
+		{
+			name:      "empty other",
+			pods:      []*corev1.Pod{makePod("pod-0", "2", "4Gi")},
+			otherPods: nil,
+
+			expectedPods: 1,
+			expectedTotal: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := newResourceStore()
+			for _, pod := range tt.pods {
+				rs.AddPod(pod)
+			}
+			other := newResourceStore()
+			for _, pod := range tt.otherPods {
+				other.AddPod(pod)
+			}
+			otherPodCount := len(other.data)
+
+			rs.MergeFrom(other)
+
+			if len(rs.data) != tt.expectedPods {
+				t.Errorf("unexpected pods count: got %d expected %d", len(rs.data), tt.expectedPods)
+			}
+			got := totals(rs)
+			for name, qty := range tt.expectedTotal {
+				gotQty := got[name]
+				if gotQty.Cmp(qty) != 0 {
+					t.Errorf("unexpected total for %q: got %s expected %s", name, gotQty.String(), qty.String())
+				}
+			}
+			if len(other.data) != otherPodCount {
+				t.Errorf("merge modified the source store")
+			}
+		})
+	}
+}
+
+func TestResourceStoreDeletePod(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	existed := rs.DeletePod(&pod)
+	if existed {
+		t.Fatalf("deleted a pod into a empty resourceStore")
+	}
+	rs.AddPod(&pod)
+	existed = rs.DeletePod(&pod)
+	if !existed {
+		t.Fatalf("deleted a pod which was not supposed to be present")
+	}
+}
+
+func makeTwoZonesTestTopology() *topologyv1alpha1.NodeResourceTopology {
+	return &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+					MakeTopologyResInfo(nicName, "8", "8"),
+				},
+			},
+		},
+	}
+}
+
+func TestResourceStoreUpdate(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:           resource.MustParse("16"),
+							corev1.ResourceMemory:        resource.MustParse("4Gi"),
+							corev1.ResourceName(nicName): resource.MustParse("2"),
+						},
+					},
+				},
+				{
+					Name: "cnt-1",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	existed := rs.AddPod(&pod)
+	if existed {
+		t.Fatalf("replacing a pod into a empty resourceStore")
+	}
+
+	logID := "testResourceStoreUpdate"
+	rs.UpdateNRT(logID, nrt)
+
+	cpuInfo0 := findResourceInfo(nrt.Zones[0].Resources, cpu)
+	if cpuInfo0.Capacity.Cmp(resource.MustParse("20")) != 0 {
+		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", cpu, 0, "20", cpuInfo0.Capacity)
+	}
+	if cpuInfo0.Available.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", cpu, 0, "2", cpuInfo0.Available)
+	}
+	cpuInfo1 := findResourceInfo(nrt.Zones[1].Resources, cpu)
+	if cpuInfo1.Capacity.Cmp(resource.MustParse("20")) != 0 {
+		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", cpu, 1, "20", cpuInfo1.Capacity)
+	}
+	if cpuInfo1.Available.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", cpu, 1, "2", cpuInfo1.Available)
+	}
+
+	memInfo0 := findResourceInfo(nrt.Zones[0].Resources, memory)
+	if memInfo0.Capacity.Cmp(resource.MustParse("32Gi")) != 0 {
+		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", memory, 0, "32Gi", memInfo0.Capacity)
+	}
+	if memInfo0.Available.Cmp(resource.MustParse("26Gi")) != 0 {
+		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", memory, 0, "26Gi", memInfo0.Available)
+	}
+	memInfo1 := findResourceInfo(nrt.Zones[1].Resources, memory)
+	if memInfo1.Capacity.Cmp(resource.MustParse("32Gi")) != 0 {
+		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", memory, 1, "32Gi", memInfo1.Capacity)
+	}
+	if memInfo1.Available.Cmp(resource.MustParse("26Gi")) != 0 {
+		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", memory, 1, "26Gi", memInfo1.Available)
+	}
+
+	devInfo0 := findResourceInfo(nrt.Zones[0].Resources, nicName)
+	if devInfo0 != nil {
+		t.Errorf("unexpected device %q on zone %d", nicName, 0)
+	}
+
+	devInfo1 := findResourceInfo(nrt.Zones[1].Resources, nicName)
+	if devInfo1 == nil {
+		t.Fatalf("expected device %q on zone %d, but missing", nicName, 1)
+	}
+	if devInfo1.Capacity.Cmp(resource.MustParse("8")) != 0 {
+		t.Errorf("bad capacity for resource %q on zone %d: expected %v got %v", nicName, 1, "8", devInfo1.Capacity)
+	}
+	if devInfo1.Available.Cmp(resource.MustParse("6")) != 0 {
+		t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", nicName, 1, "6", devInfo1.Available)
+	}
+
+	expectedZone := "zone node-1: cpu 2/20, memory 26Gi/32Gi, " + nicName + " 6/8"
+	if got := stringify.Zone(nrt.Zones[1]); got != expectedZone {
+		t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, expectedZone)
+	}
+}
+
+func TestSubtractReservationsTargetZones(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+
+	reserved := []Reservation{
+		{
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Zones: []string{"node-1"},
+		},
+		{
+			// unknown zones are ignored, so the reservation is charged to all the zones
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("2"),
+			},
+			Zones: []string{"node-7"},
+		},
+	}
+
+	subtractReservations("testing", nrt, reserved, sets.NewString())
+	expected := []string{
+		"zone node-0: cpu 18/20, memory 32Gi/32Gi",
+		"zone node-1: cpu 14/20, memory 28Gi/32Gi, " + nicName + " 8/8",
+	}
+	for zi, zone := range nrt.Zones {
+		if got := stringify.Zone(zone); got != expected[zi] {
+			t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, expected[zi])
+		}
+	}
+}
+
+func TestSubtractReservationsExclusiveAndSharedCPU(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+	nrt.Zones[1].Attributes = topologyv1alpha1.AttributeList{
+		{Name: AttributeSharedCPUPool, Value: "4"},
+	}
+
+	reserved := []Reservation{
+		{
+			Key: "ns/exclusive",
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			Exclusive: true,
+		},
+		{
+			// fits the shared pool of the remaining zone, which is already accounted as consumed
+			Key: "ns/shared",
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			SharedCPU: true,
+		},
+	}
+
+	subtractReservations("testing", nrt, reserved, sets.NewString())
+	expected := []string{
+		"zone node-0: cpu 0/20, memory 0/32Gi",
+		"zone node-1: cpu 20/20, memory 30Gi/32Gi, " + nicName + " 8/8",
+	}
+	for zi, zone := range nrt.Zones {
+		if got := stringify.Zone(zone); got != expected[zi] {
+			t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, expected[zi])
+		}
+	}
+}
+
+func TestResourceStoreUpdateSameDevice(t *testing.T) {
+	devices := corev1.ResourceList{
+		corev1.ResourceName(nicName): resource.MustParse("2"),
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: devices,
+						Limits:   devices,
+					},
+				},
+				{
+					Name: "cnt-1",
+					Resources: corev1.ResourceRequirements{
+						Requests: devices,
+						Limits:   devices,
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+
+	// updating twice must not account the devices twice
+	for i := 0; i < 2; i++ {
+		nrt := makeTwoZonesTestTopology()
+		rs.UpdateNRT("testResourceStoreUpdateSameDevice", nrt)
+
+		devInfo := findResourceInfo(nrt.Zones[1].Resources, nicName)
+		if devInfo == nil {
+			t.Fatalf("expected device %q on zone %d, but missing", nicName, 1)
+		}
+		if devInfo.Available.Cmp(resource.MustParse("4")) != 0 {
+			t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", nicName, 1, "4", devInfo.Available)
+		}
+	}
+}
+
+func TestResourceStoreUpdateSimilarDeviceNames(t *testing.T) {
+	// devices advertised per slot, with names differing only by suffix
+	nicSlotName := nicName + ".1"
+	nrt := makeTwoZonesTestTopology()
+	nrt.Zones[1].Resources = append(nrt.Zones[1].Resources, MakeTopologyResInfo(nicSlotName, "4", "4"))
+
+	devices := corev1.ResourceList{
+		corev1.ResourceName(nicName):     resource.MustParse("2"),
+		corev1.ResourceName(nicSlotName): resource.MustParse("1"),
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: devices,
+						Limits:   devices,
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+	rs.UpdateNRT("testResourceStoreUpdateSimilarDeviceNames", nrt)
+
+	expected := "zone node-1: cpu 20/20, memory 32Gi/32Gi, " + nicName + " 6/8, " + nicSlotName + " 3/4"
+	if got := stringify.Zone(nrt.Zones[1]); got != expected {
+		t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, expected)
+	}
+}
+
+func TestResourceStoreUpdateNodeAndZoneScope(t *testing.T) {
+	fpgaName := "vendor.com/fpga"
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "machine",
+				Type: "Machine",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					{
+						Name:      cpu,
+						Capacity:  resource.MustParse("40"),
+						Available: resource.MustParse("36"),
+					},
+					{
+						Name:      fpgaName,
+						Capacity:  resource.MustParse("2"),
+						Available: resource.MustParse("2"),
+					},
+				},
+			},
+			{
+				Name:   "node-0",
+				Type:   "Node",
+				Parent: "machine",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					{
+						Name:      cpu,
+						Capacity:  resource.MustParse("20"),
+						Available: resource.MustParse("18"),
+					},
+				},
+			},
+			{
+				Name:   "node-1",
+				Type:   "Node",
+				Parent: "machine",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					{
+						Name:      cpu,
+						Capacity:  resource.MustParse("20"),
+						Available: resource.MustParse("18"),
+					},
+				},
+			},
+		},
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:            resource.MustParse("4"),
+							corev1.ResourceName(fpgaName): resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+	rs.UpdateNRT("testResourceStoreUpdateNodeAndZoneScope", nrt)
+
+	// cpu is reported at both scopes: only the NUMA zones drive the accounting
+	for zi, expected := range []string{"36", "14", "14"} {
+		cpuInfo := findResourceInfo(nrt.Zones[zi].Resources, cpu)
+		if cpuInfo.Available.Cmp(resource.MustParse(expected)) != 0 {
+			t.Errorf("bad availability for resource %q on zone %q: expected %v got %v", cpu, nrt.Zones[zi].Name, expected, cpuInfo.Available)
+		}
+	}
+
+	// the fpga is reported only at node scope, so it is accounted there
+	fpgaInfo := findResourceInfo(nrt.Zones[0].Resources, fpgaName)
+	if fpgaInfo.Available.Cmp(resource.MustParse("1")) != 0 {
+		t.Errorf("bad availability for resource %q on zone %q: expected %v got %v", fpgaName, nrt.Zones[0].Name, "1", fpgaInfo.Available)
+	}
+}
+
+func TestAggregateZoneResources(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+
+	tests := []struct {
+		resourceName     string
+		expectedCapacity string
+		expectedAvail    string
+	}{
+		{
+			resourceName:     cpu,
+			expectedCapacity: "40",
+			expectedAvail:    "40",
+		},
+		{
+			resourceName:     nicName,
+			expectedCapacity: "8",
+			expectedAvail:    "8",
+		},
+		{
+			resourceName:     "vendor.com/missing",
+			expectedCapacity: "0",
+			expectedAvail:    "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceName, func(t *testing.T) {
+			capacity := AggregateZoneCapacity(nrt, tt.resourceName)
+			if capacity.Cmp(resource.MustParse(tt.expectedCapacity)) != 0 {
+				t.Errorf("bad capacity for resource %q: expected %v got %v", tt.resourceName, tt.expectedCapacity, capacity.String())
+			}
+			avail := AggregateZoneAvailable(nrt, tt.resourceName)
+			if avail.Cmp(resource.MustParse(tt.expectedAvail)) != 0 {
+				t.Errorf("bad availability for resource %q: expected %v got %v", tt.resourceName, tt.expectedAvail, avail.String())
+			}
+		})
+	}
+}
+
+func makeSocketTestTopology() *topologyv1alpha1.NodeResourceTopology {
+	nrt := makeTwoZonesTestTopology()
+	nrt.Zones[0].Parent = "socket-0"
+	nrt.Zones[1].Parent = "socket-0"
+	nrt.Zones = append(topologyv1alpha1.ZoneList{
+		{
+			Name: "socket-0",
+			Type: "Socket",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "40", "40"),
+				MakeTopologyResInfo(memory, "64Gi", "64Gi"),
+			},
+		},
+	}, nrt.Zones...)
+	return nrt
+}
+
+func TestZonesOfType(t *testing.T) {
+	nrt := makeSocketTestTopology()
+
+	numaZones := ZonesOfType(nrt, ZoneTypeNUMANode)
+	if len(numaZones) != 2 || numaZones[0].Name != "node-0" || numaZones[1].Name != "node-1" {
+		t.Fatalf("unexpected NUMA zones: %v", numaZones)
+	}
+	socketZones := ZonesOfType(nrt, "Socket")
+	if len(socketZones) != 1 || socketZones[0].Name != "socket-0" {
+		t.Fatalf("unexpected socket zones: %v", socketZones)
+	}
+	if zones := ZonesOfType(nrt, "Core"); len(zones) != 0 {
+		t.Fatalf("unexpected core zones: %v", zones)
+	}
+
+	// zones are shared with the object
+	numaZones[0].Name = "renamed"
+	if nrt.Zones[1].Name != "renamed" {
+		t.Errorf("zone is not shared with the object")
+	}
+}
+
+func TestGetZoneByName(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+
+	zone := GetZoneByName(nrt, "node-1")
+	if zone == nil || zone.Name != "node-1" {
+		t.Fatalf("unexpected zone: %v", zone)
+	}
+	if res := findResourceInfo(zone.Resources, "vendor_A.com/nic"); res == nil {
+		t.Errorf("unexpected zone resources: %v", zone.Resources)
+	}
+
+	// zones are shared with the object
+	zone.Name = "renamed"
+	if nrt.Zones[1].Name != "renamed" {
+		t.Errorf("zone is not shared with the object")
+	}
+
+	if zone := GetZoneByName(nrt, "node-2"); zone != nil {
+		t.Errorf("unexpected zone: %v", zone)
+	}
+	if zone := GetZoneByName(nil, "node-0"); zone != nil {
+		t.Errorf("unexpected zone from nil object: %v", zone)
+	}
+}
+
+func TestResourceStoreUpdateSocketZone(t *testing.T) {
+	nrt := makeSocketTestTopology()
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+	rs.UpdateNRT("testResourceStoreUpdateSocketZone", nrt)
+
+	expected := map[string]map[string]string{
+		"socket-0": {cpu: "40", memory: "64Gi"},
+		"node-0":   {cpu: "16", memory: "28Gi"},
+		"node-1":   {cpu: "16", memory: "28Gi"},
+	}
+	for _, zone := range nrt.Zones {
+		for resName, qty := range expected[zone.Name] {
+			info := findResourceInfo(zone.Resources, resName)
+			if info.Available.Cmp(resource.MustParse(qty)) != 0 {
+				t.Errorf("bad availability for resource %q on zone %q: expected %v got %v", resName, zone.Name, qty, info.Available)
+			}
+		}
+	}
+}
+
+func TestResourceStoreUpdateNilZoneResources(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+			Annotations: map[string]string{
+				AnnotationExclusiveZone: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	pod2 := pod.DeepCopy()
+	pod2.Name = "pod-1"
+	pod2.Annotations = nil
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+	rs.AddPod(pod2)
+
+	// must not panic
+	rs.UpdateNRT("testResourceStoreUpdateNilZoneResources", nil)
+	rs.UpdateNRT("testResourceStoreUpdateNilZoneResources", &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+	})
+
+	nrt := makeTwoZonesTestTopology()
+	nrt.Zones = append(topologyv1alpha1.ZoneList{
+		{
+			Name: "node-2",
+			Type: "Node",
+		},
+	}, nrt.Zones...)
+	rs.UpdateNRT("testResourceStoreUpdateNilZoneResources", nrt)
+
+	if nrt.Zones[0].Resources != nil {
+		t.Errorf("zone without resources was changed: %v", nrt.Zones[0].Resources)
+	}
+	// the exclusive pod claims node-0, the other pod is charged on both the NUMA zones
+	expected := map[string]map[string]string{
+		"node-0": {cpu: "0", memory: "0"},
+		"node-1": {cpu: "16", memory: "28Gi"},
+	}
+	for _, zone := range nrt.Zones[1:] {
+		for resName, qty := range expected[zone.Name] {
+			info := findResourceInfo(zone.Resources, resName)
+			if info == nil {
+				t.Fatalf("missing resource %q on zone %q", resName, zone.Name)
+			}
+			if info.Available.Cmp(resource.MustParse(qty)) != 0 {
+				t.Errorf("bad availability for resource %q on zone %q: expected %v got %v", resName, zone.Name, qty, info.Available)
+			}
+		}
+	}
+}
+
+type logEntry struct {
+	msg    string
+	fields map[string]interface{}
+}
+
+// fakeLogSink records the messages logged through it
+type fakeLogSink struct {
+	entries *[]logEntry
+}
+
+func (fls fakeLogSink) Init(info logr.RuntimeInfo) {}
+func (fls fakeLogSink) Enabled(level int) bool     { return true }
+func (fls fakeLogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fls.Info(0, msg, keysAndValues...)
+}
+func (fls fakeLogSink) WithValues(keysAndValues ...interface{}) logr.LogSink { return fls }
+func (fls fakeLogSink) WithName(name string) logr.LogSink                    { return fls }
+
+func (fls fakeLogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	entry := logEntry{
+		msg:    msg,
+		fields: make(map[string]interface{}),
+	}
+	for idx := 0; idx+1 < len(keysAndValues); idx += 2 {
+		entry.fields[fmt.Sprintf("%v", keysAndValues[idx])] = keysAndValues[idx+1]
+	}
+	*fls.entries = append(*fls.entries, entry)
+}
+
+func setLogVerbosity(t *testing.T, level string) (restore func()) {
+	var fs flag.FlagSet
+	klog.InitFlags(&fs)
+	prev := fs.Lookup("v").Value.String()
+	if err := fs.Set("v", level); err != nil {
+		t.Fatalf("cannot set the log verbosity: %v", err)
+	}
+	return func() {
+		_ = fs.Set("v", prev)
+	}
+}
+
+func TestResourceStoreUpdateNRTLogsAccounting(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+
+	var entries []logEntry
+	klog.SetLogger(logr.New(fakeLogSink{entries: &entries}))
+	defer klog.ClearLogger()
+
+	restore := setLogVerbosity(t, "3")
+	rs.UpdateNRT("testLogsAccounting", makeTwoZonesTestTopology())
+	restore()
+	for _, entry := range entries {
+		if entry.msg == "nrtcache: accounted resource" {
+			t.Fatalf("accounting logged at low verbosity: %v", entry.fields)
+		}
+	}
+
+	restore = setLogVerbosity(t, "4")
+	rs.UpdateNRT("testLogsAccounting", makeTwoZonesTestTopology())
+	restore()
+
+	var found *logEntry
+	for idx := range entries {
+		entry := &entries[idx]
+		if entry.msg == "nrtcache: accounted resource" && entry.fields["zone"] == "node-0" && entry.fields["resource"] == cpu {
+			found = entry
+			break
+		}
+	}
+	if found == nil {
+		t.Fatalf("missing accounting log entry, got %v", entries)
+	}
+
+	expected := map[string]string{
+		"logID":           "testLogsAccounting",
+		"node":            "node",
+		"requestor":       "ns-0/pod-0",
+		"capacity":        "20",
+		"availableBefore": "20",
+		"subtracted":      "4",
+		"availableAfter":  "16",
+	}
+	for key, val := range expected {
+		got, ok := found.fields[key]
+		if !ok {
+			t.Errorf("missing field %q", key)
+			continue
+		}
+		if gotStr := fmt.Sprintf("%v", got); gotStr != val {
+			t.Errorf("field %q: expected %q got %q", key, val, gotStr)
+		}
+	}
+}
+
+func TestNRTStoreMetrics(t *testing.T) {
+	registry := metrics.NewKubeRegistry()
+	registry.MustRegister(nrtLookups, nrtMaxStaleness)
+	nrtLookups.Reset()
+
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))
+	nrs := newNrtStore(nil)
+	nrs.clock = fakeClock
+
+	nrs.Update(&topologyv1alpha1.NodeResourceTopology{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}})
+	fakeClock.Step(30 * time.Second)
+	nrs.Update(&topologyv1alpha1.NodeResourceTopology{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+
+	if val, err := testutil.GetGaugeMetricValue(nrtMaxStaleness); err != nil || val != 30 {
+		t.Errorf("unexpected staleness after update: %v (err=%v)", val, err)
+	}
+
+	nrs.GetNRTCopyByNodeName("node-0")
+	nrs.GetNRTCopyByNodeName("node-1")
+	nrs.GetNRTCopyByNodeName("node-7")
+
+	expected := `
+# HELP nrtcache_lookups_total [ALPHA] Number of lookups of the cached NodeResourceTopology data by result: 'hit' or 'miss' (node not cached).
+# TYPE nrtcache_lookups_total counter
+nrtcache_lookups_total{result="hit"} 2
+nrtcache_lookups_total{result="miss"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "nrtcache_lookups_total"); err != nil {
+		t.Errorf("unexpected lookups metric: %v", err)
+	}
+
+	fakeClock.Step(15 * time.Second)
+	nrs.UpdateStalenessMetric()
+	if val, err := testutil.GetGaugeMetricValue(nrtMaxStaleness); err != nil || val != 45 {
+		t.Errorf("unexpected staleness after refresh: %v (err=%v)", val, err)
+	}
+
+	// the stalest node is updated, so the staleness is now measured on the other node
+	nrs.Update(&topologyv1alpha1.NodeResourceTopology{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}})
+	if val, err := testutil.GetGaugeMetricValue(nrtMaxStaleness); err != nil || val != 15 {
+		t.Errorf("unexpected staleness after update: %v (err=%v)", val, err)
+	}
+}
+
+func TestResourceStoreUpdateNRTOverbookedMetric(t *testing.T) {
+	registry := metrics.NewKubeRegistry()
+	registry.MustRegister(nrtOverbookedZones)
+	nrtOverbookedZones.Reset()
+	defer SetOverbookedZonesMetricByNode(false)
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+
+	// only the cpu of node-0 is overbooked
+	makeOverbookedNRT := func() *topologyv1alpha1.NodeResourceTopology {
+		nrt := makeTwoZonesTestTopology()
+		findResourceInfo(nrt.Zones[0].Resources, cpu).Available = resource.MustParse("2")
+		return nrt
+	}
+
+	rs.UpdateNRT("testOverbookedMetric", makeOverbookedNRT())
+	expected := `
+# HELP nrtcache_overbooked_zones_total [ALPHA] Number of times the accounting of the reserved resources found less resources available in a zone than requested, by node (if enabled) and resource.
+# TYPE nrtcache_overbooked_zones_total counter
+nrtcache_overbooked_zones_total{node="",resource="cpu"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "nrtcache_overbooked_zones_total"); err != nil {
+		t.Errorf("unexpected overbooked metric: %v", err)
+	}
+
+	SetOverbookedZonesMetricByNode(true)
+	rs.UpdateNRT("testOverbookedMetric", makeOverbookedNRT())
+	rs.UpdateNRT("testOverbookedMetric", makeOverbookedNRT())
+	expected = `
+# HELP nrtcache_overbooked_zones_total [ALPHA] Number of times the accounting of the reserved resources found less resources available in a zone than requested, by node (if enabled) and resource.
+# TYPE nrtcache_overbooked_zones_total counter
+nrtcache_overbooked_zones_total{node="",resource="cpu"} 1
+nrtcache_overbooked_zones_total{node="node",resource="cpu"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "nrtcache_overbooked_zones_total"); err != nil {
+		t.Errorf("unexpected overbooked metric: %v", err)
+	}
+}
+
+func TestResourceStoreUpdateMixedMemorySuffixes(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+		},
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1500m"),
+							corev1.ResourceMemory: resource.MustParse("4G"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore()
+	rs.AddPod(&pod)
+	rs.UpdateNRT("testResourceStoreUpdateMixedMemorySuffixes", nrt)
+
+	memInfo := findResourceInfo(nrt.Zones[0].Resources, memory)
+	expectedBytes := int64(32*1024*1024*1024 - 4*1000*1000*1000)
+	if memInfo.Available.Value() != expectedBytes {
+		t.Errorf("bad availability for resource %q: expected %d bytes got %d (%v)", memory, expectedBytes, memInfo.Available.Value(), memInfo.Available.String())
+	}
+	if memInfo.Available.Format != resource.BinarySI {
+		t.Errorf("bad format for resource %q: expected %v got %v", memory, resource.BinarySI, memInfo.Available.Format)
+	}
+
+	cpuInfo := findResourceInfo(nrt.Zones[0].Resources, cpu)
+	if cpuInfo.Available.Cmp(resource.MustParse("18500m")) != 0 {
+		t.Errorf("bad availability for resource %q: expected %v got %v", cpu, "18500m", cpuInfo.Available.String())
+	}
+}
+
+func findResourceInfo(rinfos []topologyv1alpha1.ResourceInfo, name string) *topologyv1alpha1.ResourceInfo {
+	for idx := 0; idx < len(rinfos); idx++ {
+		if rinfos[idx].Name == name {
+			return &rinfos[idx]
+		}
+	}
+	return nil
+}
+
+func makeBenchmarkNRT(zoneCount, devicesPerZone int) *topologyv1alpha1.NodeResourceTopology {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-0",
+		},
+		TopologyPolicies: []string{
+			"single-numa-node",
+		},
+	}
+	for zi := 0; zi < zoneCount; zi++ {
+		zone := topologyv1alpha1.Zone{
+			Name: fmt.Sprintf("node-%d", zi),
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				{Name: "cpu", Capacity: resource.MustParse("64"), Available: resource.MustParse("64")},
+				{Name: "memory", Capacity: resource.MustParse("256Gi"), Available: resource.MustParse("256Gi")},
+			},
+		}
+		for di := 0; di < devicesPerZone; di++ {
+			zone.Resources = append(zone.Resources, topologyv1alpha1.ResourceInfo{
+				Name:      fmt.Sprintf("vendor.com/dev%d", di),
+				Capacity:  resource.MustParse("8"),
+				Available: resource.MustParse("8"),
+			})
+		}
+		nrt.Zones = append(nrt.Zones, zone)
+	}
+	return nrt
+}
+
+func BenchmarkNRTStoreGetNRTCopyByNodeName(b *testing.B) {
+	ns := newNrtStore([]*topologyv1alpha1.NodeResourceTopology{makeBenchmarkNRT(8, 32)})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ns.GetNRTCopyByNodeName("node-0")
+	}
+}
+
+func BenchmarkNRTStoreGetNRTReadOnly(b *testing.B) {
+	ns := newNrtStore([]*topologyv1alpha1.NodeResourceTopology{makeBenchmarkNRT(8, 32)})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ns.GetNRTReadOnly("node-0")
+	}
+}
+
+const benchmarkCounterKeys = 10000
+
+func makeBenchmarkCounterKeys() []string {
+	keys := make([]string, 0, benchmarkCounterKeys)
+	for i := 0; i < benchmarkCounterKeys; i++ {
+		keys = append(keys, fmt.Sprintf("node-%05d", i))
+	}
+	return keys
+}
+
+func BenchmarkCounterIncr(b *testing.B) {
+	keys := makeBenchmarkCounterKeys()
+	cnt := newCounter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cnt.Incr(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkCounterKeys(b *testing.B) {
+	cnt := newCounter()
+	for _, key := range makeBenchmarkCounterKeys() {
+		cnt.Incr(key)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if keys := cnt.Keys(); len(keys) != benchmarkCounterKeys {
+			b.Fatalf("unexpected keys count: %d", len(keys))
+		}
+	}
+}
+
+func BenchmarkCounterClone(b *testing.B) {
+	cnt := newCounter()
+	for _, key := range makeBenchmarkCounterKeys() {
+		cnt.Incr(key)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if cloned := cnt.Clone(); cloned.Len() != benchmarkCounterKeys {
+			b.Fatalf("unexpected cloned keys count: %d", cloned.Len())
+		}
+	}
+}
+
+func TestResourceStorePodsInZone(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+	podZones := map[string][]string{
+		"pod-a": {"node-0"},
+		"pod-b": {"node-1"},
+		"pod-c": {"node-0", "node-1"},
+	}
+	for _, name := range []string{"pod-a", "pod-b", "pod-c"} {
+		pod := makeOwnedPod(name, types.UID("rs-"+name), "2")
+		nrtCache.ReserveNodeResources("node", pod)
+		if !nrtCache.SetPodZones("node", pod, podZones[name]) {
+			t.Fatalf("cannot set the zones of pod %s", name)
+		}
+	}
+	if nrtCache.SetPodZones("node", makeOwnedPod("pod-missing", types.UID("rs-1"), "2"), []string{"node-0"}) {
+		t.Errorf("set the zones of a pod not reserved")
+	}
+
+	rs := nrtCache.assumedResources["node"]
+	expected := map[string][]types.NamespacedName{
+		"node-0": {
+			{Namespace: "namespace1", Name: "pod-a"},
+			{Namespace: "namespace1", Name: "pod-c"},
+		},
+		"node-1": {
+			{Namespace: "namespace1", Name: "pod-b"},
+			{Namespace: "namespace1", Name: "pod-c"},
+		},
+		"node-2": {},
+	}
+	for zone, pods := range expected {
+		if got := rs.PodsInZone(zone); !reflect.DeepEqual(got, pods) {
+			t.Errorf("unexpected pods in zone %s: got %v expected %v", zone, got, pods)
+		}
+	}
+
+	if owner := rs.owners["namespace1/pod-b"]; owner.Kind != "ReplicaSet" || owner.Name != "rs-pod-b" {
+		t.Errorf("unexpected owner of pod-b: %+v", owner)
+	}
+
+	rs.deleteKey("namespace1/pod-a")
+	if got := rs.PodsInZone("node-0"); !reflect.DeepEqual(got, []types.NamespacedName{{Namespace: "namespace1", Name: "pod-c"}}) {
+		t.Errorf("unexpected pods in zone node-0 after deletion: %v", got)
+	}
 }
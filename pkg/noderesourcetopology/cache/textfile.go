@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	textfileZoneAvailable = metricsSubsystem + "_zone_available"
+	textfileZoneCapacity  = metricsSubsystem + "_zone_capacity"
+)
+
+var textfileLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteTextfile renders the per-zone availability of the cached nodes, net of the resources reserved by
+// the scheduler, in the Prometheus text exposition format, suitable for the node-exporter textfile collector.
+func (ov *OverReserve) WriteTextfile(w io.Writer) error {
+	return WriteZoneAvailabilityTextfile(w, ov.zoneAvailabilitySnapshot())
+}
+
+func (ov *OverReserve) zoneAvailabilitySnapshot() []*topologyv1alpha1.NodeResourceTopology {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	nrts := make([]*topologyv1alpha1.NodeResourceTopology, 0, len(ov.nrts.data))
+	for nodeName := range ov.nrts.data {
+		nrt := ov.nrts.GetNRTCopyByNodeName(nodeName)
+		subtractReservedFromZones("textfile", nrt)
+		if nodeAssumedResources, ok := ov.assumedResources[nodeName]; ok {
+			nodeAssumedResources.UpdateNRT("textfile", nrt)
+		}
+		nrts = append(nrts, nrt)
+	}
+	return nrts
+}
+
+// WriteZoneAvailabilityTextfile renders the available and the capacity amounts of each resource of each
+// zone of the given NRT objects in the Prometheus text exposition format. The output is sorted by node,
+// zone and resource, so rendering the same data always yields the same text.
+func WriteZoneAvailabilityTextfile(w io.Writer, nrts []*topologyv1alpha1.NodeResourceTopology) error {
+	sorted := make([]*topologyv1alpha1.NodeResourceTopology, 0, len(nrts))
+	for _, nrt := range nrts {
+		if nrt == nil {
+			continue
+		}
+		sorted = append(sorted, nrt)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var available, capacity strings.Builder
+	for _, nrt := range sorted {
+		zones := make(topologyv1alpha1.ZoneList, len(nrt.Zones))
+		copy(zones, nrt.Zones)
+		sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+
+		for _, zone := range zones {
+			resInfos := make(topologyv1alpha1.ResourceInfoList, len(zone.Resources))
+			copy(resInfos, zone.Resources)
+			sort.Slice(resInfos, func(i, j int) bool { return resInfos[i].Name < resInfos[j].Name })
+
+			for _, resInfo := range resInfos {
+				labels := textfileLabels(nrt.Name, zone.Name, resInfo.Name)
+				fmt.Fprintf(&available, "%s{%s} %g\n", textfileZoneAvailable, labels, resInfo.Available.AsApproximateFloat64())
+				fmt.Fprintf(&capacity, "%s{%s} %g\n", textfileZoneCapacity, labels, resInfo.Capacity.AsApproximateFloat64())
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# HELP " + textfileZoneAvailable + " Amount of resource available in the NUMA zone, net of the resources reserved by the scheduler.\n")
+	sb.WriteString("# TYPE " + textfileZoneAvailable + " gauge\n")
+	sb.WriteString(available.String())
+	sb.WriteString("# HELP " + textfileZoneCapacity + " Amount of resource capacity of the NUMA zone.\n")
+	sb.WriteString("# TYPE " + textfileZoneCapacity + " gauge\n")
+	sb.WriteString(capacity.String())
+
+	_, err := io.WriteString(w, sb.String())
+	if err != nil {
+		klog.ErrorS(err, "nrtcache: cannot write the textfile")
+	}
+	return err
+}
+
+func textfileLabels(nodeName, zoneName, resourceName string) string {
+	return fmt.Sprintf(`node="%s",zone="%s",resource="%s"`,
+		textfileLabelEscaper.Replace(nodeName),
+		textfileLabelEscaper.Replace(zoneName),
+		textfileLabelEscaper.Replace(resourceName))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWriteZoneAvailabilityTextfile(t *testing.T) {
+	nrt := makeTwoZonesTestTopology()
+	findResourceInfo(nrt.Zones[0].Resources, cpu).Available = resource.MustParse("12")
+
+	var buf bytes.Buffer
+	if err := WriteZoneAvailabilityTextfile(&buf, nil); err != nil {
+		t.Fatalf("unexpected error rendering no nodes: %v", err)
+	}
+	buf.Reset()
+	if err := WriteZoneAvailabilityTextfile(&buf, []*topologyv1alpha1.NodeResourceTopology{nrt}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := buf.String()
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("cannot parse the rendered text: %v\n%s", err, text)
+	}
+	for _, name := range []string{textfileZoneAvailable, textfileZoneCapacity} {
+		family, ok := families[name]
+		if !ok {
+			t.Fatalf("missing metric family %q:\n%s", name, text)
+		}
+		if family.GetType() != dto.MetricType_GAUGE {
+			t.Errorf("metric family %q: unexpected type %v", name, family.GetType())
+		}
+		// cpu and memory on both zones, plus the NIC on the second
+		if len(family.GetMetric()) != 5 {
+			t.Errorf("metric family %q: unexpected metrics count %d", name, len(family.GetMetric()))
+		}
+	}
+
+	expectedLines := []string{
+		`nrtcache_zone_available{node="node",zone="node-0",resource="cpu"} 12`,
+		`nrtcache_zone_available{node="node",zone="node-1",resource="cpu"} 20`,
+		`nrtcache_zone_available{node="node",zone="node-1",resource="vendor_A.com/nic"} 8`,
+		`nrtcache_zone_capacity{node="node",zone="node-0",resource="cpu"} 20`,
+		`nrtcache_zone_capacity{node="node",zone="node-0",resource="memory"} 3.4359738368e+10`,
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestOverReserveWriteTextfile(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("4"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nrtCache.ReserveNodeResources("node", pod)
+
+	var buf bytes.Buffer
+	if err := nrtCache.WriteTextfile(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := buf.String()
+
+	// reserved resources are pessimistically accounted on all the zones
+	for _, line := range []string{
+		`nrtcache_zone_available{node="node",zone="node-0",resource="cpu"} 16`,
+		`nrtcache_zone_available{node="node",zone="node-1",resource="cpu"} 16`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, text)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// nodeTrust tracks a node whose NRT data is not trusted yet, because it just appeared in the cache.
+type nodeTrust struct {
+	// since is when the podset fingerprint was last observed changing.
+	since       time.Time
+	fingerprint string
+	uid         types.UID
+}
+
+// distrustNode starts the trust delay of the node described by the given NRT data. Must be called with the lock held.
+func (ov *OverReserve) distrustNode(logID string, nrt *topologyv1alpha1.NodeResourceTopology) {
+	ov.untrustedNodes[nrt.Name] = nodeTrust{
+		since:       ov.clock.Now(),
+		fingerprint: podFingerprintForNodeTopology(nrt),
+		uid:         nrt.UID,
+	}
+	klog.V(4).InfoS("nrtcache: distrusting new node", "logID", logID, "node", nrt.Name, "delay", ov.NRTTrustDelay)
+}
+
+// needsTrustDelay returns true if the given NRT data must go through the trust delay before being used, because it
+// starts a new life of the node: the node is new to the cache, its NRT object was deleted and recreated, like when
+// the node rejoins the cluster, or its topology exporter restarted and reset the podset fingerprint. Must be called
+// with the lock held.
+func (ov *OverReserve) needsTrustDelay(nrt *topologyv1alpha1.NodeResourceTopology) bool {
+	if ov.NRTTrustDelay <= 0 {
+		return false
+	}
+	cached := ov.nrts.GetNRTReadOnly(nrt.Name)
+	if cached == nil {
+		return true
+	}
+	if nrtRecreated(cached, nrt) {
+		return true
+	}
+	// a restarting exporter publishes its data before computing the podset fingerprint
+	return podFingerprintForNodeTopology(cached) != "" && podFingerprintForNodeTopology(nrt) == ""
+}
+
+// nrtRecreated returns true if the given NRT objects of the same node are not the same object, because it was deleted
+// and created again in between. The generation of a new object starts over, so it goes backwards.
+func nrtRecreated(cached, nrt *topologyv1alpha1.NodeResourceTopology) bool {
+	if cached.UID != "" && nrt.UID != "" && cached.UID != nrt.UID {
+		return true
+	}
+	return nrt.Generation < cached.Generation
+}
+
+// isNodeUntrusted returns true if the NRT data of the given node can't be used yet. Must be called with the lock held.
+func (ov *OverReserve) isNodeUntrusted(nodeName string) bool {
+	_, ok := ov.untrustedNodes[nodeName]
+	return ok
+}
+
+// observeTrust checks the given NRT data of an untrusted node. The node becomes trusted once its podset fingerprint
+// stayed the same for NRTTrustDelay; the data is then cached. A changing fingerprint, or a recreated NRT object, restarts
+// the delay.
+// Returns true if the node is trusted.
+func (ov *OverReserve) observeTrust(logID string, nrt *topologyv1alpha1.NodeResourceTopology) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	trust, ok := ov.untrustedNodes[nrt.Name]
+	if !ok {
+		return true
+	}
+
+	now := ov.clock.Now()
+	fingerprint := podFingerprintForNodeTopology(nrt)
+	if fingerprint != trust.fingerprint || nrt.UID != trust.uid {
+		klog.V(4).InfoS("nrtcache: untrusted node data changed, restarting the delay", "logID", logID, "node", nrt.Name, "fingerprint", fingerprint, "uid", nrt.UID)
+		ov.untrustedNodes[nrt.Name] = nodeTrust{
+			since:       now,
+			fingerprint: fingerprint,
+			uid:         nrt.UID,
+		}
+		return false
+	}
+	if now.Sub(trust.since) < ov.NRTTrustDelay {
+		return false
+	}
+
+	nrt, ok = sanitizeAvailability(logID, nrt, ov.StrictAvailability)
+	if !ok {
+		klog.V(2).InfoS("nrtcache: rejected update with negative availability", "logID", logID, "node", nrt.Name)
+		return false
+	}
+	ov.nrts.Update(nrt)
+	ov.headroom.Record(nrt)
+	delete(ov.untrustedNodes, nrt.Name)
+	klog.V(4).InfoS("nrtcache: trusting node", "logID", logID, "node", nrt.Name, "stableFor", now.Sub(trust.since))
+	return true
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestNRTTrustDelay(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	nrtCache.clock = fakeClock
+	nrtCache.NRTTrustDelay = 30 * time.Second
+
+	nrt := makeTwoZonesTestTopology()
+	nrt.Annotations = map[string]string{
+		podfingerprint.Annotation: "pfp0v001aaaaaaaaaaaaaaaa",
+	}
+	fakeInformer.Informer().GetStore().Add(nrt)
+	nrtCache.FlushNodes("testing", nrt)
+
+	// the reserved pod is accounted only once the cached data is trusted
+	if _, err := nrtCache.ReserveNodeResources("node", makeOwnedPod("pod-0", "uid-0", "2")); err != nil {
+		t.Fatalf("unexpected reserve error: %v", err)
+	}
+
+	expectTrusted := func(t *testing.T, expected bool) {
+		t.Helper()
+		nrtCopy, ok := nrtCache.GetCachedNRTCopy("node", &corev1.Pod{})
+		if nrtCopy == nil || !ok {
+			// untrusted nodes must stay usable, like without the cache
+			t.Fatalf("node filtered out: data=%v ok=%v", nrtCopy != nil, ok)
+		}
+		if got := findResourceInfo(nrtCopy.Zones[0].Resources, cpu).Available.Cmp(resource.MustParse("18")) == 0; got != expected {
+			t.Fatalf("node trusted=%v expected %v", got, expected)
+		}
+		dirtyNodes := nrtCache.NodesMaybeOverReserved("testing")
+		if got := len(dirtyNodes) == 0; got != expected {
+			t.Fatalf("unexpected dirty nodes %v, trusted expected %v", dirtyNodes, expected)
+		}
+	}
+
+	expectTrusted(t, false)
+
+	fakeClock.Step(10 * time.Second)
+	nrtCache.Resync()
+	expectTrusted(t, false)
+
+	// the data changed, so the delay restarts
+	changed := nrt.DeepCopy()
+	changed.Annotations[podfingerprint.Annotation] = "pfp0v001bbbbbbbbbbbbbbbb"
+	fakeInformer.Informer().GetStore().Update(changed)
+	fakeClock.Step(10 * time.Second)
+	nrtCache.Resync()
+	expectTrusted(t, false)
+
+	fakeClock.Step(25 * time.Second)
+	nrtCache.Resync()
+	expectTrusted(t, false)
+
+	fakeClock.Step(10 * time.Second)
+	nrtCache.Resync()
+	expectTrusted(t, true)
+
+	nrtCopy, _ := nrtCache.GetCachedNRTCopy("node", &corev1.Pod{})
+	if got := nrtCopy.Annotations[podfingerprint.Annotation]; got != "pfp0v001bbbbbbbbbbbbbbbb" {
+		t.Errorf("unexpected cached fingerprint %q", got)
+	}
+}
+
+func TestNRTTrustDelayDisabled(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+	nrtCache.FlushNodes("testing", makeTwoZonesTestTopology())
+
+	nrtCopy, ok := nrtCache.GetCachedNRTCopy("node", &corev1.Pod{})
+	if nrtCopy == nil || !ok {
+		t.Fatalf("node not trusted with the delay disabled")
+	}
+}
+
+func TestNRTTrustDelayKnownNode(t *testing.T) {
+	tests := []struct {
+		name      string
+		restart   func(nrt *topologyv1alpha1.NodeResourceTopology)
+		untrusted bool
+	}{
+		{
+			name: "updated object",
+			restart: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				nrt.Generation++
+			},
+		},
+		{
+			name: "recreated object",
+			restart: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				nrt.UID = "uid-nrt-1"
+				nrt.Generation = 1
+			},
+			untrusted: true,
+		},
+		{
+			name: "recreated object without uid",
+			restart: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				nrt.UID = ""
+				nrt.Generation = 1
+			},
+			untrusted: true,
+		},
+		{
+			name: "fingerprint reset",
+			restart: func(nrt *topologyv1alpha1.NodeResourceTopology) {
+				delete(nrt.Annotations, podfingerprint.Annotation)
+				nrt.Generation++
+			},
+			untrusted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrt := makeTwoZonesTestTopology()
+			nrt.UID = "uid-nrt-0"
+			nrt.Generation = 5
+			nrt.Annotations = map[string]string{
+				podfingerprint.Annotation: "pfp0v001aaaaaaaaaaaaaaaa",
+			}
+
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+
+			// the node is known since the cache creation, so its data is trusted immediately
+			nrtCache := mustOverReserve(t, fakeInformer.Lister(), &fakePodByNodeNameIndex{})
+			fakeClock := clocktesting.NewFakeClock(time.Now())
+			nrtCache.clock = fakeClock
+			nrtCache.NRTTrustDelay = 30 * time.Second
+
+			if nrtCache.isNodeUntrustedLocked("node") {
+				t.Fatalf("known node not trusted")
+			}
+
+			restarted := nrt.DeepCopy()
+			tt.restart(restarted)
+			fakeInformer.Informer().GetStore().Update(restarted)
+			nrtCache.FlushNodes("testing", restarted)
+
+			if got := nrtCache.isNodeUntrustedLocked("node"); got != tt.untrusted {
+				t.Fatalf("node untrusted=%v expected %v", got, tt.untrusted)
+			}
+
+			fakeClock.Step(10 * time.Second)
+			nrtCache.Resync()
+			if got := nrtCache.isNodeUntrustedLocked("node"); got != tt.untrusted {
+				t.Fatalf("node untrusted=%v expected %v before the delay elapsed", got, tt.untrusted)
+			}
+
+			fakeClock.Step(25 * time.Second)
+			nrtCache.Resync()
+			if nrtCache.isNodeUntrustedLocked("node") {
+				t.Fatalf("node still untrusted after the delay elapsed")
+			}
+
+			// once trusted again, the same object is not distrusted anymore
+			nrtCache.FlushNodes("testing", restarted)
+			if nrtCache.isNodeUntrustedLocked("node") {
+				t.Fatalf("node untrusted after flushing the trusted data again")
+			}
+		})
+	}
+}
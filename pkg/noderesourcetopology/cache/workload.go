@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"k8s.io/klog/v2"
+)
+
+// WorkloadStats summarizes how well the pods of a workload use the NUMA zones of their nodes.
+type WorkloadStats struct {
+	// Pods is the number of pods of the workload accounted in the stats.
+	Pods int
+	// AlignmentQuality is the average of the inverse of the least number of NUMA zones each pod can be spread across:
+	// 1 means all the pods fit in a single zone, lower values mean the pods are spread across more zones.
+	AlignmentQuality float64
+	// Utilization is, per resource, the average share of the capacity of the NUMA zones of their node the pods request.
+	Utilization map[string]float64
+}
+
+// WorkloadEfficiencyReport groups the pods tracked by the cache by the value of the given label, and computes the
+// stats of each group, for SRE reviews. Pods without the label, without requests or on nodes without NRT data are
+// not reported. The resources not reported by the NUMA zones of a node are not part of the utilization.
+func (ov *OverReserve) WorkloadEfficiencyReport(labelKey string) map[string]WorkloadStats {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	type workloadSums struct {
+		pods        int
+		quality     float64
+		utilization map[string]float64
+		samples     map[string]int
+	}
+	sums := make(map[string]*workloadSums)
+
+	for nodeName, rs := range ov.assumedResources {
+		nrt := ov.nrts.GetNRTReadOnly(nodeName)
+		if nrt == nil {
+			continue
+		}
+		zones := ZonesOfType(nrt, ZoneTypeNUMANode)
+		for key, res := range rs.data {
+			workload, ok := rs.labels[key][labelKey]
+			if !ok {
+				continue
+			}
+			ws, ok := sums[workload]
+			if !ok {
+				ws = &workloadSums{
+					utilization: make(map[string]float64),
+					samples:     make(map[string]int),
+				}
+				sums[workload] = ws
+			}
+			ws.pods++
+			ws.quality += 1.0 / float64(misalignmentCost(res, zones))
+			for resName, qty := range res {
+				if !zonesReportResource(zones, string(resName)) {
+					continue
+				}
+				capacity := AggregateZoneCapacity(nrt, string(resName))
+				if capacity.IsZero() {
+					continue
+				}
+				ws.utilization[string(resName)] += qty.AsApproximateFloat64() / capacity.AsApproximateFloat64()
+				ws.samples[string(resName)]++
+			}
+		}
+	}
+
+	report := make(map[string]WorkloadStats, len(sums))
+	for workload, ws := range sums {
+		stats := WorkloadStats{
+			Pods:             ws.pods,
+			AlignmentQuality: ws.quality / float64(ws.pods),
+			Utilization:      make(map[string]float64, len(ws.utilization)),
+		}
+		for resName, val := range ws.utilization {
+			stats.Utilization[resName] = val / float64(ws.samples[resName])
+		}
+		report[workload] = stats
+	}
+	klog.V(5).InfoS("nrtcache: workload efficiency report", "labelKey", labelKey, "workloads", len(report))
+	return report
+}
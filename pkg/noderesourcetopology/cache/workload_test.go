@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadEfficiencyReport(t *testing.T) {
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeIndex := &fakePodByNodeNameIndex{}
+
+	nrtCache := mustOverReserve(t, fakeInformer.Lister(), fakeIndex)
+	nrtCache.Store().Update(makeTwoZonesTestTopology())
+
+	makeWorkloadPod := func(name, workload string, requests corev1.ResourceList) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace1",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: requests,
+						},
+					},
+				},
+			},
+		}
+		if workload != "" {
+			pod.Labels = map[string]string{"app": workload}
+		}
+		return pod
+	}
+
+	// the node has 2 zones with 20 cpus and 32Gi each
+	nrtCache.ReserveNodeResources("node", makeWorkloadPod("web-1", "web", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+	}))
+	nrtCache.ReserveNodeResources("node", makeWorkloadPod("web-2", "web", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("20"),
+	}))
+	nrtCache.ReserveNodeResources("node", makeWorkloadPod("batch-1", "batch", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("30"),
+	}))
+	nrtCache.ReserveNodeResources("node", makeWorkloadPod("unlabeled", "", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("2"),
+	}))
+	// nodes without data are not reported
+	nrtCache.ReserveNodeResources("node-unknown", makeWorkloadPod("web-3", "web", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("2"),
+	}))
+
+	got := nrtCache.WorkloadEfficiencyReport("app")
+	expected := map[string]WorkloadStats{
+		"web": {
+			Pods:             2,
+			AlignmentQuality: 1,
+			Utilization: map[string]float64{
+				cpu:    0.375,
+				memory: 0.25,
+			},
+		},
+		"batch": {
+			Pods:             1,
+			AlignmentQuality: 0.5,
+			Utilization: map[string]float64{
+				cpu: 0.75,
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected report:\ngot      %+v\nexpected %+v", got, expected)
+	}
+
+	if got := nrtCache.WorkloadEfficiencyReport("missing"); len(got) != 0 {
+		t.Errorf("unexpected report for missing label: %+v", got)
+	}
+}
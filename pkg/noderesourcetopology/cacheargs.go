@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+// validateNegativeAvailabilityPolicy returns an error if the given policy for the negative availability is unknown.
+// The empty policy is accepted, and behaves like NegativeAvailabilityClamp.
+func validateNegativeAvailabilityPolicy(policy apiconfig.NegativeAvailabilityPolicy) error {
+	switch policy {
+	case "", apiconfig.NegativeAvailabilityClamp, apiconfig.NegativeAvailabilityReject:
+		return nil
+	default:
+		return fmt.Errorf("unknown negative availability policy %q", policy)
+	}
+}
+
+// validateAccountedSchedulerNames returns an error if any of the given scheduler names is empty.
+func validateAccountedSchedulerNames(names []string) error {
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("empty accounted scheduler name")
+		}
+	}
+	return nil
+}
+
+// validateEvictionReleasePolicy returns an error if the given policy for the terminating pods is unknown.
+// The empty policy is accepted, and behaves like EvictionReleaseOnDeletion.
+func validateEvictionReleasePolicy(policy apiconfig.EvictionReleasePolicy) error {
+	switch policy {
+	case "", apiconfig.EvictionReleaseOnDeletion, apiconfig.EvictionReleaseImmediate, apiconfig.EvictionReleaseAtGraceExpiry:
+		return nil
+	default:
+		return fmt.Errorf("unknown eviction release policy %q", policy)
+	}
+}
+
+// evictionReleasePolicy returns the cache policy matching the given config policy, which must be valid.
+func evictionReleasePolicy(policy apiconfig.EvictionReleasePolicy) nrtcache.EvictionReleasePolicy {
+	switch policy {
+	case apiconfig.EvictionReleaseImmediate:
+		return nrtcache.EvictionReleaseImmediate
+	case apiconfig.EvictionReleaseAtGraceExpiry:
+		return nrtcache.EvictionReleaseAtGraceExpiry
+	default:
+		return nrtcache.EvictionReleaseOnDeletion
+	}
+}
+
+// validateResourceGranularity returns an error if the granularity of any resource is not positive.
+func validateResourceGranularity(granularity map[string]resource.Quantity) error {
+	for name, qty := range granularity {
+		if qty.Sign() <= 0 {
+			return fmt.Errorf("granularity of resource %q must be positive, got %s", name, qty.String())
+		}
+	}
+	return nil
+}
+
+// resourceGranularity returns the granularity of the given config in the form the cache expects.
+func resourceGranularity(granularity map[string]resource.Quantity) map[corev1.ResourceName]resource.Quantity {
+	if len(granularity) == 0 {
+		return nil
+	}
+	ret := make(map[corev1.ResourceName]resource.Quantity, len(granularity))
+	for name, qty := range granularity {
+		ret[corev1.ResourceName(name)] = qty.DeepCopy()
+	}
+	return ret
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+func TestValidateNegativeAvailabilityPolicy(t *testing.T) {
+	for _, policy := range []apiconfig.NegativeAvailabilityPolicy{"", apiconfig.NegativeAvailabilityClamp, apiconfig.NegativeAvailabilityReject} {
+		if err := validateNegativeAvailabilityPolicy(policy); err != nil {
+			t.Errorf("unexpected error for policy %q: %v", policy, err)
+		}
+	}
+	if err := validateNegativeAvailabilityPolicy("Ignore"); err == nil {
+		t.Errorf("unknown policy accepted")
+	}
+}
+
+func TestValidateAccountedSchedulerNames(t *testing.T) {
+	for _, names := range [][]string{nil, {"default-scheduler"}, {"default-scheduler", "topo-aware-scheduler"}} {
+		if err := validateAccountedSchedulerNames(names); err != nil {
+			t.Errorf("unexpected error for names %v: %v", names, err)
+		}
+	}
+	if err := validateAccountedSchedulerNames([]string{"default-scheduler", ""}); err == nil {
+		t.Errorf("empty scheduler name accepted")
+	}
+}
+
+func TestEvictionReleasePolicy(t *testing.T) {
+	tests := []struct {
+		policy   apiconfig.EvictionReleasePolicy
+		expected nrtcache.EvictionReleasePolicy
+	}{
+		{policy: "", expected: nrtcache.EvictionReleaseOnDeletion},
+		{policy: apiconfig.EvictionReleaseOnDeletion, expected: nrtcache.EvictionReleaseOnDeletion},
+		{policy: apiconfig.EvictionReleaseImmediate, expected: nrtcache.EvictionReleaseImmediate},
+		{policy: apiconfig.EvictionReleaseAtGraceExpiry, expected: nrtcache.EvictionReleaseAtGraceExpiry},
+	}
+	for _, tt := range tests {
+		if err := validateEvictionReleasePolicy(tt.policy); err != nil {
+			t.Errorf("unexpected error for policy %q: %v", tt.policy, err)
+		}
+		if got := evictionReleasePolicy(tt.policy); got != tt.expected {
+			t.Errorf("policy %q: got %q expected %q", tt.policy, got, tt.expected)
+		}
+	}
+	if err := validateEvictionReleasePolicy("Never"); err == nil {
+		t.Errorf("unknown policy accepted")
+	}
+}
+
+func TestResourceGranularity(t *testing.T) {
+	granularity := map[string]resource.Quantity{
+		string(corev1.ResourceCPU): resource.MustParse("1"),
+		"example.com/device":       resource.MustParse("2"),
+	}
+	if err := validateResourceGranularity(granularity); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := resourceGranularity(granularity)
+	if len(got) != 2 {
+		t.Fatalf("got %d resources expected 2", len(got))
+	}
+	if qty := got[corev1.ResourceCPU]; qty.Cmp(resource.MustParse("1")) != 0 {
+		t.Errorf("cpu granularity: got %s expected 1", qty.String())
+	}
+	if qty := got["example.com/device"]; qty.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("device granularity: got %s expected 2", qty.String())
+	}
+	if got := resourceGranularity(map[string]resource.Quantity{}); got != nil {
+		t.Errorf("empty granularity: got %v expected nil", got)
+	}
+
+	for _, qty := range []string{"0", "-1"} {
+		if err := validateResourceGranularity(map[string]resource.Quantity{"cpu": resource.MustParse(qty)}); err == nil {
+			t.Errorf("granularity %s accepted", qty)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// AnnotationColocateContainers is the pod annotation requesting all the containers of the pod to be aligned
+// to the same NUMA zone, e.g. because they communicate through shared memory. Value must be a boolean.
+const AnnotationColocateContainers = "noderesourcetopology/colocate-containers"
+
+// isColocatedContainersPod returns true if the given pod requests its containers to share a NUMA zone.
+func isColocatedContainersPod(pod *v1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	val, ok := pod.Annotations[AnnotationColocateContainers]
+	if !ok {
+		return false
+	}
+	colocate, err := strconv.ParseBool(val)
+	return err == nil && colocate
+}
+
+// colocatedContainersHandler admits pods requesting the co-location of their containers only if all the containers
+// fit together in a single NUMA zone, regardless of the scope of the policy. Containers which would fit on different
+// zones one by one are not enough. This is enforced by the scheduler only; the kubelet is not aware of the co-location.
+func colocatedContainersHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+	klog.V(5).InfoS("Colocated containers handler")
+
+	resources := util.GetPodEffectiveRequest(pod)
+
+	logID := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	nodes := createNUMANodeList(zones)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes("colocated containers handler NUMA resources", nodeInfo.Node().Name, nodes)
+	klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, resources)...)
+
+	if _, match := resourcesAvailableInAnyNUMANodes(logID, nodes, resources, v1qos.GetPodQOS(pod), nodeInfo); !match {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot colocate containers of pod: %s", pod.Name))
+	}
+	return nil
+}
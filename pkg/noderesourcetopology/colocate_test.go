@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestIsColocatedContainersPod(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:     "no annotations",
+			expected: false,
+		},
+		{
+			name:        "enabled",
+			annotations: map[string]string{AnnotationColocateContainers: "true"},
+			expected:    true,
+		},
+		{
+			name:        "disabled",
+			annotations: map[string]string{AnnotationColocateContainers: "false"},
+			expected:    false,
+		},
+		{
+			name:        "malformed",
+			annotations: map[string]string{AnnotationColocateContainers: "yes please"},
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := isColocatedContainersPod(pod); got != tt.expected {
+				t.Errorf("got %v expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestColocatedContainersFilter(t *testing.T) {
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy, cpuAvailable ...string) *topologyv1alpha1.NodeResourceTopology {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(policy)},
+		}
+		for idx, avail := range cpuAvailable {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha1.Zone{
+				Name: fmt.Sprintf("node-%d", idx),
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", avail),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		colocate   bool
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "containers fit different zones",
+			nrt:        makeNRT(topologyv1alpha1.SingleNUMANodeContainerLevel, "4", "4"),
+			wantStatus: nil,
+		},
+		{
+			name:       "colocated containers, no zone can hold both",
+			nrt:        makeNRT(topologyv1alpha1.SingleNUMANodeContainerLevel, "4", "4"),
+			colocate:   true,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot colocate containers of pod: testpod"),
+		},
+		{
+			name:       "colocated containers, a zone can hold both",
+			nrt:        makeNRT(topologyv1alpha1.SingleNUMANodeContainerLevel, "4", "4", "8"),
+			colocate:   true,
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted containers span zones",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedContainerLevel, "4", "4"),
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted colocated containers, no zone can hold both",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedContainerLevel, "4", "4"),
+			colocate:   true,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot colocate containers of pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(tt.nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			pod := makePod("testpod",
+				withMultiContainers([]v1.ResourceList{
+					{
+						v1.ResourceCPU:    resource.MustParse("4"),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+					{
+						v1.ResourceCPU:    resource.MustParse("4"),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				}),
+			)
+			if tt.colocate {
+				pod.Annotations = map[string]string{AnnotationColocateContainers: "true"}
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+
+			zones, ok := SimulatePlacement(tt.nrt, pod, topologyv1alpha1.TopologyManagerPolicy(tt.nrt.TopologyPolicies[0]))
+			if ok != (tt.wantStatus == nil) {
+				t.Errorf("simulation disagrees with the filter: simulated %v zones %v", ok, zones)
+			}
+			if ok && tt.colocate && len(zones) != 1 {
+				t.Errorf("colocated containers simulated on zones %v", zones)
+			}
+		})
+	}
+}
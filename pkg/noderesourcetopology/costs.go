@@ -0,0 +1,227 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"gonum.org/v1/gonum/stat/combin"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// costMatrix maps the zone names to the cost (distance) to reach the other zones, as reported
+// in the zone Costs field: zoneFrom -> zoneTo -> cost
+type costMatrix map[string]map[string]int64
+
+func costMatrixFromZones(zones topologyv1alpha1.ZoneList) costMatrix {
+	cm := make(costMatrix, len(zones))
+	for _, zone := range zones {
+		if len(zone.Costs) == 0 {
+			continue
+		}
+		costs := make(map[string]int64, len(zone.Costs))
+		for _, costInfo := range zone.Costs {
+			costs[costInfo.Name] = costInfo.Value
+		}
+		cm[zone.Name] = costs
+	}
+	return cm
+}
+
+// cost returns the cost between two zones, and a boolean reporting if the cost is known
+func (cm costMatrix) cost(from, to string) (int64, bool) {
+	costs, ok := cm[from]
+	if !ok {
+		return 0, false
+	}
+	val, ok := costs[to]
+	return val, ok
+}
+
+// ValidateCostMatrix checks the zone costs (distances) reported in the given NodeResourceTopology are sane.
+// The cost between two zones must be the same in both directions (symmetry) and the direct cost between two
+// zones must never exceed the cost of going through a third zone (triangle inequality).
+// Returns all the violations found, or nil if the cost matrix is valid. Zones not reporting costs are ignored.
+func ValidateCostMatrix(nrt *topologyv1alpha1.NodeResourceTopology) []error {
+	if nrt == nil {
+		return nil
+	}
+	var errs []error
+	cm := costMatrixFromZones(nrt.Zones)
+
+	for _, zoneA := range nrt.Zones {
+		for _, costInfo := range zoneA.Costs {
+			zoneB := costInfo.Name
+			rev, ok := cm.cost(zoneB, zoneA.Name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("node %q: missing cost from zone %q to zone %q", nrt.Name, zoneB, zoneA.Name))
+				continue
+			}
+			if rev != costInfo.Value {
+				errs = append(errs, fmt.Errorf("node %q: asymmetric cost between zones %q and %q: %d vs %d", nrt.Name, zoneA.Name, zoneB, costInfo.Value, rev))
+			}
+		}
+	}
+
+	for _, zoneA := range nrt.Zones {
+		for _, zoneB := range nrt.Zones {
+			costAB, ok := cm.cost(zoneA.Name, zoneB.Name)
+			if !ok || zoneA.Name == zoneB.Name {
+				continue
+			}
+			for _, zoneC := range nrt.Zones {
+				if zoneC.Name == zoneA.Name || zoneC.Name == zoneB.Name {
+					continue
+				}
+				costAC, okAC := cm.cost(zoneA.Name, zoneC.Name)
+				costCB, okCB := cm.cost(zoneC.Name, zoneB.Name)
+				if !okAC || !okCB {
+					continue
+				}
+				if costAB > costAC+costCB {
+					errs = append(errs, fmt.Errorf("node %q: cost between zones %q and %q (%d) exceeds the cost through zone %q (%d)", nrt.Name, zoneA.Name, zoneB.Name, costAB, zoneC.Name, costAC+costCB))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// distanceAwareScore scales the given node score by how close to each other are the NUMA zones the pod would span.
+// Only the restricted policy lets a pod span more than one zone, so the score is returned unchanged for the other policies.
+// Among the narrowest sets of zones which can fit the pod, the closest is picked, and the score is multiplied by the ratio
+// between the local cost (e.g. 10) and the average cost between the zones of the set (e.g. 20 for zones one hop apart).
+// If the pod fits in a single zone, or the costs are not reported, the score is returned unchanged.
+// Like the restricted filter, past maxZones NUMA zones the set is picked by greedyNUMANodes instead of searched.
+func distanceAwareScore(pod *v1.Pod, zones topologyv1alpha1.ZoneList, policy topologyv1alpha1.TopologyManagerPolicy, score int64, maxZones int) int64 {
+	if policy != topologyv1alpha1.RestrictedPodLevel && policy != topologyv1alpha1.RestrictedContainerLevel {
+		return score
+	}
+	cm := costMatrixFromZones(zones)
+	if len(cm) == 0 {
+		return score
+	}
+
+	qos := v1qos.GetPodQOS(pod)
+	nodes := createNUMANodeList(zones)
+	if len(nodes) > maxZones {
+		klog.V(4).InfoS("too many NUMA zones to search exhaustively, falling back to the greedy heuristic", "pod", klog.KObj(pod), "zones", len(nodes), "maxZones", maxZones)
+	}
+	ratio := 1.0
+	if policy == topologyv1alpha1.RestrictedPodLevel {
+		_, ratio = lowestCostSpan(cm, qos, nodes, util.GetPodEffectiveRequest(pod), maxZones)
+	} else {
+		// init containers run serially and before the app containers, so their resources are not accumulated
+		for _, initContainer := range pod.Spec.InitContainers {
+			if _, r := lowestCostSpan(cm, qos, nodes, initContainer.Resources.Requests, maxZones); r < ratio {
+				ratio = r
+			}
+		}
+		for _, container := range pod.Spec.Containers {
+			combination, r := lowestCostSpan(cm, qos, nodes, container.Resources.Requests, maxZones)
+			if r < ratio {
+				ratio = r
+			}
+			subtractFromNUMAs(container.Resources.Requests, nodes, combination...)
+		}
+	}
+
+	finalScore := int64(float64(score) * ratio)
+	klog.V(6).InfoS("distance aware score", "pod", klog.KObj(pod), "score", score, "ratio", ratio, "finalScore", finalScore)
+	return finalScore
+}
+
+// lowestCostSpan returns the indexes (in numaNodes, NOT the NUMA IDs) of the closest zones among the narrowest sets
+// which can fit the given resources, and the ratio between their local cost and their average cost.
+// The ratio is 1 if the resources fit in a single zone, or if the costs between the zones are unknown.
+// The search is exponential in the number of NUMA nodes, so past maxZones NUMA nodes greedyNUMANodes is used instead.
+func lowestCostSpan(cm costMatrix, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList, maxZones int) ([]int, float64) {
+	if len(numaNodes) > maxZones {
+		combination, ok := greedyNUMANodes(numaNodes, resources, qos)
+		if !ok {
+			return nil, 1.0
+		}
+		ratio, ok := spanCostRatio(cm, numaNodes, combination)
+		if !ok {
+			ratio = 1.0
+		}
+		return combination, ratio
+	}
+
+	reported := numaReportedResources(numaNodes)
+	for i := 1; i <= len(numaNodes); i++ {
+		var bestCombination []int
+		bestRatio := 0.0
+		for _, combination := range combin.Combinations(len(numaNodes), i) {
+			if !resourcesFitCombination(qos, resources, combineNUMAResources(numaNodes, reported, combination)) {
+				continue
+			}
+			ratio, ok := spanCostRatio(cm, numaNodes, combination)
+			if !ok {
+				ratio = 1.0
+			}
+			if bestCombination == nil || ratio > bestRatio {
+				bestCombination = combination
+				bestRatio = ratio
+			}
+		}
+		if bestCombination != nil {
+			return bestCombination, bestRatio
+		}
+	}
+	return nil, 1.0
+}
+
+// spanCostRatio returns the ratio between the average local cost of the given zones and the average cost between them,
+// and false if any of the costs is not known. Spanning a single zone has ratio 1.
+func spanCostRatio(cm costMatrix, numaNodes NUMANodeList, combination []int) (float64, bool) {
+	if len(combination) < 2 {
+		return 1.0, true
+	}
+	var localCost, spanCost, pairs int64
+	for i, idxA := range combination {
+		zoneA := numaNodes[idxA].Name
+		cost, ok := cm.cost(zoneA, zoneA)
+		if !ok {
+			return 0, false
+		}
+		localCost += cost
+		for _, idxB := range combination[i+1:] {
+			cost, ok := cm.cost(zoneA, numaNodes[idxB].Name)
+			if !ok {
+				return 0, false
+			}
+			spanCost += cost
+			pairs++
+		}
+	}
+	if localCost <= 0 || spanCost <= 0 {
+		return 0, false
+	}
+	avgLocal := float64(localCost) / float64(len(combination))
+	avgSpan := float64(spanCost) / float64(pairs)
+	if avgSpan <= avgLocal {
+		return 1.0, true
+	}
+	return avgLocal / avgSpan, true
+}
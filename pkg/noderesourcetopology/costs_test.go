@@ -0,0 +1,285 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func makeCostsNRT(costs map[string]map[string]int64, zoneNames ...string) *topologyv1alpha1.NodeResourceTopology {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+		TopologyPolicies: []string{string(topologyv1alpha1.RestrictedPodLevel)},
+	}
+	for _, zoneName := range zoneNames {
+		zone := topologyv1alpha1.Zone{
+			Name: zoneName,
+			Type: "Node",
+		}
+		for _, to := range zoneNames {
+			val, ok := costs[zoneName][to]
+			if !ok {
+				continue
+			}
+			zone.Costs = append(zone.Costs, topologyv1alpha1.CostInfo{Name: to, Value: val})
+		}
+		nrt.Zones = append(nrt.Zones, zone)
+	}
+	return nrt
+}
+
+func TestValidateCostMatrix(t *testing.T) {
+	tcases := []struct {
+		description string
+		nrt         *topologyv1alpha1.NodeResourceTopology
+		expectedErr int
+	}{
+		{
+			description: "nil object",
+			nrt:         nil,
+			expectedErr: 0,
+		},
+		{
+			description: "no costs reported",
+			nrt:         makeCostsNRT(nil, "node-0", "node-1"),
+			expectedErr: 0,
+		},
+		{
+			description: "valid matrix",
+			nrt: makeCostsNRT(map[string]map[string]int64{
+				"node-0": {"node-0": 10, "node-1": 20, "node-2": 20},
+				"node-1": {"node-0": 20, "node-1": 10, "node-2": 20},
+				"node-2": {"node-0": 20, "node-1": 20, "node-2": 10},
+			}, "node-0", "node-1", "node-2"),
+			expectedErr: 0,
+		},
+		{
+			description: "asymmetric matrix",
+			nrt: makeCostsNRT(map[string]map[string]int64{
+				"node-0": {"node-0": 10, "node-1": 20},
+				"node-1": {"node-0": 21, "node-1": 10},
+			}, "node-0", "node-1"),
+			// reported once per direction
+			expectedErr: 2,
+		},
+		{
+			description: "triangle inequality violated",
+			nrt: makeCostsNRT(map[string]map[string]int64{
+				"node-0": {"node-0": 10, "node-1": 12, "node-2": 40},
+				"node-1": {"node-0": 12, "node-1": 10, "node-2": 12},
+				"node-2": {"node-0": 40, "node-1": 12, "node-2": 10},
+			}, "node-0", "node-1", "node-2"),
+			// node-0 -> node-2 and node-2 -> node-0, both through node-1
+			expectedErr: 2,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.description, func(t *testing.T) {
+			errs := ValidateCostMatrix(tcase.nrt)
+			if len(errs) != tcase.expectedErr {
+				t.Errorf("expected %d errors, got %d: %v", tcase.expectedErr, len(errs), errs)
+			}
+		})
+	}
+}
+
+func makeCostsNRTWithResources(nodeName string, policy topologyv1alpha1.TopologyManagerPolicy, costs map[string]map[string]int64, zoneNames ...string) *topologyv1alpha1.NodeResourceTopology {
+	nrt := makeCostsNRT(costs, zoneNames...)
+	nrt.Name = nodeName
+	nrt.TopologyPolicies = []string{string(policy)}
+	for idx := range nrt.Zones {
+		nrt.Zones[idx].Resources = topologyv1alpha1.ResourceInfoList{
+			MakeTopologyResInfo(cpu, "4", "4"),
+			MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+		}
+	}
+	return nrt
+}
+
+func TestDistanceAwareScore(t *testing.T) {
+	costs := map[string]map[string]int64{
+		"node-0": {"node-0": 10, "node-1": 20, "node-2": 40},
+		"node-1": {"node-0": 20, "node-1": 10, "node-2": 40},
+		"node-2": {"node-0": 40, "node-1": 40, "node-2": 10},
+	}
+
+	tests := []struct {
+		name     string
+		policy   topologyv1alpha1.TopologyManagerPolicy
+		costs    map[string]map[string]int64
+		cpu      string
+		expected int64
+	}{
+		{
+			name:     "single-numa-node policy",
+			policy:   topologyv1alpha1.SingleNUMANodePodLevel,
+			costs:    costs,
+			cpu:      "6",
+			expected: 80,
+		},
+		{
+			name:     "no costs reported",
+			policy:   topologyv1alpha1.RestrictedPodLevel,
+			cpu:      "6",
+			expected: 80,
+		},
+		{
+			name:     "pod fits one zone",
+			policy:   topologyv1alpha1.RestrictedPodLevel,
+			costs:    costs,
+			cpu:      "2",
+			expected: 80,
+		},
+		{
+			name:     "pod spans the closest zones",
+			policy:   topologyv1alpha1.RestrictedPodLevel,
+			costs:    costs,
+			cpu:      "6",
+			expected: 40,
+		},
+		{
+			name:     "pod spans the closest zones, container scope",
+			policy:   topologyv1alpha1.RestrictedContainerLevel,
+			costs:    costs,
+			cpu:      "6",
+			expected: 40,
+		},
+		{
+			name:   "pod spans all the zones",
+			policy: topologyv1alpha1.RestrictedPodLevel,
+			costs:  costs,
+			cpu:    "10",
+			// average span cost (20+40+40)/3
+			expected: 24,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrt := makeCostsNRTWithResources("node1", tt.policy, tt.costs, "node-0", "node-1", "node-2")
+			pod := makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(tt.cpu),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			})
+			got := distanceAwareScore(pod, nrt.Zones, tt.policy, 80, defaultMaxZonesForSubsetSearch)
+			if got != tt.expected {
+				t.Errorf("wrong score: got %d expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDistanceAwareScoreZoneNames(t *testing.T) {
+	// the zone names don't match the "node-<NUMA ID>" form, so they can't be rebuilt from the NUMA IDs
+	costs := map[string]map[string]int64{
+		"node-00": {"node-00": 10, "node-01": 20},
+		"node-01": {"node-00": 20, "node-01": 10},
+	}
+	nrt := makeCostsNRTWithResources("node1", topologyv1alpha1.RestrictedPodLevel, costs, "node-00", "node-01")
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("6"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+	if got := distanceAwareScore(pod, nrt.Zones, topologyv1alpha1.RestrictedPodLevel, 80, defaultMaxZonesForSubsetSearch); got != 40 {
+		t.Errorf("wrong score: got %d expected %d", got, 40)
+	}
+}
+
+func TestDistanceAwareScoreManyZones(t *testing.T) {
+	// way past what an exhaustive search can handle: the narrowest set is 5 zones out of 40
+	var zoneNames []string
+	for i := 0; i < 40; i++ {
+		zoneNames = append(zoneNames, fmt.Sprintf("node-%d", i))
+	}
+	costs := make(map[string]map[string]int64)
+	for _, from := range zoneNames {
+		costs[from] = make(map[string]int64)
+		for _, to := range zoneNames {
+			costs[from][to] = 20
+		}
+		costs[from][from] = 10
+	}
+	nrt := makeCostsNRTWithResources("node1", topologyv1alpha1.RestrictedPodLevel, costs, zoneNames...)
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("20"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+
+	start := time.Now()
+	got := distanceAwareScore(pod, nrt.Zones, topologyv1alpha1.RestrictedPodLevel, 80, defaultMaxZonesForSubsetSearch)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("scoring took too long: %v", elapsed)
+	}
+	if got != 40 {
+		t.Errorf("wrong score: got %d expected %d", got, 40)
+	}
+}
+
+func TestScorePrefersCloseZonesForSpanningPod(t *testing.T) {
+	nearNRT := makeCostsNRTWithResources("near-node", topologyv1alpha1.RestrictedPodLevel, map[string]map[string]int64{
+		"node-0": {"node-0": 10, "node-1": 12},
+		"node-1": {"node-0": 12, "node-1": 10},
+	}, "node-0", "node-1")
+	farNRT := makeCostsNRTWithResources("far-node", topologyv1alpha1.RestrictedPodLevel, map[string]map[string]int64{
+		"node-0": {"node-0": 10, "node-1": 32},
+		"node-1": {"node-0": 32, "node-1": 10},
+	}, "node-0", "node-1")
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nearNRT)
+	fakeInformer.Informer().GetStore().Add(farNRT)
+
+	tm := TopologyMatch{
+		scoringHandlers:         leastNUMAscoreHandlers(),
+		nrtCache:                nrtcache.NewPassthrough(fakeInformer.Lister()),
+		maxZonesForSubsetSearch: defaultMaxZonesForSubsetSearch,
+	}
+
+	// the pod cannot fit a single zone, so it must span both on either node
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("6"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})
+
+	nearScore, status := tm.Score(context.Background(), framework.NewCycleState(), pod, nearNRT.Name)
+	if status != nil {
+		t.Fatalf("unexpected status scoring %q: %v", nearNRT.Name, status)
+	}
+	farScore, status := tm.Score(context.Background(), framework.NewCycleState(), pod, farNRT.Name)
+	if status != nil {
+		t.Fatalf("unexpected status scoring %q: %v", farNRT.Name, status)
+	}
+	if farScore >= nearScore {
+		t.Errorf("node with far apart zones scored %d, not lower than node with close zones scored %d", farScore, nearScore)
+	}
+}
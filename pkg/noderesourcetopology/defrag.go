@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// PodPlacement tells on which NUMA zone of a node a pod is running.
+type PodPlacement struct {
+	Pod  *v1.Pod
+	Zone string
+}
+
+// MoveSuggestion suggests to move a pod from a NUMA zone to another NUMA zone of the same node.
+type MoveSuggestion struct {
+	Pod      *v1.Pod
+	FromZone string
+	ToZone   string
+}
+
+// DefragmentationPlan suggests the pod moves between the NUMA zones of a node which free a whole zone, consolidating
+// the free capacity. The plan empties the zone requiring the least moves whose pods all fit in the available resources
+// of the other zones. Returns nil if a zone is already free or if no zone can be freed.
+// Meant to be consumed by descheduling tools: the pods are not moved, and the scheduler takes no action.
+func DefragmentationPlan(nrt *topologyv1alpha1.NodeResourceTopology, pods []PodPlacement) []MoveSuggestion {
+	podsByZone := make(map[string][]*v1.Pod)
+	for _, placement := range pods {
+		podsByZone[placement.Zone] = append(podsByZone[placement.Zone], placement.Pod)
+	}
+
+	var zoneNames []string
+	for _, zone := range nrt.Zones {
+		if zone.Type != nrtcache.ZoneTypeNUMANode {
+			continue
+		}
+		if len(podsByZone[zone.Name]) == 0 {
+			klog.V(5).InfoS("free zone found, nothing to defragment", "node", nrt.Name, "zone", zone.Name)
+			return nil
+		}
+		zoneNames = append(zoneNames, zone.Name)
+	}
+
+	// try first the zones which need the least moves to be freed
+	sort.SliceStable(zoneNames, func(i, j int) bool {
+		return len(podsByZone[zoneNames[i]]) < len(podsByZone[zoneNames[j]])
+	})
+
+	for _, candidate := range zoneNames {
+		moves, ok := planZoneEviction(nrt, candidate, podsByZone[candidate])
+		if ok {
+			klog.V(4).InfoS("defragmentation plan", "node", nrt.Name, "zone", candidate, "moves", len(moves))
+			return moves
+		}
+	}
+	klog.V(5).InfoS("no zone can be freed", "node", nrt.Name)
+	return nil
+}
+
+// planZoneEviction computes the moves to relocate all the given pods from the candidate zone to the other zones,
+// placing each pod on the first zone with enough available resources. Returns false if any pod can't be relocated.
+func planZoneEviction(nrt *topologyv1alpha1.NodeResourceTopology, candidate string, pods []*v1.Pod) ([]MoveSuggestion, bool) {
+	type zoneAvailable struct {
+		name      string
+		resources v1.ResourceList
+	}
+	var targets []zoneAvailable
+	for _, zone := range nrt.Zones {
+		if zone.Type != nrtcache.ZoneTypeNUMANode || zone.Name == candidate {
+			continue
+		}
+		targets = append(targets, zoneAvailable{name: zone.Name, resources: extractResources(zone)})
+	}
+
+	var moves []MoveSuggestion
+	for _, pod := range pods {
+		resources := util.GetPodEffectiveRequest(pod)
+		placed := false
+		for _, target := range targets {
+			if !resourcesFitCombination(v1.PodQOSGuaranteed, resources, target.resources) {
+				continue
+			}
+			for name, qty := range resources {
+				if available, ok := target.resources[name]; ok {
+					available.Sub(qty)
+					target.resources[name] = available
+				}
+			}
+			moves = append(moves, MoveSuggestion{Pod: pod, FromZone: candidate, ToZone: target.name})
+			placed = true
+			break
+		}
+		if !placed {
+			return nil, false
+		}
+	}
+	return moves, true
+}
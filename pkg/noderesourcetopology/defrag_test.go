@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"testing"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefragmentationPlan(t *testing.T) {
+	makeNRT := func(zone0CPU, zone1CPU string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", zone0CPU),
+						MakeTopologyResInfo(memory, "16Gi", "12Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", zone1CPU),
+						MakeTopologyResInfo(memory, "16Gi", "8Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	makePodWithCPU := func(name, cpus string) *v1.Pod {
+		pod := makePodByResourceList(&v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpus),
+			v1.ResourceMemory: resource.MustParse("2Gi"),
+		})
+		pod.Name = name
+		return pod
+	}
+
+	smallPod := makePodWithCPU("small", "2")
+	bigPod := makePodWithCPU("big", "4")
+
+	t.Run("moving one pod frees a zone", func(t *testing.T) {
+		nrt := makeNRT("6", "4")
+		moves := DefragmentationPlan(nrt, []PodPlacement{
+			{Pod: smallPod, Zone: "node-0"},
+			{Pod: bigPod, Zone: "node-1"},
+		})
+		if len(moves) != 1 {
+			t.Fatalf("unexpected moves: %+v", moves)
+		}
+		if moves[0].Pod != smallPod || moves[0].FromZone != "node-0" || moves[0].ToZone != "node-1" {
+			t.Errorf("unexpected move: pod %q from %q to %q", moves[0].Pod.Name, moves[0].FromZone, moves[0].ToZone)
+		}
+	})
+
+	t.Run("a zone is already free", func(t *testing.T) {
+		nrt := makeNRT("6", "8")
+		moves := DefragmentationPlan(nrt, []PodPlacement{
+			{Pod: smallPod, Zone: "node-0"},
+		})
+		if len(moves) != 0 {
+			t.Errorf("unexpected moves: %+v", moves)
+		}
+	})
+
+	t.Run("no zone can be freed", func(t *testing.T) {
+		nrt := makeNRT("2", "1")
+		moves := DefragmentationPlan(nrt, []PodPlacement{
+			{Pod: smallPod, Zone: "node-0"},
+			{Pod: makePodWithCPU("other", "4"), Zone: "node-0"},
+			{Pod: bigPod, Zone: "node-1"},
+		})
+		if len(moves) != 0 {
+			t.Errorf("unexpected moves: %+v", moves)
+		}
+	})
+}
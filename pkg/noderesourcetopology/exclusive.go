@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// exclusiveZoneHandler admits pods requesting an exclusive NUMA zone only if they fit in a zone not used by any other pod.
+// The kubelet is not aware of exclusive zones, so this check is done regardless of the Topology Manager policy.
+// A pod fitting in a single zone satisfies all the policies anyway.
+func exclusiveZoneHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+	klog.V(5).InfoS("Exclusive zone handler")
+
+	resources := util.GetPodEffectiveRequest(pod)
+	qos := v1qos.GetPodQOS(pod)
+
+	logID := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	nodes := createNUMANodeList(unusedZones(zones))
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes("exclusive zone handler unused NUMA resources", nodeInfo.Node().Name, nodes)
+	klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, resources)...)
+
+	for _, numaNode := range nodes {
+		if resourcesFitCombination(qos, resources, numaNode.Resources) {
+			klog.V(5).InfoS("final verdict", "logID", logID, "node", nodeInfo.Node().Name, "NUMAID", numaNode.NUMAID, "suitable", true)
+			return nil
+		}
+	}
+	klog.V(5).InfoS("final verdict", "logID", logID, "node", nodeInfo.Node().Name, "suitable", false)
+	return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot find an exclusive zone for pod: %s", pod.Name))
+}
+
+// unusedZones returns the zones not used by any pod, which are the only candidates for pods requesting an exclusive zone.
+func unusedZones(zones topologyv1alpha1.ZoneList) topologyv1alpha1.ZoneList {
+	var unused topologyv1alpha1.ZoneList
+	for _, zone := range zones {
+		if nrtcache.IsZoneUnused(zone) {
+			unused = append(unused, zone)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+func TestExclusiveZoneFilterAndScore(t *testing.T) {
+	makeNRT := func(name, secondZoneCPUAvailable string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: name},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", "6"),
+						MakeTopologyResInfo(memory, "16Gi", "14Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", secondZoneCPUAvailable),
+						MakeTopologyResInfo(memory, "16Gi", "16Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	makeExclusivePod := func(exclusive bool) *v1.Pod {
+		pod := makePodByResourceList(&v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+		})
+		pod.Name = "testpod"
+		if exclusive {
+			pod.Annotations = map[string]string{
+				nrtcache.AnnotationExclusiveZone: "true",
+			}
+		}
+		return pod
+	}
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		exclusive  bool
+		wantStatus *framework.Status
+		wantScore  bool
+	}{
+		{
+			name:       "exclusive pod skips the partially used zone and claims the empty one",
+			nrt:        makeNRT("mixed", "8"),
+			exclusive:  true,
+			wantStatus: nil,
+			wantScore:  true,
+		},
+		{
+			name:       "exclusive pod cannot fit on partially used zones",
+			nrt:        makeNRT("used", "7"),
+			exclusive:  true,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot find an exclusive zone for pod: testpod"),
+			wantScore:  false,
+		},
+		{
+			name:       "regular pod fits on partially used zones",
+			nrt:        makeNRT("used", "7"),
+			exclusive:  false,
+			wantStatus: nil,
+			wantScore:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(tt.nrt)
+
+			tm := TopologyMatch{
+				filterHandlers:  newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				scoringHandlers: newScoringHandlers(leastAllocatedScoreStrategy, nil),
+				nrtCache:        nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			pod := makeExclusivePod(tt.exclusive)
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+
+			score, status := tm.Score(context.Background(), framework.NewCycleState(), pod, tt.nrt.Name)
+			if status != nil {
+				t.Fatalf("unexpected score status: %v", status)
+			}
+			if gotScore := score > 0; gotScore != tt.wantScore {
+				t.Errorf("unexpected score %d", score)
+			}
+		})
+	}
+}
@@ -18,7 +18,9 @@ package noderesourcetopology
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -29,6 +31,8 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
@@ -37,6 +41,13 @@ import (
 // https://kubernetes.io/docs/tasks/administer-cluster/topology-manager/#known-limitations
 const highestNUMAID = 8
 
+var (
+	// ErrNRTNotFound signals there is no NodeResourceTopology data for the node, so its NUMA zones can't be evaluated.
+	ErrNRTNotFound = errors.New("no NodeResourceTopology data")
+	// ErrNoNUMAZoneFit signals the pod can't be aligned to the NUMA zones of the node as its policy requires.
+	ErrNoNUMAZoneFit = errors.New("cannot fit the NUMA zones")
+)
+
 type PolicyHandler func(pod *v1.Pod, zoneMap topologyv1alpha1.ZoneList) *framework.Status
 
 func singleNUMAContainerLevelHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
@@ -187,24 +198,43 @@ func singleNUMAPodLevelHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nod
 	return nil
 }
 
-// Filter Now only single-numa-node supported
+// Filter Now single-numa-node and restricted policies are supported
 func (tm *TopologyMatch) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
 	if nodeInfo.Node() == nil {
 		return framework.NewStatus(framework.Error, "node not found")
 	}
-	if v1qos.GetPodQOS(pod) == v1.PodQOSBestEffort && !hasNonNativeResource(pod) {
+	state := tm.preFilterStateFor(cycleState, pod)
+	if state.skip {
 		return nil
 	}
+	pod = state.pod
+	if err := validateIntegerResources(pod); err != nil {
+		// no node can ever satisfy the request, and accounting it would leave fractional devices available
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
 
 	nodeName := nodeInfo.Node().Name
+	defer tm.evalLatency.Track(nodeName)()
+
 	nodeTopology, ok := tm.nrtCache.GetCachedNRTCopy(nodeName, pod)
 
 	if !ok {
 		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("invalid node topology data for node %s", nodeName))
 	}
+	return filterStatus(tm.evaluateFit(cycleState, pod, nodeInfo, nodeTopology), tm.missingNRTPolicy)
+}
+
+// evaluateFit checks if the given pod fits the NUMA zones of the node described by the given NRT data, according to
+// the policy of the node. Returns ErrNRTNotFound if there is no NRT data, or an error matching ErrNoNUMAZoneFit if the pod
+// can't be aligned.
+func (tm *TopologyMatch) evaluateFit(cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo, nodeTopology *topologyv1alpha1.NodeResourceTopology) error {
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	nodeName := nodeInfo.Node().Name
 	if nodeTopology == nil {
-		return nil
+		return fmt.Errorf("%w for node %s", ErrNRTNotFound, nodeName)
 	}
+	// the handlers never change the data, so the Score phase can reuse it
+	writeNodeTopologyState(cycleState, nodeName, nodeTopology)
 
 	klog.V(5).InfoS("Found NodeResourceTopology", "nodeTopology", klog.KObj(nodeTopology))
 	if len(nodeTopology.TopologyPolicies) == 0 {
@@ -212,17 +242,108 @@ func (tm *TopologyMatch) Filter(ctx context.Context, cycleState *framework.Cycle
 		return nil
 	}
 
+	if nrtcache.IsExclusiveZonePod(pod) {
+		status := exclusiveZoneHandler(pod, nodeTopology.Zones, nodeInfo)
+		if status != nil {
+			tm.nrtCache.NodeMaybeOverReserved(nodeName, pod)
+		}
+		return asFitError(status)
+	}
+
+	if names := alignedContainerNames(pod); names.Len() > 0 {
+		// checked on top of the policy of the node, which may let the containers span zones
+		status := alignedContainersHandler(pod, names, nodeTopology.Zones, nodeInfo)
+		if status != nil {
+			tm.nrtCache.NodeMaybeOverReserved(nodeName, pod)
+			return asFitError(status)
+		}
+	}
+
+	if requestsPCIeBandwidth(pod) {
+		// the bandwidth is usable only from the zone of the devices, whatever the policy of the node
+		status := pcieBandwidthHandler(pod, nodeTopology.Zones, nodeInfo)
+		if status != nil {
+			tm.nrtCache.NodeMaybeOverReserved(nodeName, pod)
+			return asFitError(status)
+		}
+	}
+
 	policyName := nodeTopology.TopologyPolicies[0]
+	if isBestEffortPolicy(topologyv1alpha1.TopologyManagerPolicy(policyName)) {
+		// the kubelet won't enforce any alignment, so the pod is never rejected. Scoring still applies.
+		klog.V(5).InfoS("best-effort policy, nothing to filter", "node", nodeName, "policy", policyName)
+		return nil
+	}
+
 	handler, ok := tm.filterHandlers[topologyv1alpha1.TopologyManagerPolicy(policyName)]
 	if !ok {
 		klog.V(4).InfoS("Policy handler not found", "policy", policyName)
 		return nil
 	}
-	status := handler(pod, nodeTopology.Zones, nodeInfo)
+	if isColocatedContainersPod(pod) {
+		handler = colocatedContainersHandler
+	} else if isSpreadContainersPod(pod) && topologyv1alpha1.TopologyManagerPolicy(policyName) != topologyv1alpha1.SingleNUMANodePodLevel {
+		// with the pod scope the kubelet allocates the whole pod from one zone, so the containers can't be spread
+		handler = spreadContainersHandler
+	}
+	zones := nodeTopology.Zones
+	if tm.spillableResources.Len() > 0 && podUsesAnyResource(pod, tm.spillableResources) {
+		if status := spillableResourcesHandler(pod, tm.spillableResources, zones); status != nil {
+			tm.nrtCache.NodeMaybeOverReserved(nodeName, pod)
+			return asFitError(status)
+		}
+		// nodeTopology is shared with the Score phase, so it must not change
+		zones = withSpilledZones(zones, tm.spillableResources)
+	}
+	if isRoundedPolicy(topologyv1alpha1.TopologyManagerPolicy(policyName)) {
+		pod = withRoundedRequests(pod, tm.roundingPolicies)
+	}
+	status := handler(pod, zones, nodeInfo)
 	if status != nil {
 		tm.nrtCache.NodeMaybeOverReserved(nodeName, pod)
 	}
-	return status
+	return asFitError(status)
+}
+
+// numaFitError carries the status of a handler rejecting the pod, so it can be rebuilt unchanged by filterStatus.
+type numaFitError struct {
+	code    framework.Code
+	reasons []string
+}
+
+func (e *numaFitError) Error() string {
+	return strings.Join(e.reasons, ", ")
+}
+
+func (e *numaFitError) Is(target error) bool {
+	return target == ErrNoNUMAZoneFit
+}
+
+// asFitError returns the error matching ErrNoNUMAZoneFit which carries the given handler status, or nil on success.
+func asFitError(status *framework.Status) error {
+	if status.IsSuccess() {
+		return nil
+	}
+	return &numaFitError{
+		code:    status.Code(),
+		reasons: status.Reasons(),
+	}
+}
+
+// filterStatus maps the errors of the fit evaluation to the filter status. Nodes without NRT data are handled according
+// to the given policy, while the status of the handler rejecting the pod is kept as-is.
+func filterStatus(err error, missingNRTPolicy apiconfig.MissingNRTPolicy) *framework.Status {
+	if err == nil {
+		return nil
+	}
+	var fitErr *numaFitError
+	if errors.As(err, &fitErr) {
+		return framework.NewStatus(fitErr.code, fitErr.reasons...)
+	}
+	if errors.Is(err, ErrNRTNotFound) {
+		return missingNRTStatus(missingNRTPolicy, err)
+	}
+	return framework.AsStatus(err)
 }
 
 // subtractFromNUMA finds the correct NUMA ID's resources and subtract them from `nodes`.
@@ -247,6 +368,10 @@ func subtractFromNUMA(nodes NUMANodeList, numaID int, container v1.Container) {
 	}
 }
 
+func isBestEffortPolicy(policy topologyv1alpha1.TopologyManagerPolicy) bool {
+	return policy == topologyv1alpha1.BestEffort || policy == topologyv1alpha1.BestEffortPodLevel || policy == topologyv1alpha1.BestEffortContainerLevel
+}
+
 func hasNonNativeResource(pod *v1.Pod) bool {
 	for _, initContainer := range pod.Spec.InitContainers {
 		for resource := range initContainer.Resources.Requests {
@@ -266,3 +391,24 @@ func hasNonNativeResource(pod *v1.Pod) bool {
 	}
 	return false
 }
+
+// validateIntegerResources returns an error if any container of the pod requests (or limits) a fractional
+// quantity of a non-native resource. Non-native resources, like devices, are allocated only in whole units.
+func validateIntegerResources(pod *v1.Pod) error {
+	containers := make([]v1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, resources := range []v1.ResourceList{container.Resources.Requests, container.Resources.Limits} {
+			for resource, quantity := range resources {
+				if v1helper.IsNativeResource(resource) {
+					continue
+				}
+				if quantity.MilliValue()%1000 != 0 {
+					return fmt.Errorf("fractional quantity %s of resource %s in container %s", quantity.String(), resource, container.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package noderesourcetopology
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
@@ -670,7 +672,7 @@ func TestNodeResourceTopology(t *testing.T) {
 	}
 
 	tm := TopologyMatch{
-		filterHandlers: newFilterHandlers(),
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
 		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
 	}
 
@@ -886,7 +888,7 @@ func TestNodeResourceTopologyMultiContainerPodScope(t *testing.T) {
 			}
 
 			tm := TopologyMatch{
-				filterHandlers: newFilterHandlers(),
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
 				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
 			}
 
@@ -1144,7 +1146,7 @@ func TestNodeResourceTopologyMultiContainerContainerScope(t *testing.T) {
 			}
 
 			tm := TopologyMatch{
-				filterHandlers: newFilterHandlers(),
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
 				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
 			}
 
@@ -1266,3 +1268,557 @@ func parseState(error string) *framework.Status {
 
 	return framework.NewStatus(framework.Unschedulable, error)
 }
+
+func TestNodeResourceTopologyContainerScopeVsPodScope(t *testing.T) {
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	// each container fits a zone on its own, but no zone can hold both
+	pod := makePod("testpod",
+		withMultiContainers([]v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		}),
+	)
+
+	tests := []struct {
+		name       string
+		policy     topologyv1alpha1.TopologyManagerPolicy
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "container scope, containers spread across NUMAs - fit",
+			policy:     topologyv1alpha1.SingleNUMANodeContainerLevel,
+			wantStatus: nil,
+		},
+		{
+			name:       "pod scope, whole pod does not fit a single NUMA - not fit",
+			policy:     topologyv1alpha1.SingleNUMANodePodLevel,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrt := makeNRT(tt.policy)
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNodeResourceTopologyMixedScopesSameCycle(t *testing.T) {
+	makeNRT := func(name string, policy topologyv1alpha1.TopologyManagerPolicy) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: name},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	// the kubelets of the nodes run different topology manager scopes
+	nrts := []*topologyv1alpha1.NodeResourceTopology{
+		makeNRT("container-scope-node", topologyv1alpha1.SingleNUMANodeContainerLevel),
+		makeNRT("pod-scope-node", topologyv1alpha1.SingleNUMANodePodLevel),
+	}
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	for _, nrt := range nrts {
+		fakeInformer.Informer().GetStore().Add(nrt)
+	}
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	// each container fits a zone on its own, but no zone can hold both
+	pod := makePod("testpod",
+		withMultiContainers([]v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		}),
+	)
+
+	expected := map[string]*framework.Status{
+		"container-scope-node": nil,
+		"pod-scope-node":       framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+	}
+
+	// the same cycle evaluates all the nodes, each with the fit check of its own scope
+	cycleState := framework.NewCycleState()
+	if _, status := tm.PreFilter(context.Background(), cycleState, pod); !status.IsSuccess() {
+		t.Fatalf("unexpected prefilter status: %v", status)
+	}
+	for _, nrt := range nrts {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+		gotStatus := tm.Filter(context.Background(), cycleState, pod, nodeInfo)
+		if !reflect.DeepEqual(gotStatus, expected[nrt.Name]) {
+			t.Errorf("node %q: status does not match: %v, want: %v", nrt.Name, gotStatus, expected[nrt.Name])
+		}
+	}
+}
+
+func TestNodeResourceTopologyPodScopeSameDevice(t *testing.T) {
+	makeNRT := func(nics string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+						MakeTopologyResInfo(nicResourceName, "8", "3"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+						MakeTopologyResInfo(nicResourceName, "8", nics),
+					},
+				},
+			},
+		}
+	}
+
+	// each container fits any zone on its own, but the devices of both must come from the same zone
+	pod := makePod("testpod",
+		withMultiContainers([]v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse("1"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+				nicResourceName:   resource.MustParse("2"),
+			},
+			{
+				v1.ResourceCPU:    resource.MustParse("1"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+				nicResourceName:   resource.MustParse("2"),
+			},
+		}),
+	)
+
+	tests := []struct {
+		name       string
+		nics       string
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "one zone has enough devices for both containers - fit",
+			nics:       "8",
+			wantStatus: nil,
+		},
+		{
+			name:       "no zone has enough devices for both containers - not fit",
+			nics:       "3",
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrt := makeNRT(tt.nics)
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNodeResourceTopologySimilarDeviceNames(t *testing.T) {
+	// devices advertised per slot, with names differing only by suffix
+	nicSlotName := v1.ResourceName(nicResourceName + ".1")
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "4", "4"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					MakeTopologyResInfo(nicResourceName, "8", "8"),
+					MakeTopologyResInfo(string(nicSlotName), "2", "1"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		nics       string
+		slotNics   string
+		wantStatus *framework.Status
+	}{
+		{
+			name:     "both fit on their own - fit",
+			nics:     "2",
+			slotNics: "1",
+		},
+		{
+			name:       "slot devices exhausted, plenty of the similarly named ones - not fit",
+			nics:       "2",
+			slotNics:   "2",
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+		{
+			name:       "the similarly named devices don't add up - not fit",
+			nics:       "9",
+			slotNics:   "0",
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			pod := makePod("testpod",
+				withMultiContainers([]v1.ResourceList{
+					{
+						v1.ResourceCPU:    resource.MustParse("1"),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+						nicResourceName:   resource.MustParse(tt.nics),
+						nicSlotName:       resource.MustParse(tt.slotNics),
+					},
+				}),
+			)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNodeResourceTopologyIgnoresSocketZones(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "socket-0",
+				Type: "Socket",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "16Gi", "16Gi"),
+				},
+			},
+			{
+				Name:   "node-0",
+				Type:   "Node",
+				Parent: "socket-0",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "4", "4"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+			{
+				Name:   "node-1",
+				Type:   "Node",
+				Parent: "socket-0",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "4", "4"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nrt)
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+
+	// the socket could fit the pod, but the alignment is checked only against the NUMA nodes
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("6"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})
+	pod.Name = "testpod"
+	wantStatus := framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod")
+	if gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo); !reflect.DeepEqual(gotStatus, wantStatus) {
+		t.Errorf("status does not match: %v, want: %v", gotStatus, wantStatus)
+	}
+
+	pod = makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})
+	pod.Name = "testpod"
+	if gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo); gotStatus != nil {
+		t.Errorf("status does not match: %v, want: nil", gotStatus)
+	}
+}
+
+func TestEvaluateFitErrors(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "4", "4"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nrt)
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("6"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})
+	pod.Name = "testpod"
+
+	tests := []struct {
+		name       string
+		node       *v1.Node
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		wantErr    error
+		wantStatus *framework.Status
+	}{
+		{
+			name:    "missing node topology data",
+			node:    &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "host1"}},
+			wantErr: ErrNRTNotFound,
+		},
+		{
+			name:       "overfull node",
+			node:       makeNodeFromNodeResourceTopology(nrt),
+			nrt:        nrt,
+			wantErr:    ErrNoNUMAZoneFit,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(tt.node)
+
+			err := tm.evaluateFit(framework.NewCycleState(), pod, nodeInfo, tt.nrt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: %v, want: %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNRTNotFound) && errors.Is(err, ErrNoNUMAZoneFit) {
+				t.Errorf("error matches both the missing data and the no fit errors: %v", err)
+			}
+
+			if gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo); !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNodeResourceTopologyFractionalDevices(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "4", "4"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					MakeTopologyResInfo(nicResourceName, "4", "4"),
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nrt)
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+		nicResourceName:   resource.MustParse("500m"),
+	})
+	pod.Spec.Containers[0].Name = "cnt-0"
+	gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+	wantStatus := framework.NewStatus(framework.UnschedulableAndUnresolvable, "fractional quantity 500m of resource "+nicResourceName+" in container cnt-0")
+	if !reflect.DeepEqual(gotStatus, wantStatus) {
+		t.Errorf("status does not match: %v, want: %v", gotStatus, wantStatus)
+	}
+
+	pod = makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("500m"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+		nicResourceName:   resource.MustParse("1"),
+	})
+	if gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo); gotStatus != nil {
+		t.Errorf("status does not match: %v, want: nil", gotStatus)
+	}
+}
+
+func TestNodeResourceTopologyIgnoredResources(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "4", "4"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nrt)
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:              resource.MustParse("2"),
+		v1.ResourceMemory:           resource.MustParse("2Gi"),
+		v1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+	})
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+	if gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo); gotStatus == nil {
+		t.Errorf("pod requesting a resource not reported by the node admitted")
+	}
+
+	tm.ignoredResources = sets.NewString(string(v1.ResourceEphemeralStorage), string(v1.ResourcePods))
+	if gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo); gotStatus != nil {
+		t.Errorf("status does not match: %v, want: nil", gotStatus)
+	}
+	if _, ok := pod.Spec.Containers[0].Resources.Requests[v1.ResourceEphemeralStorage]; !ok {
+		t.Errorf("filtering modified the pod")
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// stateData holds the NodeResourceTopology data of a node, as computed in the Filter phase, so the Score phase
+// of the same scheduling cycle can reuse it instead of computing it again. Filter runs concurrently on many nodes,
+// so each node has its own key in the CycleState. The data is never changed once written.
+type stateData struct {
+	nodeTopology *topologyv1alpha1.NodeResourceTopology
+}
+
+// Clone returns the same data, which is immutable.
+func (sd *stateData) Clone() framework.StateData {
+	return sd
+}
+
+func stateKeyForNode(nodeName string) framework.StateKey {
+	return framework.StateKey(Name + "/" + nodeName)
+}
+
+func writeNodeTopologyState(state *framework.CycleState, nodeName string, nodeTopology *topologyv1alpha1.NodeResourceTopology) {
+	if state == nil {
+		return
+	}
+	state.Write(stateKeyForNode(nodeName), &stateData{nodeTopology: nodeTopology})
+}
+
+func readNodeTopologyState(state *framework.CycleState, nodeName string) (*topologyv1alpha1.NodeResourceTopology, bool) {
+	if state == nil {
+		return nil, false
+	}
+	data, err := state.Read(stateKeyForNode(nodeName))
+	if err != nil {
+		return nil, false
+	}
+	sd, ok := data.(*stateData)
+	if !ok || sd.nodeTopology == nil {
+		return nil, false
+	}
+	return sd.nodeTopology, true
+}
+
+// nodeTopologyForScore returns the NodeResourceTopology data computed in the Filter phase for the given node,
+// falling back to the cache if the data is missing, like when the Filter phase didn't run for the node.
+func (tm *TopologyMatch) nodeTopologyForScore(state *framework.CycleState, nodeName string, pod *v1.Pod) (*topologyv1alpha1.NodeResourceTopology, bool) {
+	if nodeTopology, ok := readNodeTopologyState(state, nodeName); ok {
+		klog.V(6).InfoS("reusing noderesourcetopology from filter", "logID", klog.KObj(pod), "node", nodeName)
+		return nodeTopology, true
+	}
+	return tm.nrtCache.GetCachedNRTCopy(nodeName, pod)
+}
+
+// podZonesFromState returns the names of the NUMA zones the given pod is expected to be allocated from on the node,
+// using the node data computed in the Filter phase and the same logic of the filter handlers. Returns false if the
+// data is missing, or if the zones can't be determined, like when the policy of the node doesn't align the resources.
+func (tm *TopologyMatch) podZonesFromState(state *framework.CycleState, pod *v1.Pod, nodeName string) ([]string, bool) {
+	nodeTopology, ok := readNodeTopologyState(state, nodeName)
+	if !ok || len(nodeTopology.TopologyPolicies) == 0 {
+		return nil, false
+	}
+	policyName := topologyv1alpha1.TopologyManagerPolicy(nodeTopology.TopologyPolicies[0])
+	// check the pod as the Filter phase did
+	fitPod := tm.preFilterStateFor(state, pod).pod
+	if isRoundedPolicy(policyName) {
+		fitPod = withRoundedRequests(fitPod, tm.roundingPolicies)
+	}
+	zoneNames, ok := SimulatePlacement(nodeTopology, fitPod, policyName)
+	if !ok || len(zoneNames) == 0 {
+		klog.V(5).InfoS("cannot determine the pod zones", "logID", klog.KObj(pod), "node", nodeName, "policy", policyName)
+		return nil, false
+	}
+	return zoneNames, true
+}
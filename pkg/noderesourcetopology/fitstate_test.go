@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+type countingCache struct {
+	nrtcache.Interface
+	lookups int
+}
+
+func (cc *countingCache) GetCachedNRTCopy(nodeName string, pod *v1.Pod) (*topologyv1alpha1.NodeResourceTopology, bool) {
+	cc.lookups++
+	return cc.Interface.GetCachedNRTCopy(nodeName, pod)
+}
+
+func TestScoreReusesFilterState(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "fit-node"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "16Gi", "16Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "4"),
+					MakeTopologyResInfo(memory, "16Gi", "8Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nrt)
+
+	cc := &countingCache{Interface: nrtcache.NewPassthrough(fakeInformer.Lister())}
+	tm := &TopologyMatch{
+		filterHandlers:  newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		scoringHandlers: newScoringHandlers(leastAllocatedScoreStrategy, nil),
+		nrtCache:        cc,
+	}
+
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+
+	state := framework.NewCycleState()
+	if status := tm.Filter(context.Background(), state, pod, nodeInfo); !status.IsSuccess() {
+		t.Fatalf("node %q filtered out: %v", nrt.Name, status)
+	}
+	if _, ok := readNodeTopologyState(state, nrt.Name); !ok {
+		t.Fatalf("missing state for node %q after filter", nrt.Name)
+	}
+
+	lookups := cc.lookups
+	score, status := tm.Score(context.Background(), state, pod, nrt.Name)
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected scoring failure: %v", status)
+	}
+	if cc.lookups != lookups {
+		t.Errorf("score looked up the cache despite the filter state: %d lookups, expected %d", cc.lookups, lookups)
+	}
+
+	// cache miss: a new cycle state without data from the filter phase
+	missScore, status := tm.Score(context.Background(), framework.NewCycleState(), pod, nrt.Name)
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected scoring failure on state miss: %v", status)
+	}
+	if cc.lookups != lookups+1 {
+		t.Errorf("score did not look up the cache on state miss: %d lookups, expected %d", cc.lookups, lookups+1)
+	}
+	if missScore != score {
+		t.Errorf("score differs on state miss: %d, expected %d", missScore, score)
+	}
+}
+
+func TestReadNodeTopologyStateMissing(t *testing.T) {
+	if _, ok := readNodeTopologyState(nil, "node"); ok {
+		t.Errorf("read state from nil cycle state")
+	}
+
+	state := framework.NewCycleState()
+	writeNodeTopologyState(state, "node-A", &topologyv1alpha1.NodeResourceTopology{})
+	if _, ok := readNodeTopologyState(state, "node-B"); ok {
+		t.Errorf("read state of another node")
+	}
+	if _, ok := readNodeTopologyState(state.Clone(), "node-A"); !ok {
+		t.Errorf("missing state in the cloned cycle state")
+	}
+}
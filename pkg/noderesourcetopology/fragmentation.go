@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// FragmentationAfter simulates the placement of the given pods, in order, on the node described by the given NRT object,
+// and returns the fragmentation score of the resulting free capacity. See fragmentationScore for the details.
+// The placements are simulated like SimulatePlacement does, under the policy reported by the node; the pods which can't
+// be aligned are skipped. The NRT object is not changed. Meant for planning tools evaluating the impact of a batch.
+func FragmentationAfter(nrt *topologyv1alpha1.NodeResourceTopology, pods []*v1.Pod) float64 {
+	if nrt == nil {
+		return 0
+	}
+	sim := nrt.DeepCopy()
+	if len(sim.TopologyPolicies) == 0 {
+		klog.V(4).InfoS("cannot determine policy, placements not simulated", "node", sim.Name)
+		return fragmentationScore(sim.Zones)
+	}
+	policy := topologyv1alpha1.TopologyManagerPolicy(sim.TopologyPolicies[0])
+
+	for _, pod := range pods {
+		zoneNames, ok := SimulatePlacement(sim, pod, policy)
+		if !ok {
+			klog.V(5).InfoS("cannot place pod, skipped", "node", sim.Name, "pod", klog.KObj(pod))
+			continue
+		}
+		chargeZones(sim, util.GetPodEffectiveRequest(pod), zoneNames)
+	}
+	return fragmentationScore(sim.Zones)
+}
+
+// chargeZones subtracts the given resources from the available resources of the given NUMA zones, filling
+// the zones in order. This is exact for a single zone and a good enough approximation for pods spanning zones.
+func chargeZones(nrt *topologyv1alpha1.NodeResourceTopology, resources v1.ResourceList, zoneNames []string) {
+	nodes := createNUMANodeList(nrt.Zones)
+	var idxs []int
+	for _, zoneName := range zoneNames {
+		for idx, node := range nodes {
+			if fmt.Sprintf("node-%d", node.NUMAID) == zoneName {
+				idxs = append(idxs, idx)
+			}
+		}
+	}
+	subtractFromNUMAs(resources.DeepCopy(), nodes, idxs...)
+
+	for _, node := range nodes {
+		zone := nrtcache.GetZoneByName(nrt, fmt.Sprintf("node-%d", node.NUMAID))
+		if zone == nil {
+			continue
+		}
+		for ri := 0; ri < len(zone.Resources); ri++ {
+			zr := &zone.Resources[ri] // shortcut
+			if qty, ok := node.Resources[v1.ResourceName(zr.Name)]; ok {
+				zr.Available = qty
+			}
+		}
+	}
+}
+
+// fragmentationScore measures how much the free capacity of the NUMA zones is scattered, between 0 (all the free
+// capacity is in a single zone) and 1 (the free capacity is split across many zones). For each resource, the score
+// is the share of the free capacity not in the zone with the most free capacity; the final score is the average
+// across the resources with free capacity, so 0 if there is no free capacity at all.
+func fragmentationScore(zones topologyv1alpha1.ZoneList) float64 {
+	totalFree := make(map[string]float64)
+	maxFree := make(map[string]float64)
+	for _, zone := range zones {
+		if zone.Type != nrtcache.ZoneTypeNUMANode {
+			continue
+		}
+		for _, zr := range zone.Resources {
+			free := zr.Available.AsApproximateFloat64()
+			if free <= 0 {
+				continue
+			}
+			totalFree[zr.Name] += free
+			if free > maxFree[zr.Name] {
+				maxFree[zr.Name] = free
+			}
+		}
+	}
+	if len(totalFree) == 0 {
+		return 0
+	}
+	score := 0.0
+	for resName, total := range totalFree {
+		score += 1 - maxFree[resName]/total
+	}
+	return score / float64(len(totalFree))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+func TestFragmentationAfter(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+		},
+	}
+	nrtOrig := nrt.DeepCopy()
+
+	makeBatch := func(cpuQty string, count int) []*v1.Pod {
+		var pods []*v1.Pod
+		for i := 0; i < count; i++ {
+			pods = append(pods, makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpuQty),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}))
+		}
+		return pods
+	}
+
+	if frag := FragmentationAfter(nrt, nil); frag != 0.5 {
+		t.Errorf("unexpected fragmentation of the empty node: %v", frag)
+	}
+
+	// both pods fit node-0, all the free cpus are left on node-1, and most of the free memory
+	tight := FragmentationAfter(nrt, makeBatch("4", 2))
+	if tight <= 0 || tight >= 0.25 {
+		t.Errorf("unexpected fragmentation of the tight batch: %v", tight)
+	}
+
+	// the pods land on different zones, leaving 2 cpus and 7Gi of memory free on each
+	scattered := FragmentationAfter(nrt, makeBatch("6", 2))
+	if scattered != 0.5 {
+		t.Errorf("unexpected fragmentation of the scattered batch: %v", scattered)
+	}
+
+	if tight >= scattered {
+		t.Errorf("tight batch fragmentation %v not lower than scattered batch fragmentation %v", tight, scattered)
+	}
+
+	// the third pod can't fit and is skipped
+	if frag := FragmentationAfter(nrt, makeBatch("6", 3)); frag != scattered {
+		t.Errorf("unexpected fragmentation with unplaceable pod: %v", frag)
+	}
+
+	if !reflect.DeepEqual(nrt, nrtOrig) {
+		t.Errorf("the NRT object was changed")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// withoutIgnoredResources returns the pod without the ignored resources in the requests and limits of its
+// containers, so they don't participate in the NUMA zone fit and scoring. These resources, like ephemeral-storage,
+// are not NUMA-local, so no zone would ever report them, and they are already checked at node level by other plugins.
+// Returns the pod unchanged (not a copy) if it doesn't use any ignored resource.
+func withoutIgnoredResources(pod *v1.Pod, ignored sets.String) *v1.Pod {
+	if ignored.Len() == 0 || !podUsesAnyResource(pod, ignored) {
+		return pod
+	}
+	ret := pod.DeepCopy()
+	for idx := range ret.Spec.InitContainers {
+		dropResources(&ret.Spec.InitContainers[idx].Resources, ignored)
+	}
+	for idx := range ret.Spec.Containers {
+		dropResources(&ret.Spec.Containers[idx].Resources, ignored)
+	}
+	return ret
+}
+
+func podUsesAnyResource(pod *v1.Pod, names sets.String) bool {
+	containers := append([]v1.Container{}, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, cnt := range containers {
+		for resName := range cnt.Resources.Requests {
+			if names.Has(string(resName)) {
+				return true
+			}
+		}
+		for resName := range cnt.Resources.Limits {
+			if names.Has(string(resName)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func dropResources(resources *v1.ResourceRequirements, names sets.String) {
+	for _, name := range names.UnsortedList() {
+		delete(resources.Requests, v1.ResourceName(name))
+		delete(resources.Limits, v1.ResourceName(name))
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// defaultEvalLatencyWindow is how long the per-node evaluation latency samples are retained
+	defaultEvalLatencyWindow = 5 * time.Minute
+	// evalLatencyBuckets is how many time buckets the window is split into. Samples are aggregated
+	// per bucket, so memory usage is bounded by the number of buckets times the number of nodes.
+	evalLatencyBuckets = 10
+	// evalLatencyMetricMaxNodes is how many of the slowest nodes are exported in the metrics,
+	// to keep the metric cardinality bounded on large clusters.
+	evalLatencyMetricMaxNodes = 10
+)
+
+// sharedEvalLatency is the tracker used by all the plugin instances, so the exported data covers all the profiles.
+var sharedEvalLatency = newEvalLatencyTracker(clock.RealClock{}, defaultEvalLatencyWindow)
+
+// evalLatencyTracker attributes the time spent evaluating (filtering, scoring) each node, so we can
+// find the nodes whose large or complex NRT objects slow down the scheduling.
+// Nodes are evaluated in parallel, so each node keeps its own samples, and the samples out of the
+// sampling window are dropped only when the data is read, never while recording.
+type evalLatencyTracker struct {
+	clock       clock.PassiveClock
+	window      time.Duration
+	granularity time.Duration
+	// nodeName -> *nodeEvalLatency
+	nodes sync.Map
+}
+
+// nodeEvalLatency accumulates the evaluation time of a node in a ring of time buckets, which covers the sampling window.
+// The same node is seldom evaluated concurrently, so the lock is hardly ever contended.
+type nodeEvalLatency struct {
+	lock   sync.Mutex
+	starts [evalLatencyBuckets + 1]time.Time
+	totals [evalLatencyBuckets + 1]time.Duration
+	// deleted is set once the entry is dropped from the tracker, so the late recorders use a new one
+	deleted bool
+}
+
+func newEvalLatencyTracker(clk clock.PassiveClock, window time.Duration) *evalLatencyTracker {
+	granularity := window / evalLatencyBuckets
+	if granularity <= 0 {
+		granularity = window
+	}
+	return &evalLatencyTracker{
+		clock:       clk,
+		window:      window,
+		granularity: granularity,
+	}
+}
+
+// Track starts the measurement of the evaluation of the given node. The caller must invoke the returned
+// function once the evaluation is done. Safe to be called on nil trackers, which do nothing.
+func (elt *evalLatencyTracker) Track(nodeName string) func() {
+	if elt == nil {
+		return func() {}
+	}
+	start := elt.clock.Now()
+	return func() {
+		elt.record(nodeName, start, elt.clock.Since(start))
+	}
+}
+
+func (elt *evalLatencyTracker) record(nodeName string, ts time.Time, elapsed time.Duration) {
+	bucketStart := ts.Truncate(elt.granularity)
+	idx := int((bucketStart.UnixNano() / int64(elt.granularity)) % (evalLatencyBuckets + 1))
+	for {
+		obj, ok := elt.nodes.Load(nodeName)
+		if !ok {
+			obj, _ = elt.nodes.LoadOrStore(nodeName, &nodeEvalLatency{})
+		}
+		nel := obj.(*nodeEvalLatency)
+		nel.lock.Lock()
+		if nel.deleted {
+			nel.lock.Unlock()
+			continue
+		}
+		if !nel.starts[idx].Equal(bucketStart) {
+			// reusing the bucket of an older round of the ring
+			nel.starts[idx] = bucketStart
+			nel.totals[idx] = 0
+		}
+		nel.totals[idx] += elapsed
+		nel.lock.Unlock()
+		return
+	}
+}
+
+// PerNodeEvalLatency returns the time spent evaluating each node within the sampling window.
+func (elt *evalLatencyTracker) PerNodeEvalLatency() map[string]time.Duration {
+	ret := make(map[string]time.Duration)
+	if elt == nil {
+		return ret
+	}
+	cutoff := elt.clock.Now().Add(-elt.window)
+	elt.nodes.Range(func(key, obj any) bool {
+		nodeName, nel := key.(string), obj.(*nodeEvalLatency)
+		nel.lock.Lock()
+		defer nel.lock.Unlock()
+		var total time.Duration
+		recent := false
+		for idx := range nel.starts {
+			if !nel.starts[idx].Add(elt.granularity).After(cutoff) {
+				continue
+			}
+			total += nel.totals[idx]
+			recent = true
+		}
+		if !recent {
+			// not evaluated within the window, likely removed from the cluster
+			nel.deleted = true
+			elt.nodes.Delete(nodeName)
+			return true
+		}
+		ret[nodeName] = total
+		return true
+	})
+	return ret
+}
+
+// PerNodeEvalLatency returns the time spent filtering and scoring each node within the sampling window.
+// The plugin instances share the samples, so the data covers the evaluations of all the scheduler profiles.
+func (tm *TopologyMatch) PerNodeEvalLatency() map[string]time.Duration {
+	return tm.evalLatency.PerNodeEvalLatency()
+}
+
+var nodeEvalLatencyDesc = metrics.NewDesc(
+	"noderesourcetopology_node_evaluation_seconds",
+	"Time spent filtering and scoring the node within the sampling window, for the slowest nodes.",
+	[]string{"node"}, nil, metrics.ALPHA, "")
+
+// evalLatencyCollector exports the evaluation latency of the slowest nodes. The data is computed when collected.
+type evalLatencyCollector struct {
+	metrics.BaseStableCollector
+	tracker  *evalLatencyTracker
+	maxNodes int
+}
+
+func (c *evalLatencyCollector) DescribeWithStability(ch chan<- *metrics.Desc) {
+	ch <- nodeEvalLatencyDesc
+}
+
+func (c *evalLatencyCollector) CollectWithStability(ch chan<- metrics.Metric) {
+	latencies := c.tracker.PerNodeEvalLatency()
+	nodeNames := make([]string, 0, len(latencies))
+	for nodeName := range latencies {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Slice(nodeNames, func(i, j int) bool {
+		if latencies[nodeNames[i]] != latencies[nodeNames[j]] {
+			return latencies[nodeNames[i]] > latencies[nodeNames[j]]
+		}
+		return nodeNames[i] < nodeNames[j]
+	})
+	if len(nodeNames) > c.maxNodes {
+		nodeNames = nodeNames[:c.maxNodes]
+	}
+	for _, nodeName := range nodeNames {
+		ch <- metrics.NewLazyConstMetric(nodeEvalLatencyDesc, metrics.GaugeValue, latencies[nodeName].Seconds(), nodeName)
+	}
+}
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the plugin metrics in the legacy registry. Safe to be called more than once.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.CustomMustRegister(&evalLatencyCollector{
+			tracker:  sharedEvalLatency,
+			maxNodes: evalLatencyMetricMaxNodes,
+		})
+	})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/component-base/metrics/testutil"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestPerNodeEvalLatency(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))
+	tm := &TopologyMatch{
+		evalLatency: newEvalLatencyTracker(fakeClock, time.Minute),
+	}
+
+	evaluate := func(nodeName string, elapsed time.Duration) {
+		done := tm.evalLatency.Track(nodeName)
+		fakeClock.Step(elapsed)
+		done()
+	}
+
+	evaluate("node-a", 2*time.Millisecond)
+	evaluate("node-b", 5*time.Millisecond)
+	evaluate("node-a", 3*time.Millisecond)
+
+	expected := map[string]time.Duration{
+		"node-a": 5 * time.Millisecond,
+		"node-b": 5 * time.Millisecond,
+	}
+	got := tm.PerNodeEvalLatency()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected latencies: got=%v expected=%v", got, expected)
+	}
+
+	// move past the window: the previous samples must be forgotten
+	fakeClock.Step(2 * time.Minute)
+	evaluate("node-b", time.Millisecond)
+
+	expected = map[string]time.Duration{
+		"node-b": time.Millisecond,
+	}
+	got = tm.PerNodeEvalLatency()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected latencies after window: got=%v expected=%v", got, expected)
+	}
+}
+
+func TestPerNodeEvalLatencyDisabled(t *testing.T) {
+	tm := &TopologyMatch{}
+	tm.evalLatency.Track("node-a")()
+	if got := tm.PerNodeEvalLatency(); len(got) != 0 {
+		t.Errorf("unexpected latencies from disabled tracker: %v", got)
+	}
+}
+
+func TestPerNodeEvalLatencyConcurrent(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))
+	elt := newEvalLatencyTracker(fakeClock, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				elt.record(fmt.Sprintf("node-%d", i%2), fakeClock.Now(), time.Millisecond)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	expected := map[string]time.Duration{
+		"node-0": 400 * time.Millisecond,
+		"node-1": 400 * time.Millisecond,
+	}
+	if got := elt.PerNodeEvalLatency(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected latencies: got=%v expected=%v", got, expected)
+	}
+}
+
+func TestEvalLatencyCollector(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))
+	elt := newEvalLatencyTracker(fakeClock, time.Minute)
+	elt.record("node-a", fakeClock.Now(), 2*time.Second)
+	elt.record("node-b", fakeClock.Now(), 5*time.Second)
+	elt.record("node-c", fakeClock.Now(), time.Second)
+
+	// only the slowest nodes are exported
+	expected := `
+		# HELP noderesourcetopology_node_evaluation_seconds [ALPHA] Time spent filtering and scoring the node within the sampling window, for the slowest nodes.
+		# TYPE noderesourcetopology_node_evaluation_seconds gauge
+		noderesourcetopology_node_evaluation_seconds{node="node-a"} 2
+		noderesourcetopology_node_evaluation_seconds{node="node-b"} 5
+	`
+	collector := &evalLatencyCollector{tracker: elt, maxNodes: 2}
+	if err := testutil.CustomCollectAndCompare(collector, strings.NewReader(expected), "noderesourcetopology_node_evaluation_seconds"); err != nil {
+		t.Error(err)
+	}
+}
@@ -22,7 +22,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 )
 
-func leastAllocatedScoreStrategy(requested, allocatable v1.ResourceList, resourceToWeightMap resourceToWeightMap) int64 {
+func leastAllocatedScoreStrategy(requested, allocatable, _ v1.ResourceList, resourceToWeightMap resourceToWeightMap) int64 {
 	var numaNodeScore int64 = 0
 	var weightSum int64 = 0
 
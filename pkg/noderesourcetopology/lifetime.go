@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// AnnotationExpectedLifetime is the pod annotation hinting how long the pod is expected to run.
+// Short-lived pods, like batch jobs, are better packed on the busiest NUMA zones, leaving the emptier zones to the
+// pods to come; long-lived pods, like services, are better spread on the emptiest NUMA zones, to keep room to grow
+// and to limit the noisy neighbours. The hint overrides the configured scoring strategy for the pod.
+const AnnotationExpectedLifetime = "noderesourcetopology/expected-lifetime"
+
+const (
+	ExpectedLifetimeShort = "short"
+	ExpectedLifetimeLong  = "long"
+)
+
+// lifetimeScoreStrategies maps the expected lifetimes to the scoring strategy they bias the pods to.
+var lifetimeScoreStrategies = map[string]scoreStrategy{
+	ExpectedLifetimeShort: mostAllocatedScoreStrategy,
+	ExpectedLifetimeLong:  leastAllocatedScoreStrategy,
+}
+
+// newLifetimeScoringHandlers builds the scoring handlers of each expected lifetime, so Score() only needs a lookup.
+func newLifetimeScoringHandlers(resourceToWeightMap resourceToWeightMap) map[string]scoreHandlersMap {
+	handlers := make(map[string]scoreHandlersMap, len(lifetimeScoreStrategies))
+	for lifetime, strategy := range lifetimeScoreStrategies {
+		handlers[lifetime] = newScoringHandlers(strategy, resourceToWeightMap)
+	}
+	return handlers
+}
+
+// expectedLifetime returns the expected lifetime of the pod, if any and known.
+func expectedLifetime(pod *v1.Pod) (string, bool) {
+	if pod == nil {
+		return "", false
+	}
+	val, ok := pod.Annotations[AnnotationExpectedLifetime]
+	if !ok {
+		return "", false
+	}
+	if _, ok := lifetimeScoreStrategies[val]; !ok {
+		klog.V(4).InfoS("ignoring unknown expected lifetime", "pod", klog.KObj(pod), "lifetime", val)
+		return "", false
+	}
+	return val, true
+}
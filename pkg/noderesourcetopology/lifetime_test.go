@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestExpectedLifetime(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:     "no annotations",
+			expected: false,
+		},
+		{
+			name:        "short",
+			annotations: map[string]string{AnnotationExpectedLifetime: ExpectedLifetimeShort},
+			expected:    true,
+		},
+		{
+			name:        "long",
+			annotations: map[string]string{AnnotationExpectedLifetime: ExpectedLifetimeLong},
+			expected:    true,
+		},
+		{
+			name:        "unknown",
+			annotations: map[string]string{AnnotationExpectedLifetime: "forever"},
+			expected:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if _, got := expectedLifetime(pod); got != tt.expected {
+				t.Errorf("got %v expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScoreExpectedLifetime(t *testing.T) {
+	makeNRT := func(name, availableCPU, availableMemory string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: name},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "20", availableCPU),
+						MakeTopologyResInfo(memory, "32Gi", availableMemory),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "20", availableCPU),
+						MakeTopologyResInfo(memory, "32Gi", availableMemory),
+					},
+				},
+			},
+		}
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	// same hardware, different load
+	fakeInformer.Informer().GetStore().Add(makeNRT("busy", "8", "12Gi"))
+	fakeInformer.Informer().GetStore().Add(makeNRT("idle", "20", "32Gi"))
+
+	weights := resourceToWeightMap{v1.ResourceCPU: 1, v1.ResourceMemory: 1}
+	tm := TopologyMatch{
+		// the configured strategy is the one to be overridden
+		scoringHandlers:         newScoringHandlers(balancedAllocationScoreStrategy, weights),
+		lifetimeScoringHandlers: newLifetimeScoringHandlers(weights),
+		resourceToWeightMap:     weights,
+		nrtCache:                nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	scoreNodes := func(t *testing.T, lifetime string) (int64, int64) {
+		t.Helper()
+		pod := makePodByResourceList(&v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("2"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+		})
+		pod.Annotations = map[string]string{AnnotationExpectedLifetime: lifetime}
+
+		busyScore, status := tm.Score(context.Background(), framework.NewCycleState(), pod, "busy")
+		if status != nil {
+			t.Fatalf("unexpected status: %v", status)
+		}
+		idleScore, status := tm.Score(context.Background(), framework.NewCycleState(), pod, "idle")
+		if status != nil {
+			t.Fatalf("unexpected status: %v", status)
+		}
+		return busyScore, idleScore
+	}
+
+	t.Run("short lived pod packs", func(t *testing.T) {
+		busyScore, idleScore := scoreNodes(t, ExpectedLifetimeShort)
+		if busyScore <= idleScore {
+			t.Errorf("expected the busy node to be preferred: busy=%d idle=%d", busyScore, idleScore)
+		}
+	})
+
+	t.Run("long lived pod spreads", func(t *testing.T) {
+		busyScore, idleScore := scoreNodes(t, ExpectedLifetimeLong)
+		if idleScore <= busyScore {
+			t.Errorf("expected the idle node to be preferred: busy=%d idle=%d", busyScore, idleScore)
+		}
+	})
+}
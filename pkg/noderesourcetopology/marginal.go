@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// MarginalPlacementCost returns, for each of the given nodes, how much scarce-resource pressure placing the pod on it adds.
+// The cost of a NUMA zone is the weighted mean of the fractions of its available resources the pod would consume;
+// the cost of a node is the cost of its cheapest zone which can fit the pod, or +Inf if none can.
+// Lower is better, so the cost can be used as the inverse of a score.
+// Nodes without valid topology data are not included in the result.
+func (tm *TopologyMatch) MarginalPlacementCost(pod *v1.Pod, nodeNames []string) map[string]float64 {
+	resources := util.GetPodEffectiveRequest(pod)
+	costs := make(map[string]float64, len(nodeNames))
+
+	for _, nodeName := range nodeNames {
+		nodeTopology, ok := tm.nrtCache.GetCachedNRTCopy(nodeName, pod)
+		if !ok || nodeTopology == nil {
+			klog.V(5).InfoS("noderesourcetopology not available for node", "logID", klog.KObj(pod), "node", nodeName)
+			continue
+		}
+
+		nodeCost := math.Inf(1)
+		for _, numaNode := range createNUMANodeList(nodeTopology.Zones) {
+			zoneCost, fits := marginalZoneCost(resources, numaNode.Resources, tm.resourceToWeightMap)
+			if fits && zoneCost < nodeCost {
+				nodeCost = zoneCost
+			}
+		}
+		klog.V(6).InfoS("marginal placement cost", "logID", klog.KObj(pod), "node", nodeName, "cost", nodeCost)
+		costs[nodeName] = nodeCost
+	}
+	return costs
+}
+
+// marginalZoneCost returns the weighted mean of the fractions of the available zone resources consumed by
+// the given resources, and false if the zone can't fit them. Resources not reported by the zone are ignored.
+func marginalZoneCost(resources, zoneResources v1.ResourceList, resourceToWeightMap resourceToWeightMap) (float64, bool) {
+	var cost float64
+	var weightSum int64
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		available, ok := zoneResources[resource]
+		if !ok {
+			// non NUMA resource
+			continue
+		}
+		if available.Cmp(quantity) < 0 {
+			return 0, false
+		}
+		weight := resourceToWeightMap.weight(resource)
+		cost += float64(weight) * float64(quantity.MilliValue()) / float64(available.MilliValue())
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 0, true
+	}
+	return cost / float64(weightSum), true
+}
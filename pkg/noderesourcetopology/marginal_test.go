@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+func TestMarginalPlacementCost(t *testing.T) {
+	makeNRT := func(name, cpuAvailable, memoryAvailable string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: name},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "32", cpuAvailable),
+						MakeTopologyResInfo(memory, "64Gi", memoryAvailable),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "32", "0"),
+						MakeTopologyResInfo(memory, "64Gi", "0"),
+					},
+				},
+			},
+		}
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	for _, obj := range []*topologyv1alpha1.NodeResourceTopology{
+		makeNRT("abundant", "30", "60Gi"),
+		makeNRT("tight", "4", "5Gi"),
+		makeNRT("full", "2", "1Gi"),
+	} {
+		fakeInformer.Informer().GetStore().Add(obj)
+	}
+
+	tm := &TopologyMatch{
+		nrtCache: nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("3"),
+		v1.ResourceMemory: resource.MustParse("4Gi"),
+	})
+
+	costs := tm.MarginalPlacementCost(pod, []string{"abundant", "tight", "full", "missing"})
+	t.Logf("costs: %v", costs)
+
+	if _, ok := costs["missing"]; ok {
+		t.Errorf("unexpected cost for node without topology data")
+	}
+	if !math.IsInf(costs["full"], 1) {
+		t.Errorf("expected infinite cost for node which cannot fit the pod, got %v", costs["full"])
+	}
+	if costs["abundant"] >= costs["tight"] {
+		t.Errorf("expected lower cost for node with abundant resources: abundant=%v tight=%v", costs["abundant"], costs["tight"])
+	}
+	// cpu: 3/30, memory: 4/60
+	if expected := (3.0/30.0 + 4.0/60.0) / 2; math.Abs(costs["abundant"]-expected) > 1e-9 {
+		t.Errorf("unexpected cost for node with abundant resources: got %v expected %v", costs["abundant"], expected)
+	}
+}
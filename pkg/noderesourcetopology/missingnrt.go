@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// validateMissingNRTPolicy returns an error if the given policy for the nodes without NRT data is unknown.
+// The empty policy is accepted, and behaves like MissingNRTSkip.
+func validateMissingNRTPolicy(policy apiconfig.MissingNRTPolicy) error {
+	switch policy {
+	case "", apiconfig.MissingNRTSkip, apiconfig.MissingNRTReject, apiconfig.MissingNRTError:
+		return nil
+	default:
+		return fmt.Errorf("unknown missing NRT policy %q", policy)
+	}
+}
+
+// missingNRTStatus returns the filter status for a node without NRT data according to the given policy.
+// Skipped nodes pass the filter, and get the lowest score since there is nothing to score, so the nodes
+// without a topology exporter stay schedulable in mixed clusters. Rejected nodes are unresolvable, because
+// preempting pods can't make the NRT data appear.
+func missingNRTStatus(policy apiconfig.MissingNRTPolicy, err error) *framework.Status {
+	switch policy {
+	case apiconfig.MissingNRTReject:
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	case apiconfig.MissingNRTError:
+		return framework.AsStatus(err)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestValidateMissingNRTPolicy(t *testing.T) {
+	for _, policy := range []apiconfig.MissingNRTPolicy{"", apiconfig.MissingNRTSkip, apiconfig.MissingNRTReject, apiconfig.MissingNRTError} {
+		if err := validateMissingNRTPolicy(policy); err != nil {
+			t.Errorf("unexpected error for policy %q: %v", policy, err)
+		}
+	}
+	if err := validateMissingNRTPolicy("Ignore"); err == nil {
+		t.Errorf("unknown policy accepted")
+	}
+}
+
+func TestNodeResourceTopologyMissingNRTPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     apiconfig.MissingNRTPolicy
+		wantStatus framework.Code
+	}{
+		{
+			name: "unset - skip",
+		},
+		{
+			name:   "skip",
+			policy: apiconfig.MissingNRTSkip,
+		},
+		{
+			name:       "reject",
+			policy:     apiconfig.MissingNRTReject,
+			wantStatus: framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name:       "error",
+			policy:     apiconfig.MissingNRTError,
+			wantStatus: framework.Error,
+		},
+	}
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-missing"},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("8"),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("8"),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the store has no NRT data at all
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+
+			tm := TopologyMatch{
+				filterHandlers:   newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				scoringHandlers:  newScoringHandlers(leastAllocatedScoreStrategy, nil),
+				nrtCache:         nrtcache.NewPassthrough(fakeInformer.Lister()),
+				missingNRTPolicy: tt.policy,
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			state := framework.NewCycleState()
+			gotStatus := tm.Filter(context.Background(), state, pod, nodeInfo)
+			if gotStatus.Code() != tt.wantStatus {
+				t.Fatalf("status does not match: %v, want code: %v", gotStatus, tt.wantStatus)
+			}
+			if !gotStatus.IsSuccess() {
+				return
+			}
+
+			score, scoreStatus := tm.Score(context.Background(), state, pod, node.Name)
+			if !scoreStatus.IsSuccess() || score != 0 {
+				t.Errorf("unexpected score for a skipped node: %d (status %v), expected 0", score, scoreStatus)
+			}
+		})
+	}
+}
@@ -22,14 +22,24 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 )
 
-func mostAllocatedScoreStrategy(requested, allocatable v1.ResourceList, resourceToWeightMap resourceToWeightMap) int64 {
+// mostAllocatedScoreStrategy scores a NUMA zone higher the more utilized the zone becomes after placing the requested
+// resources, to pack the pods on the busiest zones and free up whole nodes. Zones which can't fit all the requested
+// resources score 0, so they are never preferred.
+func mostAllocatedScoreStrategy(requested, allocatable, capacity v1.ResourceList, resourceToWeightMap resourceToWeightMap) int64 {
 	var numaNodeScore int64 = 0
 	var weightSum int64 = 0
 
+	for resourceName, quantity := range requested {
+		available, ok := allocatable[resourceName]
+		if ok && quantity.Cmp(available) > 0 {
+			return 0
+		}
+	}
+
 	for resourceName := range requested {
 		// We don't care what kind of resources are being requested, we just iterate all of them.
 		// If NUMA zone doesn't have the requested resource, the score for that resource will be 0.
-		resourceScore := mostAllocatedScore(requested[resourceName], allocatable[resourceName])
+		resourceScore := mostAllocatedScore(requested[resourceName], allocatable[resourceName], capacity[resourceName])
 		weight := resourceToWeightMap.weight(resourceName)
 		numaNodeScore += resourceScore * weight
 		weightSum += weight
@@ -41,14 +51,19 @@ func mostAllocatedScoreStrategy(requested, allocatable v1.ResourceList, resource
 // The used capacity is calculated on a scale of 0-MaxNodeScore (MaxNodeScore is
 // constant with value set to 100).
 // 0 being the lowest priority and 100 being the highest.
-// The more allocated resources the node has, the higher the score is.
-func mostAllocatedScore(requested, numaCapacity resource.Quantity) int64 {
-	if numaCapacity.CmpInt64(0) == 0 {
+// The more allocated resources the NUMA zone has after the placement, the higher the score is.
+func mostAllocatedScore(requested, numaAvailable, numaCapacity resource.Quantity) int64 {
+	if numaAvailable.CmpInt64(0) == 0 {
 		return 0
 	}
-	if requested.Cmp(numaCapacity) > 0 {
+	if requested.Cmp(numaAvailable) > 0 {
 		return 0
 	}
+	if numaCapacity.Cmp(numaAvailable) < 0 {
+		// capacity not reported or inconsistent, we can only account the request
+		numaCapacity = numaAvailable
+	}
 
-	return requested.Value() * framework.MaxNodeScore / numaCapacity.Value()
+	used := numaCapacity.Value() - numaAvailable.Value() + requested.Value()
+	return used * framework.MaxNodeScore / numaCapacity.Value()
 }
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// SuggestOvercommitRatio returns the minimal cpu overcommit ratio which lets the given number of copies of the probe pod
+// fit on the node described by the given NRT object, each copy aligned to a single NUMA zone. The ratio multiplies the
+// cpus available in each zone; the other resources are not overcommitted. Returns 1 if the target fits already,
+// and 0 if no cpu overcommit can meet the target, e.g. because the zones run out of memory first.
+// Meant for capacity planning tools.
+func SuggestOvercommitRatio(nrt *topologyv1alpha1.NodeResourceTopology, probePod *v1.Pod, targetCount int) float64 {
+	if targetCount <= 0 {
+		return 1
+	}
+	if nrt == nil || probePod == nil {
+		return 0
+	}
+
+	resources := util.GetPodEffectiveRequest(probePod)
+	podCPU := resources.Cpu().MilliValue()
+	nodes := createNUMANodeList(nrt.Zones)
+
+	zoneCPUs := make([]int64, len(nodes))
+	zoneLimits := make([]int, len(nodes)) // how many pods each zone can fit, considering only the other resources
+	var candidates []float64
+	for idx, node := range nodes {
+		zoneLimits[idx] = fitCountIgnoringCPU(resources, node.Resources, targetCount)
+		zoneCPU := node.Resources[v1.ResourceCPU]
+		zoneCPUs[idx] = zoneCPU.MilliValue()
+		if podCPU == 0 || zoneCPUs[idx] == 0 {
+			continue
+		}
+		// the ratios at which the zone can fit one more pod
+		for count := 1; count <= zoneLimits[idx]; count++ {
+			candidates = append(candidates, float64(int64(count)*podCPU)/float64(zoneCPUs[idx]))
+		}
+	}
+
+	fitCount := func(ratio float64) int {
+		total := 0
+		for idx := range nodes {
+			count := zoneLimits[idx]
+			if podCPU > 0 {
+				// the epsilon compensates the rounding errors at the exact candidate ratios
+				count = int(math.Min(float64(count), math.Floor(float64(zoneCPUs[idx])*ratio/float64(podCPU)+1e-9)))
+			}
+			total += count
+		}
+		return total
+	}
+
+	if fitCount(1) >= targetCount {
+		return 1
+	}
+	sort.Float64s(candidates)
+	for _, ratio := range candidates {
+		if ratio > 1 && fitCount(ratio) >= targetCount {
+			return ratio
+		}
+	}
+	return 0
+}
+
+// fitCountIgnoringCPU returns how many pods requesting the given resources fit in the given available resources,
+// not considering the cpus, up to the given limit. Non-native resources not reported by the zone don't limit the count.
+func fitCountIgnoringCPU(resources, available v1.ResourceList, limit int) int {
+	count := limit
+	for resource, quantity := range resources {
+		if resource == v1.ResourceCPU || quantity.IsZero() {
+			continue
+		}
+		availableQuantity, ok := available[resource]
+		if !ok {
+			if v1helper.IsNativeResource(resource) {
+				return 0
+			}
+			continue
+		}
+		if fits := int(availableQuantity.MilliValue() / quantity.MilliValue()); fits < count {
+			count = fits
+		}
+	}
+	return count
+}
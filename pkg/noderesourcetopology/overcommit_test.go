@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+func TestSuggestOvercommitRatio(t *testing.T) {
+	oneZone := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "64Gi", "64Gi"),
+				},
+			},
+		},
+	}
+	twoZones := oneZone.DeepCopy()
+	twoZones.Zones = append(twoZones.Zones, topologyv1alpha1.Zone{
+		Name: "node-1",
+		Type: "Node",
+		Resources: topologyv1alpha1.ResourceInfoList{
+			MakeTopologyResInfo(cpu, "10", "10"),
+			MakeTopologyResInfo(memory, "64Gi", "64Gi"),
+		},
+	})
+
+	tests := []struct {
+		name        string
+		nrt         *topologyv1alpha1.NodeResourceTopology
+		memory      string
+		targetCount int
+		expected    float64
+	}{
+		{
+			name:        "fits already",
+			nrt:         oneZone,
+			memory:      "1Gi",
+			targetCount: 2,
+			expected:    1,
+		},
+		{
+			name:        "needs overcommit",
+			nrt:         oneZone,
+			memory:      "1Gi",
+			targetCount: 3,
+			expected:    1.2,
+		},
+		{
+			name:        "needs overcommit across zones",
+			nrt:         twoZones,
+			memory:      "1Gi",
+			targetCount: 4,
+			expected:    1.2,
+		},
+		{
+			name:        "memory runs out first",
+			nrt:         oneZone,
+			memory:      "30Gi",
+			targetCount: 3,
+			expected:    0,
+		},
+		{
+			name:        "no target",
+			nrt:         oneZone,
+			memory:      "1Gi",
+			targetCount: 0,
+			expected:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probePod := makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("8"),
+				v1.ResourceMemory: resource.MustParse(tt.memory),
+			})
+			got := SuggestOvercommitRatio(tt.nrt, probePod, tt.targetCount)
+			if math.Abs(got-tt.expected) > 1e-6 {
+				t.Errorf("got ratio %v expected %v", got, tt.expected)
+			}
+		})
+	}
+}
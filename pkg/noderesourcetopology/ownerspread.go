@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+// AnnotationSpreadReplicas marks the pods, usually latency-sensitive replicas, which prefer not to share the NUMA zones
+// with the other pods of the same owner on the same node. Unlike AnnotationSpreadContainers, it affects only the scoring.
+const AnnotationSpreadReplicas = "noderesourcetopology/spread-replicas"
+
+// ownerSpreadPenaltyDivisor scales the penalty of the nodes which can fit the pod only in zones hosting pods of the same owner.
+const ownerSpreadPenaltyDivisor = 5
+
+func isSpreadReplicasPod(pod *v1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	val, ok := pod.Annotations[AnnotationSpreadReplicas]
+	if !ok {
+		return false
+	}
+	spread, err := strconv.ParseBool(val)
+	return err == nil && spread
+}
+
+// ownerSpreadZoneScore scores a zone by how many pods of the same owner it already hosts: the emptier, the higher.
+func ownerSpreadZoneScore(ownerPods int) int64 {
+	return framework.MaxNodeScore / int64(1+ownerPods)
+}
+
+// ownerSpreadZoneScores returns the spread score of each NUMA zone which can fit the given effective request of
+// the pod, given how many pods of the same owner each zone hosts.
+func ownerSpreadZoneScores(resources v1.ResourceList, zones topologyv1alpha1.ZoneList, ownerPods map[string]int) map[string]int64 {
+	scores := make(map[string]int64)
+	for _, zone := range zones {
+		if zone.Type != nrtcache.ZoneTypeNUMANode {
+			continue
+		}
+		// only guaranteed pods are scored
+		if !resourcesFitCombination(v1.PodQOSGuaranteed, resources, extractResources(zone)) {
+			continue
+		}
+		scores[zone.Name] = ownerSpreadZoneScore(ownerPods[zone.Name])
+	}
+	return scores
+}
+
+// ownerSpreadScore lowers the given score if every zone of the node which can fit the pod already hosts pods of the
+// same owner, proportionally to how crowded the emptiest of these zones is. The returned score is never negative.
+func ownerSpreadScore(pod *v1.Pod, resources v1.ResourceList, nodeName string, zones topologyv1alpha1.ZoneList, ownerPods map[string]int, score int64) int64 {
+	if len(ownerPods) == 0 {
+		return score
+	}
+	best := int64(-1)
+	for _, zoneScore := range ownerSpreadZoneScores(resources, zones, ownerPods) {
+		if zoneScore > best {
+			best = zoneScore
+		}
+	}
+	if best < 0 {
+		// no zone can fit the pod, nothing to spread
+		return score
+	}
+	penalized := score - (framework.MaxNodeScore-best)/ownerSpreadPenaltyDivisor
+	if penalized < 0 {
+		penalized = 0
+	}
+	klog.V(5).InfoS("owner spread score", "logID", klog.KObj(pod), "node", nodeName, "bestZoneScore", best, "score", score, "penalized", penalized)
+	return penalized
+}
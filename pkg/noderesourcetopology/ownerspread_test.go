@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+type emptyNodeIndexer struct{}
+
+func (ei emptyNodeIndexer) GetPodNamespacedNamesByNode(logID, nodeName string) ([]types.NamespacedName, error) {
+	return nil, nil
+}
+
+func (ei emptyNodeIndexer) TrackReservedPod(pod *v1.Pod, nodeName string)   {}
+func (ei emptyNodeIndexer) UntrackReservedPod(pod *v1.Pod, nodeName string) {}
+
+func makeReplicaPod(name string) *v1.Pod {
+	controller := true
+	pod := makePod(name, withMultiContainers([]v1.ResourceList{
+		{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}))
+	pod.Namespace = "default"
+	pod.Annotations = map[string]string{
+		AnnotationSpreadReplicas: "true",
+	}
+	pod.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: "apps/v1",
+			Kind:       "ReplicaSet",
+			Name:       "rs-1",
+			UID:        types.UID("rs-1"),
+			Controller: &controller,
+		},
+	}
+	return pod
+}
+
+func TestOwnerSpreadReplicas(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nrt)
+	nrtCache, err := nrtcache.NewOverReserve(fakeInformer.Lister(), emptyNodeIndexer{}, 0)
+	if err != nil {
+		t.Fatalf("cannot create the cache: %v", err)
+	}
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtCache,
+	}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+
+	first := makeReplicaPod("replica-1")
+	state := framework.NewCycleState()
+	if status := tm.Filter(context.Background(), state, first, nodeInfo); status != nil {
+		t.Fatalf("unexpected filter status: %v", status)
+	}
+	if status := tm.Reserve(context.Background(), state, first, "host0"); !status.IsSuccess() {
+		t.Fatalf("unexpected reserve status: %v", status)
+	}
+
+	second := makeReplicaPod("replica-2")
+	ownerPods := nrtCache.OwnerPodsPerZone("host0", second)
+	if ownerPods["node-0"] != 1 || ownerPods["node-1"] != 0 {
+		t.Fatalf("unexpected owner pods per zone: %v", ownerPods)
+	}
+
+	// both zones still fit the second replica, which should prefer the zone without the first replica
+	zoneScores := ownerSpreadZoneScores(util.GetPodEffectiveRequest(second), nrt.Zones, ownerPods)
+	if zoneScores["node-1"] <= zoneScores["node-0"] {
+		t.Errorf("expected the emptier zone to score higher, got %v", zoneScores)
+	}
+	if got := ownerSpreadScore(second, util.GetPodEffectiveRequest(second), "host0", nrt.Zones, ownerPods, 50); got != 50 {
+		t.Errorf("unexpected penalty with an emptier zone available: got %d expected 50", got)
+	}
+
+	// only the zone hosting the first replica fits the second one
+	crowded := nrt.DeepCopy()
+	crowded.Zones[1].Resources = topologyv1alpha1.ResourceInfoList{
+		MakeTopologyResInfo(cpu, "8", "2"),
+		MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+	}
+	if got := ownerSpreadScore(second, util.GetPodEffectiveRequest(second), "host0", crowded.Zones, ownerPods, 50); got != 40 {
+		t.Errorf("unexpected penalty with only crowded zones available: got %d expected 40", got)
+	}
+
+	// other workloads are not affected
+	other := makeReplicaPod("other-1")
+	other.OwnerReferences[0].UID = types.UID("rs-2")
+	if ownerPods := nrtCache.OwnerPodsPerZone("host0", other); len(ownerPods) != 0 {
+		t.Errorf("unexpected owner pods per zone for another owner: %v", ownerPods)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+)
+
+// ResourcePCIeBandwidthSuffix is the suffix of the names of the resources representing the PCIe bandwidth
+// of a NUMA zone, e.g. "vendor.com/pcie-bandwidth". The bandwidth is usable only by the devices attached
+// to the same zone, so a container requesting bandwidth gets it from the zone of its devices.
+const ResourcePCIeBandwidthSuffix = "/pcie-bandwidth"
+
+func isPCIeBandwidthResource(resourceName v1.ResourceName) bool {
+	return strings.HasSuffix(string(resourceName), ResourcePCIeBandwidthSuffix)
+}
+
+// requestsPCIeBandwidth returns true if any container of the pod requests PCIe bandwidth.
+func requestsPCIeBandwidth(pod *v1.Pod) bool {
+	for _, container := range pod.Spec.InitContainers {
+		if containerRequestsPCIeBandwidth(container) {
+			return true
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if containerRequestsPCIeBandwidth(container) {
+			return true
+		}
+	}
+	return false
+}
+
+func containerRequestsPCIeBandwidth(container v1.Container) bool {
+	for resourceName, qty := range container.Resources.Requests {
+		if isPCIeBandwidthResource(resourceName) && !qty.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// pcieDeviceRequests returns the requests of devices and PCIe bandwidth of the container, which must be
+// satisfied by the same NUMA zone. The native resources, like CPUs and memory, are left to the node policy.
+func pcieDeviceRequests(container v1.Container) v1.ResourceList {
+	res := v1.ResourceList{}
+	for resourceName, qty := range container.Resources.Requests {
+		if v1helper.IsNativeResource(resourceName) {
+			continue
+		}
+		res[resourceName] = qty
+	}
+	return res
+}
+
+// pcieBandwidthHandler admits the pod only if each container requesting PCIe bandwidth can get the bandwidth and
+// its devices from the same NUMA zone, regardless of the policy of the node. Init containers run serially, so their
+// requests are not accumulated, while the requests of the app containers are.
+func pcieBandwidthHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+	klog.V(5).InfoS("PCIe bandwidth handler")
+
+	nodes := createNUMANodeList(zones)
+	qos := v1qos.GetPodQOS(pod)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes("pcie bandwidth handler NUMA resources", nodeInfo.Node().Name, nodes)
+
+	for _, initContainer := range pod.Spec.InitContainers {
+		if !containerRequestsPCIeBandwidth(initContainer) {
+			continue
+		}
+		logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, initContainer.Name)
+		resources := pcieDeviceRequests(initContainer)
+		klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, resources)...)
+
+		if _, match := resourcesAvailableInAnyNUMANodes(logID, nodes, resources, qos, nodeInfo); !match {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot align PCIe bandwidth of init container: %s", initContainer.Name))
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if !containerRequestsPCIeBandwidth(container) {
+			continue
+		}
+		logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, container.Name)
+		resources := pcieDeviceRequests(container)
+		klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, resources)...)
+
+		numaID, match := resourcesAvailableInAnyNUMANodes(logID, nodes, resources, qos, nodeInfo)
+		if !match {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot align PCIe bandwidth of container: %s", container.Name))
+		}
+		subtractFromNUMA(nodes, numaID, v1.Container{Resources: v1.ResourceRequirements{Requests: resources}})
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+const pcieBandwidthResourceName = "vendor/pcie-bandwidth"
+
+func TestNodeResourceTopologyPCIeBandwidth(t *testing.T) {
+	makeNRT := func(name, deviceAvailable string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: name},
+			TopologyPolicies: []string{string(topologyv1alpha1.BestEffortContainerLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+						MakeTopologyResInfo(nicResourceName, "1", "1"),
+						MakeTopologyResInfo(pcieBandwidthResourceName, "10G", "2G"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+						MakeTopologyResInfo(nicResourceName, "1", deviceAvailable),
+						MakeTopologyResInfo(pcieBandwidthResourceName, "10G", "10G"),
+					},
+				},
+			},
+		}
+	}
+	// the free device and the free bandwidth are on different zones
+	splitNRT := makeNRT("split", "0")
+	fitNRT := makeNRT("fit", "1")
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(splitNRT)
+	fakeInformer.Informer().GetStore().Add(fitNRT)
+
+	tm := TopologyMatch{
+		filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	makeTestPod := func(res v1.ResourceList) *v1.Pod {
+		res[v1.ResourceCPU] = resource.MustParse("1")
+		res[v1.ResourceMemory] = resource.MustParse("1Gi")
+		return makePod("testpod", withMultiContainers([]v1.ResourceList{res}))
+	}
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		pod        *v1.Pod
+		wantStatus *framework.Status
+	}{
+		{
+			name: "device without bandwidth",
+			nrt:  splitNRT,
+			pod: makeTestPod(v1.ResourceList{
+				nicResourceName: resource.MustParse("1"),
+			}),
+		},
+		{
+			name: "device and bandwidth on different zones",
+			nrt:  splitNRT,
+			pod: makeTestPod(v1.ResourceList{
+				nicResourceName:           resource.MustParse("1"),
+				pcieBandwidthResourceName: resource.MustParse("4G"),
+			}),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align PCIe bandwidth of container: cnt-1"),
+		},
+		{
+			name: "device and bandwidth on the same zone",
+			nrt:  fitNRT,
+			pod: makeTestPod(v1.ResourceList{
+				nicResourceName:           resource.MustParse("1"),
+				pcieBandwidthResourceName: resource.MustParse("4G"),
+			}),
+		},
+		{
+			name: "device and little bandwidth on the same zone",
+			nrt:  splitNRT,
+			pod: makeTestPod(v1.ResourceList{
+				nicResourceName:           resource.MustParse("1"),
+				pcieBandwidthResourceName: resource.MustParse("2G"),
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), tt.pod, nodeInfo)
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRequestsPCIeBandwidth(t *testing.T) {
+	pod := makePod("testpod", withMultiContainers([]v1.ResourceList{
+		{nicResourceName: resource.MustParse("1")},
+	}))
+	if requestsPCIeBandwidth(pod) {
+		t.Errorf("pod without bandwidth requests detected as requesting bandwidth")
+	}
+	pod.Spec.InitContainers = []v1.Container{
+		{
+			Name: "init",
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{pcieBandwidthResourceName: resource.MustParse("1G")},
+			},
+		},
+	}
+	if !requestsPCIeBandwidth(pod) {
+		t.Errorf("pod with bandwidth requests in init containers not detected")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
@@ -39,7 +40,9 @@ const (
 
 type NUMANode struct {
 	NUMAID    int
+	Name      string
 	Resources v1.ResourceList
+	Capacity  v1.ResourceList
 }
 
 type NUMANodeList []NUMANode
@@ -96,12 +99,20 @@ func leastNUMAscoreHandlers() scoreHandlersMap {
 
 // TopologyMatch plugin which run simplified version of TopologyManager's admit handler
 type TopologyMatch struct {
-	filterHandlers      filterHandlersMap
-	scoringHandlers     scoreHandlersMap
-	resourceToWeightMap resourceToWeightMap
-	nrtCache            nrtcache.Interface
+	filterHandlers          filterHandlersMap
+	scoringHandlers         scoreHandlersMap
+	lifetimeScoringHandlers map[string]scoreHandlersMap
+	resourceToWeightMap     resourceToWeightMap
+	nrtCache                nrtcache.Interface
+	evalLatency             *evalLatencyTracker
+	ignoredResources        sets.String
+	roundingPolicies        map[string]apiconfig.ResourceRoundingPolicy
+	spillableResources      sets.String
+	missingNRTPolicy        apiconfig.MissingNRTPolicy
+	maxZonesForSubsetSearch int
 }
 
+var _ framework.PreFilterPlugin = &TopologyMatch{}
 var _ framework.FilterPlugin = &TopologyMatch{}
 var _ framework.ReservePlugin = &TopologyMatch{}
 var _ framework.ScorePlugin = &TopologyMatch{}
@@ -120,6 +131,25 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		return nil, fmt.Errorf("want args to be of type NodeResourceTopologyMatchArgs, got %T", args)
 	}
 
+	if err := validateRoundingPolicies(tcfg.ResourceRoundingPolicies); err != nil {
+		return nil, err
+	}
+	if err := validateMissingNRTPolicy(tcfg.MissingNRTPolicy); err != nil {
+		return nil, err
+	}
+	if err := validateNegativeAvailabilityPolicy(tcfg.NegativeAvailabilityPolicy); err != nil {
+		return nil, err
+	}
+	if err := validateAccountedSchedulerNames(tcfg.AccountedSchedulerNames); err != nil {
+		return nil, err
+	}
+	if err := validateEvictionReleasePolicy(tcfg.EvictionReleasePolicy); err != nil {
+		return nil, err
+	}
+	if err := validateResourceGranularity(tcfg.ResourceGranularity); err != nil {
+		return nil, err
+	}
+
 	nrtCache, err := initNodeTopologyInformer(tcfg, handle)
 	if err != nil {
 		klog.ErrorS(err, "Cannot create clientset for NodeTopologyResource", "kubeConfig", handle.KubeConfig())
@@ -131,6 +161,11 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		resToWeightMap[v1.ResourceName(resource.Name)] = resource.Weight
 	}
 
+	maxZones := int(tcfg.MaxZonesForSubsetSearch)
+	if maxZones <= 0 {
+		maxZones = defaultMaxZonesForSubsetSearch
+	}
+
 	var scoringHandlers scoreHandlersMap
 
 	if tcfg.ScoringStrategy.Type == apiconfig.LeastNUMANodes {
@@ -144,11 +179,19 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		scoringHandlers = newScoringHandlers(strategy, resToWeightMap)
 	}
 
+	RegisterMetrics()
 	topologyMatch := &TopologyMatch{
-		filterHandlers:      newFilterHandlers(),
-		scoringHandlers:     scoringHandlers,
-		resourceToWeightMap: resToWeightMap,
-		nrtCache:            nrtCache,
+		filterHandlers:          newFilterHandlers(maxZones),
+		scoringHandlers:         scoringHandlers,
+		lifetimeScoringHandlers: newLifetimeScoringHandlers(resToWeightMap),
+		resourceToWeightMap:     resToWeightMap,
+		nrtCache:                nrtCache,
+		evalLatency:             sharedEvalLatency,
+		ignoredResources:        sets.NewString(tcfg.IgnoredResources...),
+		roundingPolicies:        tcfg.ResourceRoundingPolicies,
+		spillableResources:      sets.NewString(tcfg.SpillableResources...),
+		missingNRTPolicy:        tcfg.MissingNRTPolicy,
+		maxZonesForSubsetSearch: maxZones,
 	}
 
 	return topologyMatch, nil
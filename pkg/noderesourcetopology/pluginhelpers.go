@@ -24,6 +24,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -58,11 +59,21 @@ func initNodeTopologyInformer(tcfg *apiconfig.NodeResourceTopologyMatchArgs, han
 	}
 
 	podSharedInformer := nrtcache.InformerFromHandle(handle)
-	podIndexer := nrtcache.NewNodeNameIndexer(podSharedInformer)
-	nrtCache, err := nrtcache.NewOverReserve(nodeTopologyLister, podIndexer)
+	podIndexer := nrtcache.NewNodeNameIndexer(podSharedInformer, tcfg.AccountedSchedulerNames...)
+	nrtCache, err := nrtcache.NewOverReserve(nodeTopologyLister, podIndexer, int(tcfg.CacheResyncMismatchThreshold))
 	if err != nil {
 		return nil, err
 	}
+	nrtCache.RebuildInterval = time.Duration(tcfg.CacheRebuildPeriodSeconds) * time.Second
+	nrtCache.IgnoredResources = sets.NewString(tcfg.IgnoredResources...)
+	nrtCache.DiscountedResources = sets.NewString(tcfg.DiscountedResources...)
+	nrtCache.NRTTrustDelay = time.Duration(tcfg.NRTTrustDelaySeconds) * time.Second
+	nrtCache.StrictAvailability = tcfg.NegativeAvailabilityPolicy == apiconfig.NegativeAvailabilityReject
+	nrtCache.DiscountTerminatingPods = tcfg.DiscountTerminatingPods
+	nrtCache.EvictionRelease = evictionReleasePolicy(tcfg.EvictionReleasePolicy)
+	nrtCache.Granularity = resourceGranularity(tcfg.ResourceGranularity)
+	nrtcache.SetOverbookedZonesMetricByNode(tcfg.OverbookedZonesMetricByNode)
+	nrtcache.RegisterMetrics()
 
 	if fwk, ok := handle.(framework.Framework); ok {
 		profileName := fwk.ProfileName()
@@ -76,15 +87,21 @@ func initNodeTopologyInformer(tcfg *apiconfig.NodeResourceTopologyMatchArgs, han
 	resyncPeriod := time.Duration(tcfg.CacheResyncPeriodSeconds) * time.Second
 	go wait.Forever(nrtCache.Resync, resyncPeriod)
 
-	klog.V(3).InfoS("enable NodeTopology cache (needs the Reserve plugin)", "resyncPeriod", resyncPeriod)
+	klog.V(3).InfoS("enable NodeTopology cache (needs the Reserve plugin)", "resyncPeriod", resyncPeriod, "mismatchThreshold", tcfg.CacheResyncMismatchThreshold, "rebuildInterval", nrtCache.RebuildInterval, "ignoredResources", tcfg.IgnoredResources, "discountedResources", tcfg.DiscountedResources, "negativeAvailabilityPolicy", tcfg.NegativeAvailabilityPolicy, "accountedSchedulerNames", tcfg.AccountedSchedulerNames, "discountTerminatingPods", tcfg.DiscountTerminatingPods, "evictionReleasePolicy", tcfg.EvictionReleasePolicy, "resourceGranularity", tcfg.ResourceGranularity, "overbookedZonesMetricByNode", tcfg.OverbookedZonesMetricByNode)
 
 	return nrtCache, nil
 }
 
 func createNUMANodeList(zones topologyv1alpha1.ZoneList) NUMANodeList {
+	return createNUMANodeListWith(zones, extractResources)
+}
+
+// createNUMANodeListWith is like createNUMANodeList, but the resources of each NUMA node are extracted from its zone
+// with the given function.
+func createNUMANodeListWith(zones topologyv1alpha1.ZoneList, extract func(zone topologyv1alpha1.Zone) v1.ResourceList) NUMANodeList {
 	nodes := make(NUMANodeList, 0, len(zones))
 	for _, zone := range zones {
-		if zone.Type != "Node" {
+		if zone.Type != nrtcache.ZoneTypeNUMANode {
 			continue
 		}
 		var numaID int
@@ -97,9 +114,9 @@ func createNUMANodeList(zones topologyv1alpha1.ZoneList) NUMANodeList {
 			klog.ErrorS(nil, "Invalid NUMA id range", "numaID", numaID)
 			continue
 		}
-		resources := extractResources(zone)
+		resources := extract(zone)
 		klog.V(6).InfoS("extracted NUMA resources", stringify.ResourceListToLoggable(zone.Name, resources)...)
-		nodes = append(nodes, NUMANode{NUMAID: numaID, Resources: resources})
+		nodes = append(nodes, NUMANode{NUMAID: numaID, Name: zone.Name, Resources: resources, Capacity: extractCapacity(zone)})
 	}
 	return nodes
 }
@@ -215,21 +232,39 @@ func extractResources(zone topologyv1alpha1.Zone) v1.ResourceList {
 	return res
 }
 
-func newFilterHandlers() filterHandlersMap {
+func extractCapacity(zone topologyv1alpha1.Zone) v1.ResourceList {
+	res := make(v1.ResourceList)
+	for _, resInfo := range zone.Resources {
+		capacity := nrtcache.ResourceCapacity(resInfo)
+		res[v1.ResourceName(resInfo.Name)] = capacity.DeepCopy()
+	}
+	return res
+}
+
+func newFilterHandlers(maxZones int) filterHandlersMap {
 	return filterHandlersMap{
 		topologyv1alpha1.SingleNUMANodePodLevel:       singleNUMAPodLevelHandler,
 		topologyv1alpha1.SingleNUMANodeContainerLevel: singleNUMAContainerLevelHandler,
+		topologyv1alpha1.RestrictedPodLevel:           newRestrictedPodLevelHandler(maxZones),
+		topologyv1alpha1.RestrictedContainerLevel:     newRestrictedContainerLevelHandler(maxZones),
 	}
 }
 
 func newScoringHandlers(strategy scoreStrategy, resourceToWeightMap resourceToWeightMap) scoreHandlersMap {
+	podScope := func(pod *v1.Pod, zones topologyv1alpha1.ZoneList) (int64, *framework.Status) {
+		return podScopeScore(pod, zones, strategy, resourceToWeightMap)
+	}
+	containerScope := func(pod *v1.Pod, zones topologyv1alpha1.ZoneList) (int64, *framework.Status) {
+		return containerScopeScore(pod, zones, strategy, resourceToWeightMap)
+	}
 	return scoreHandlersMap{
-		topologyv1alpha1.SingleNUMANodePodLevel: func(pod *v1.Pod, zones topologyv1alpha1.ZoneList) (int64, *framework.Status) {
-			return podScopeScore(pod, zones, strategy, resourceToWeightMap)
-		},
-		topologyv1alpha1.SingleNUMANodeContainerLevel: func(pod *v1.Pod, zones topologyv1alpha1.ZoneList) (int64, *framework.Status) {
-			return containerScopeScore(pod, zones, strategy, resourceToWeightMap)
-		},
+		topologyv1alpha1.SingleNUMANodePodLevel:       podScope,
+		topologyv1alpha1.SingleNUMANodeContainerLevel: containerScope,
+		// best-effort nodes never filter out pods, but we still want to prefer the better aligned placements
+		topologyv1alpha1.BestEffortPodLevel:       podScope,
+		topologyv1alpha1.BestEffortContainerLevel: containerScope,
+		topologyv1alpha1.RestrictedPodLevel:       podScope,
+		topologyv1alpha1.RestrictedContainerLevel: containerScope,
 	}
 }
 
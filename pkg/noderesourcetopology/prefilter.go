@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+const preFilterStateKey = framework.StateKey("PreFilter" + Name)
+
+// preFilterState holds the data about the pod which doesn't depend on the node, computed once per scheduling cycle
+// in the PreFilter phase, so the Filter and Score phases don't compute it again for each node. The data is never
+// changed once written.
+type preFilterState struct {
+	// pod is the pod without the ignored resources. Must not be modified.
+	pod *v1.Pod
+	qos v1.PodQOSClass
+	// requests is the effective request of the pod, including the init containers and the overhead.
+	requests v1.ResourceList
+	// skip is true if the pod doesn't request any resource the NUMA zones account, so it fits on any node.
+	skip bool
+}
+
+// Clone returns the same data, which is immutable.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+func (tm *TopologyMatch) computePreFilterState(pod *v1.Pod) *preFilterState {
+	pod = withoutIgnoredResources(pod, tm.ignoredResources)
+	qos := v1qos.GetPodQOS(pod)
+	return &preFilterState{
+		pod:      pod,
+		qos:      qos,
+		requests: util.GetPodEffectiveRequest(pod),
+		skip:     qos == v1.PodQOSBestEffort && !hasNonNativeResource(pod),
+	}
+}
+
+// PreFilter computes the data about the pod shared by the Filter and Score phases of the scheduling cycle.
+func (tm *TopologyMatch) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	state := tm.computePreFilterState(pod)
+	klog.V(6).InfoS("prefilter", "logID", klog.KObj(pod), "qos", state.qos, "skip", state.skip)
+	cycleState.Write(preFilterStateKey, state)
+	return nil, nil
+}
+
+// PreFilterExtensions returns nil, because the data about the pod doesn't depend on the other pods.
+func (tm *TopologyMatch) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// preFilterStateFor returns the data about the pod computed in the PreFilter phase, computing it again if it's missing,
+// like when the PreFilter plugin is not enabled.
+func (tm *TopologyMatch) preFilterStateFor(cycleState *framework.CycleState, pod *v1.Pod) *preFilterState {
+	if cycleState != nil {
+		if data, err := cycleState.Read(preFilterStateKey); err == nil {
+			if state, ok := data.(*preFilterState); ok {
+				return state
+			}
+		}
+	}
+	return tm.computePreFilterState(pod)
+}
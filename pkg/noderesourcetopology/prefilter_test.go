@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestPreFilterState(t *testing.T) {
+	tests := []struct {
+		name             string
+		pod              *v1.Pod
+		expectedQOS      v1.PodQOSClass
+		expectedRequests v1.ResourceList
+		expectedSkip     bool
+	}{
+		{
+			name:         "best effort pod",
+			pod:          makePod("pod-be"),
+			expectedQOS:  v1.PodQOSBestEffort,
+			expectedSkip: true,
+		},
+		{
+			name: "best effort pod with devices",
+			pod: makePodByResourceList(&v1.ResourceList{
+				nicResourceName: resource.MustParse("1"),
+			}),
+			expectedQOS: v1.PodQOSBestEffort,
+			expectedRequests: v1.ResourceList{
+				nicResourceName: resource.MustParse("1"),
+			},
+		},
+		{
+			name: "guaranteed pod with init containers",
+			pod: makePod("pod-gu",
+				withMultiContainers([]v1.ResourceList{
+					{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("2Gi")},
+					{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("2Gi")},
+				}),
+				withMultiInitContainers([]v1.ResourceList{
+					{v1.ResourceCPU: resource.MustParse("6"), v1.ResourceMemory: resource.MustParse("1Gi")},
+				})),
+			expectedQOS: v1.PodQOSGuaranteed,
+			expectedRequests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("6"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		{
+			name: "guaranteed pod with ignored resources",
+			pod: makePod("pod-ignored",
+				withMultiContainers([]v1.ResourceList{
+					{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("2Gi"), v1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+				})),
+			expectedQOS: v1.PodQOSGuaranteed,
+			expectedRequests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+	}
+
+	tm := TopologyMatch{
+		ignoredResources: sets.NewString(string(v1.ResourceEphemeralStorage)),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycleState := framework.NewCycleState()
+			_, status := tm.PreFilter(context.Background(), cycleState, tt.pod)
+			if !status.IsSuccess() {
+				t.Fatalf("unexpected prefilter status: %v", status)
+			}
+
+			data, err := cycleState.Read(preFilterStateKey)
+			if err != nil {
+				t.Fatalf("missing prefilter state: %v", err)
+			}
+			state, ok := data.(*preFilterState)
+			if !ok {
+				t.Fatalf("unexpected prefilter state type %T", data)
+			}
+			if state.qos != tt.expectedQOS {
+				t.Errorf("qos %q expected %q", state.qos, tt.expectedQOS)
+			}
+			if state.skip != tt.expectedSkip {
+				t.Errorf("skip %v expected %v", state.skip, tt.expectedSkip)
+			}
+			if len(state.requests) != len(tt.expectedRequests) {
+				t.Fatalf("requests %v expected %v", state.requests, tt.expectedRequests)
+			}
+			for resName, expected := range tt.expectedRequests {
+				if got := state.requests[resName]; got.Cmp(expected) != 0 {
+					t.Errorf("request for %s: %s expected %s", resName, got.String(), expected.String())
+				}
+			}
+
+			if got := tm.preFilterStateFor(cycleState, tt.pod); got != state {
+				t.Errorf("the filter and score phases don't reuse the prefilter state")
+			}
+		})
+	}
+}
+
+func TestPreFilterStateMissing(t *testing.T) {
+	tm := TopologyMatch{}
+	state := tm.preFilterStateFor(framework.NewCycleState(), makePod("pod-be"))
+	if !state.skip {
+		t.Errorf("best effort pod not skipped without the prefilter state")
+	}
+}
+
+func TestFilterSkipsBestEffortPod(t *testing.T) {
+	tm := TopologyMatch{}
+	pod := makePod("pod-be")
+	cycleState := framework.NewCycleState()
+	if _, status := tm.PreFilter(context.Background(), cycleState, pod); !status.IsSuccess() {
+		t.Fatalf("unexpected prefilter status: %v", status)
+	}
+
+	// no cache is set, so the filter would fail if it evaluated the node
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{})
+	if status := tm.Filter(context.Background(), cycleState, pod, nodeInfo); !status.IsSuccess() {
+		t.Errorf("best effort pod rejected: %v", status)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+const (
+	// AnnotationPriorZone records the NUMA zone a pod was previously running on, in the "<node name>/<zone name>" format.
+	// When a pod is rescheduled, landing on the same zone can preserve warm caches, so the zone is preferred when scoring.
+	AnnotationPriorZone = "noderesourcetopology/prior-zone"
+
+	// priorZoneScoreBoost is added to the score of the node owning the prior zone of the pod, if the zone can still fit the pod.
+	priorZoneScoreBoost = framework.MaxNodeScore / 10
+)
+
+// priorZoneFromPod returns the node name and the zone name recorded in the prior zone annotation of the pod, if any.
+func priorZoneFromPod(pod *v1.Pod) (string, string, bool) {
+	if pod.Annotations == nil {
+		return "", "", false
+	}
+	val, ok := pod.Annotations[AnnotationPriorZone]
+	if !ok {
+		return "", "", false
+	}
+	nodeName, zoneName, ok := strings.Cut(val, "/")
+	if !ok || nodeName == "" || zoneName == "" {
+		klog.V(4).InfoS("malformed prior zone annotation", "logID", klog.KObj(pod), "value", val)
+		return "", "", false
+	}
+	return nodeName, zoneName, true
+}
+
+// priorZoneScore boosts the given score if the pod prior zone belongs to the node and still has enough
+// available resources to fit the whole pod. The returned score never exceeds framework.MaxNodeScore.
+func priorZoneScore(pod *v1.Pod, nodeName string, zones topologyv1alpha1.ZoneList, score int64) int64 {
+	priorNodeName, priorZoneName, ok := priorZoneFromPod(pod)
+	if !ok || priorNodeName != nodeName {
+		return score
+	}
+
+	for _, zone := range zones {
+		if zone.Type != nrtcache.ZoneTypeNUMANode || zone.Name != priorZoneName {
+			continue
+		}
+		// only guaranteed pods are scored
+		if !resourcesFitCombination(v1.PodQOSGuaranteed, util.GetPodEffectiveRequest(pod), extractResources(zone)) {
+			klog.V(5).InfoS("prior zone cannot fit pod", "logID", klog.KObj(pod), "node", nodeName, "zone", priorZoneName)
+			return score
+		}
+		boosted := score + priorZoneScoreBoost
+		if boosted > framework.MaxNodeScore {
+			boosted = framework.MaxNodeScore
+		}
+		klog.V(5).InfoS("prior zone score boost", "logID", klog.KObj(pod), "node", nodeName, "zone", priorZoneName, "score", score, "boosted", boosted)
+		return boosted
+	}
+	return score
+}
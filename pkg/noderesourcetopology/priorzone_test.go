@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+)
+
+func TestPriorZoneScore(t *testing.T) {
+	zones := topologyv1alpha1.ZoneList{
+		{
+			Name: "node-0",
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "4", "1"),
+				MakeTopologyResInfo(memory, "8Gi", "1Gi"),
+			},
+		},
+		{
+			Name: "node-1",
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "4", "4"),
+				MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		priorZone string
+		score     int64
+		expected  int64
+	}{
+		{
+			name:     "no annotation",
+			score:    50,
+			expected: 50,
+		},
+		{
+			name:      "malformed annotation",
+			priorZone: "node-1",
+			score:     50,
+			expected:  50,
+		},
+		{
+			name:      "prior zone on another node",
+			priorZone: "other-node/node-1",
+			score:     50,
+			expected:  50,
+		},
+		{
+			name:      "prior zone unknown",
+			priorZone: "test-node/node-7",
+			score:     50,
+			expected:  50,
+		},
+		{
+			name:      "prior zone cannot fit",
+			priorZone: "test-node/node-0",
+			score:     50,
+			expected:  50,
+		},
+		{
+			name:      "prior zone fits",
+			priorZone: "test-node/node-1",
+			score:     50,
+			expected:  50 + priorZoneScoreBoost,
+		},
+		{
+			name:      "prior zone fits, capped score",
+			priorZone: "test-node/node-1",
+			score:     framework.MaxNodeScore - 1,
+			expected:  framework.MaxNodeScore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			})
+			if tt.priorZone != "" {
+				pod.Annotations = map[string]string{
+					AnnotationPriorZone: tt.priorZone,
+				}
+			}
+
+			got := priorZoneScore(pod, "test-node", zones, tt.score)
+			if got != tt.expected {
+				t.Errorf("wrong score: got %d expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRescheduledPodPrefersPriorZone(t *testing.T) {
+	nodesMap, lister := initTest(topologyv1alpha1.SingleNUMANodeContainerLevel)
+
+	tm := &TopologyMatch{
+		filterHandlers:  newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		scoringHandlers: newScoringHandlers(leastAllocatedScoreStrategy, nil),
+		nrtCache:        nrtcache.NewPassthrough(lister),
+	}
+
+	tests := []struct {
+		name      string
+		priorZone string
+		expected  string
+	}{
+		{
+			// see TestNodeResourceScorePlugin for the details of the score computation
+			name:     "fresh pod",
+			expected: "Node1",
+		},
+		{
+			name:      "rescheduled pod",
+			priorZone: "Node3/node-1",
+			expected:  "Node3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := makePodByResourceList(&v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewQuantity(2, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(20*1024*1024, resource.DecimalSI),
+			})
+			if tt.priorZone != "" {
+				pod.Annotations = map[string]string{
+					AnnotationPriorZone: tt.priorZone,
+				}
+			}
+
+			nodeToScore := make(nodeToScoreMap, len(nodesMap))
+			for _, node := range nodesMap {
+				score, status := tm.Score(context.Background(), framework.NewCycleState(), pod, node.Name)
+				if status != nil {
+					t.Fatalf("unexpected status for node %q: %v", node.Name, status)
+				}
+				nodeToScore[node.Name] = score
+			}
+
+			got := findMaxScoreNode(nodeToScore)
+			if got != tt.expected {
+				t.Errorf("failed to select the desired node: wanted: %q, got: %q (scores: %v)", tt.expected, got, nodeToScore)
+			}
+		})
+	}
+}
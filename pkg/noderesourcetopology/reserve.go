@@ -24,8 +24,15 @@ import (
 )
 
 func (tm *TopologyMatch) Reserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
-	tm.nrtCache.ReserveNodeResources(nodeName, pod)
-	// can't fail
+	// the reservation is charged only to the zones the pod is expected to be allocated from, when known
+	zoneNames, _ := tm.podZonesFromState(state, pod, nodeName)
+	if _, err := tm.nrtCache.ReserveNodeResources(nodeName, pod, zoneNames...); err != nil {
+		return framework.AsStatus(err)
+	}
+	if isSpreadReplicasPod(pod) && len(zoneNames) > 0 {
+		// so the later pods of the same owner can be spread away from these zones
+		tm.nrtCache.SetPodZones(nodeName, pod, zoneNames)
+	}
 	return framework.NewStatus(framework.Success, "")
 }
 
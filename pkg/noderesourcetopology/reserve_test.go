@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestReserveTargetZones(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "2"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", "8"),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		filter   bool
+		expected []string
+	}{
+		{
+			name:   "zones chosen in filter",
+			filter: true,
+			// only node-1 fits the pod, so only node-1 is charged
+			expected: []string{
+				"zone node-0: cpu 2/8, memory 8Gi/8Gi",
+				"zone node-1: cpu 4/8, memory 6Gi/8Gi",
+			},
+		},
+		{
+			name: "zones unknown",
+			// without the filter data, all the zones are charged
+			expected: []string{
+				"zone node-0: cpu 0/8, memory 6Gi/8Gi",
+				"zone node-1: cpu 4/8, memory 6Gi/8Gi",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+			nrtCache, err := nrtcache.NewOverReserve(fakeInformer.Lister(), emptyNodeIndexer{}, 0)
+			if err != nil {
+				t.Fatalf("cannot create the cache: %v", err)
+			}
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtCache,
+			}
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+
+			pod := makePod("pod-1", withMultiContainers([]v1.ResourceList{
+				{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			}))
+			state := framework.NewCycleState()
+			if tt.filter {
+				if status := tm.Filter(context.Background(), state, pod, nodeInfo); status != nil {
+					t.Fatalf("unexpected filter status: %v", status)
+				}
+			}
+			if status := tm.Reserve(context.Background(), state, pod, "host0"); !status.IsSuccess() {
+				t.Fatalf("unexpected reserve status: %v", status)
+			}
+
+			nrtCopy, ok := nrtCache.GetCachedNRTCopy("host0", makePod("pod-2"))
+			if !ok || nrtCopy == nil {
+				t.Fatalf("missing cached data")
+			}
+			for zi, zone := range nrtCopy.Zones {
+				if got := stringify.Zone(zone); got != tt.expected[zi] {
+					t.Errorf("unexpected zone accounting:\ngot:      %s\nexpected: %s", got, tt.expected[zi])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"gonum.org/v1/gonum/stat/combin"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// The restricted policy lets a pod (or a container) span more than one NUMA node, but only if the
+// resources can be allocated from the narrowest set of NUMA nodes which could ever satisfy the request,
+// which is what the kubelet considers the preferred allocation. The narrowest set is computed using
+// the zone capacity, while the actual allocation is checked against the zone availability.
+// https://kubernetes.io/docs/tasks/administer-cluster/topology-manager/#policy-restricted
+
+// defaultMaxZonesForSubsetSearch is the maximum number of NUMA zones searched exhaustively when the plugin args don't set it.
+const defaultMaxZonesForSubsetSearch = 8
+
+func newRestrictedContainerLevelHandler(maxZones int) filterFn {
+	return func(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+		return restrictedContainerLevelHandler(pod, zones, nodeInfo, maxZones)
+	}
+}
+
+func newRestrictedPodLevelHandler(maxZones int) filterFn {
+	return func(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+		return restrictedPodLevelHandler(pod, zones, nodeInfo, maxZones)
+	}
+}
+
+func restrictedContainerLevelHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo, maxZones int) *framework.Status {
+	klog.V(5).InfoS("Restricted container handler")
+
+	nodes := createNUMANodeList(zones)
+	qos := v1qos.GetPodQOS(pod)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes("restricted container handler NUMA resources", nodeInfo.Node().Name, nodes)
+
+	// init containers run serially and before the app containers, so their resources are not accumulated
+	for _, initContainer := range pod.Spec.InitContainers {
+		logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, initContainer.Name)
+		klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, initContainer.Resources.Requests)...)
+
+		if _, match := resourcesAvailableInMinimalNUMANodes(logID, nodes, initContainer.Resources.Requests, qos, nodeInfo, maxZones); !match {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot align init container: %s", initContainer.Name))
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, container.Name)
+		klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, container.Resources.Requests)...)
+
+		numaIdxs, match := resourcesAvailableInMinimalNUMANodes(logID, nodes, container.Resources.Requests, qos, nodeInfo, maxZones)
+		if !match {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot align container: %s", container.Name))
+		}
+
+		// subtract the resources requested by the container from the selected NUMA nodes,
+		// so we won't allocate the same resources for the upcoming containers
+		subtractFromNUMAs(container.Resources.Requests, nodes, numaIdxs...)
+	}
+	return nil
+}
+
+func restrictedPodLevelHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo, maxZones int) *framework.Status {
+	klog.V(5).InfoS("Restricted pod handler")
+
+	resources := util.GetPodEffectiveRequest(pod)
+
+	logID := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	nodes := createNUMANodeList(zones)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes("restricted pod handler NUMA resources", nodeInfo.Node().Name, nodes)
+	klog.V(6).InfoS("target resources", stringify.ResourceListToLoggable(logID, resources)...)
+
+	if _, match := resourcesAvailableInMinimalNUMANodes(logID, nodes, resources, v1qos.GetPodQOS(pod), nodeInfo, maxZones); !match {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot align pod: %s", pod.Name))
+	}
+	return nil
+}
+
+// resourcesAvailableInMinimalNUMANodes checks if the given resources can be allocated from the narrowest NUMA nodes
+// set which can satisfy them, and returns the indexes (in numaNodes, NOT the NUMA IDs) of the selected NUMA nodes.
+// The search is exponential in the number of NUMA nodes, so past maxZones NUMA nodes greedyNUMANodes is used instead.
+func resourcesAvailableInMinimalNUMANodes(logID string, numaNodes NUMANodeList, resources v1.ResourceList, qos v1.PodQOSClass, nodeInfo *framework.NodeInfo, maxZones int) ([]int, bool) {
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	nodeName := nodeInfo.Node().Name
+	nodeResources := util.ResourceList(nodeInfo.Allocatable)
+
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		if _, ok := nodeResources[resource]; !ok {
+			// see resourcesAvailableInAnyNUMANodes for the rationale
+			klog.V(5).InfoS("early verdict: cannot meet request", "logID", logID, "node", nodeName, "resource", resource, "suitable", "false")
+			return nil, false
+		}
+	}
+
+	capacityNodes := make(NUMANodeList, 0, len(numaNodes))
+	for _, numaNode := range numaNodes {
+		capacityNodes = append(capacityNodes, NUMANode{NUMAID: numaNode.NUMAID, Name: numaNode.Name, Resources: numaNode.Capacity})
+	}
+
+	if len(numaNodes) > maxZones {
+		klog.V(4).InfoS("too many NUMA zones to search exhaustively, falling back to the greedy heuristic", "logID", logID, "node", nodeName, "zones", len(numaNodes), "maxZones", maxZones)
+		return greedyMinimalNUMANodes(logID, nodeName, capacityNodes, numaNodes, resources, qos)
+	}
+
+	reported := numaReportedResources(numaNodes)
+	minNUMANodes := 0
+	for i := 1; i <= len(capacityNodes) && minNUMANodes == 0; i++ {
+		for _, combination := range combin.Combinations(len(capacityNodes), i) {
+			if resourcesFitCombination(qos, resources, combineNUMAResources(capacityNodes, reported, combination)) {
+				minNUMANodes = i
+				break
+			}
+		}
+	}
+	if minNUMANodes == 0 {
+		klog.V(5).InfoS("final verdict: request exceeds the node capacity", "logID", logID, "node", nodeName, "suitable", false)
+		return nil, false
+	}
+
+	for _, combination := range combin.Combinations(len(numaNodes), minNUMANodes) {
+		if resourcesFitCombination(qos, resources, combineNUMAResources(numaNodes, reported, combination)) {
+			klog.V(5).InfoS("final verdict", "logID", logID, "node", nodeName, "NUMANodes", minNUMANodes, "suitable", true)
+			return combination, true
+		}
+	}
+
+	klog.V(5).InfoS("final verdict", "logID", logID, "node", nodeName, "NUMANodes", minNUMANodes, "suitable", false)
+	return nil, false
+}
+
+// greedyMinimalNUMANodes approximates resourcesAvailableInMinimalNUMANodes in polynomial time: the narrowest NUMA nodes
+// set is estimated greedily from the capacity, and the resources are allocated greedily from the availability, accepting
+// the allocation if it is not wider than the estimated set. The estimate may be wider than the actual narrowest set,
+// so the heuristic may accept allocations the kubelet would reject, but never rejects an allocation fitting the estimate.
+func greedyMinimalNUMANodes(logID, nodeName string, capacityNodes, numaNodes NUMANodeList, resources v1.ResourceList, qos v1.PodQOSClass) ([]int, bool) {
+	estimated, ok := greedyNUMANodes(capacityNodes, resources, qos)
+	if !ok {
+		klog.V(5).InfoS("final verdict: request exceeds the node capacity", "logID", logID, "node", nodeName, "suitable", false)
+		return nil, false
+	}
+
+	selected, ok := greedyNUMANodes(numaNodes, resources, qos)
+	if !ok || len(selected) > len(estimated) {
+		klog.V(5).InfoS("final verdict", "logID", logID, "node", nodeName, "NUMANodes", len(estimated), "greedy", true, "suitable", false)
+		return nil, false
+	}
+	klog.V(5).InfoS("final verdict", "logID", logID, "node", nodeName, "NUMANodes", len(selected), "greedy", true, "suitable", true)
+	return selected, true
+}
+
+// greedyNUMANodes selects NUMA nodes until their combined resources satisfy the request, picking at each step the NUMA node
+// covering the largest share of the request still unmet. Returns the sorted indexes of the selected NUMA nodes.
+func greedyNUMANodes(numaNodes NUMANodeList, resources v1.ResourceList, qos v1.PodQOSClass) ([]int, bool) {
+	reported := numaReportedResources(numaNodes)
+	used := make([]bool, len(numaNodes))
+	var selected []int
+	for len(selected) < len(numaNodes) {
+		combined := combineNUMAResources(numaNodes, reported, selected)
+		if len(selected) > 0 && resourcesFitCombination(qos, resources, combined) {
+			sort.Ints(selected)
+			return selected, true
+		}
+
+		best, bestCoverage := -1, -1.0
+		for idx, numaNode := range numaNodes {
+			if used[idx] {
+				continue
+			}
+			coverage := unmetRequestCoverage(resources, combined, numaNode.Resources)
+			if coverage > bestCoverage {
+				best, bestCoverage = idx, coverage
+			}
+		}
+		used[best] = true
+		selected = append(selected, best)
+	}
+
+	if len(selected) == 0 || !resourcesFitCombination(qos, resources, combineNUMAResources(numaNodes, reported, selected)) {
+		return nil, false
+	}
+	sort.Ints(selected)
+	return selected, true
+}
+
+// unmetRequestCoverage returns which share, on average over the requested resources, of the request not yet met
+// by the combined resources the given NUMA node resources can meet.
+func unmetRequestCoverage(resources, combined, numaResources v1.ResourceList) float64 {
+	coverage := 0.0
+	for resName, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		unmet := quantity.DeepCopy()
+		if have, ok := combined[resName]; ok {
+			unmet.Sub(have)
+		}
+		if unmet.Sign() <= 0 {
+			coverage += 1.0
+			continue
+		}
+		avail, ok := numaResources[resName]
+		if !ok {
+			continue
+		}
+		if avail.Cmp(unmet) >= 0 {
+			coverage += 1.0
+			continue
+		}
+		coverage += avail.AsApproximateFloat64() / unmet.AsApproximateFloat64()
+	}
+	return coverage / float64(len(resources))
+}
+
+// numaReportedResources returns the names of the resources reported by any of the given NUMA nodes.
+func numaReportedResources(numaNodes NUMANodeList) []v1.ResourceName {
+	reported := make(map[v1.ResourceName]struct{})
+	var names []v1.ResourceName
+	for _, numaNode := range numaNodes {
+		for name := range numaNode.Resources {
+			if _, ok := reported[name]; ok {
+				continue
+			}
+			reported[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// combineNUMAResources combines the resources of the given NUMA nodes like combineResources, but the resources reported
+// by any NUMA node and missing from the combination count as zero, not as resources without NUMA affinity.
+func combineNUMAResources(numaNodes NUMANodeList, reported []v1.ResourceName, combination []int) v1.ResourceList {
+	combined := combineResources(numaNodes, combination)
+	for _, name := range reported {
+		if _, ok := combined[name]; !ok {
+			combined[name] = resource.Quantity{}
+		}
+	}
+	return combined
+}
+
+// resourcesFitCombination returns true if the given resources can be allocated from the combined
+// resources of a set of NUMA nodes. Resources without NUMA affinity are ignored.
+func resourcesFitCombination(qos v1.PodQOSClass, resources, combinationResources v1.ResourceList) bool {
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		combinationQuantity, ok := combinationResources[resource]
+		if !ok {
+			// non NUMA resource
+			continue
+		}
+		if !isResourceSetSuitable(qos, resource, quantity, combinationQuantity) {
+			return false
+		}
+	}
+	return true
+}
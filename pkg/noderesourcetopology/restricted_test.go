@@ -0,0 +1,466 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestRestrictedVsSingleNUMANodeFilter(t *testing.T) {
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy, cpuCapacity, cpuAvailable string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, cpuCapacity, cpuAvailable),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, cpuCapacity, cpuAvailable),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	pod := makePod("testpod",
+		withMultiContainers([]v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse("6"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		}),
+	)
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "restricted pod scope, request spans the minimal two NUMAs - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "4", "4"),
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted container scope, request spans the minimal two NUMAs - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedContainerLevel, "4", "4"),
+			wantStatus: nil,
+		},
+		{
+			name:       "single-numa-node pod scope, request spans two NUMAs - not fit",
+			nrt:        makeNRT(topologyv1alpha1.SingleNUMANodePodLevel, "4", "4"),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+		{
+			name:       "single-numa-node container scope, request spans two NUMAs - not fit",
+			nrt:        makeNRT(topologyv1alpha1.SingleNUMANodeContainerLevel, "4", "4"),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align container: cnt-1"),
+		},
+		{
+			name:       "restricted pod scope, request would fit one NUMA but only two NUMAs have room - not fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "8", "4"),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+		{
+			name:       "restricted container scope, request would fit one NUMA but only two NUMAs have room - not fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedContainerLevel, "8", "4"),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align container: cnt-1"),
+		},
+		{
+			name:       "restricted pod scope, request fits one NUMA - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "8", "8"),
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted pod scope, capacity unset, request fits the availability of one NUMA - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "0", "8"),
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted container scope, capacity unset, request fits the availability of one NUMA - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedContainerLevel, "0", "8"),
+			wantStatus: nil,
+		},
+		{
+			name:       "single-numa-node pod scope, capacity unset, request fits the availability of one NUMA - fit",
+			nrt:        makeNRT(topologyv1alpha1.SingleNUMANodePodLevel, "0", "8"),
+			wantStatus: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(tt.nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRestrictedManyZonesFallback(t *testing.T) {
+	// way past what an exhaustive search can handle: the narrowest set is 10 zones out of 40
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy, cpuAvailable string) *topologyv1alpha1.NodeResourceTopology {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(policy)},
+		}
+		for i := 0; i < 40; i++ {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha1.Zone{
+				Name: fmt.Sprintf("node-%d", i),
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "2", cpuAvailable),
+					MakeTopologyResInfo(memory, "2Gi", "2Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+
+	pod := makePod("testpod",
+		withMultiContainers([]v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse("20"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		}),
+	)
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "restricted pod scope, request fits the narrowest zones - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "2"),
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted container scope, request fits the narrowest zones - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedContainerLevel, "2"),
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted pod scope, request fits only zones wider than the narrowest - not fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "1"),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(tt.nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+
+			start := time.Now()
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("filter took too long: %v", elapsed)
+			}
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRestrictedDeviceMissingFromZone(t *testing.T) {
+	// node-0 reports no devices at all, node-1 reports the devices but has none free
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy, nicAvailable string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", "8"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", "8"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+						MakeTopologyResInfo(nicResourceName, "4", nicAvailable),
+					},
+				},
+			},
+		}
+	}
+
+	pod := makePod("testpod",
+		withMultiContainers([]v1.ResourceList{
+			{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+				nicResourceName:   resource.MustParse("1"),
+			},
+		}),
+	)
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		maxZones   int
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "restricted pod scope, no free device - not fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "0"),
+			maxZones:   defaultMaxZonesForSubsetSearch,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+		{
+			name:       "restricted container scope, no free device - not fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedContainerLevel, "0"),
+			maxZones:   defaultMaxZonesForSubsetSearch,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align container: cnt-1"),
+		},
+		{
+			name:       "restricted pod scope, greedy search, no free device - not fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "0"),
+			maxZones:   1,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+		{
+			name:       "restricted pod scope, free device - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "1"),
+			maxZones:   defaultMaxZonesForSubsetSearch,
+			wantStatus: nil,
+		},
+		{
+			name:       "restricted pod scope, greedy search, free device - fit",
+			nrt:        makeNRT(topologyv1alpha1.RestrictedPodLevel, "1"),
+			maxZones:   1,
+			wantStatus: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(tt.nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(tt.maxZones),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestResourcesAvailableInMinimalNUMANodes(t *testing.T) {
+	zones := topologyv1alpha1.ZoneList{
+		{
+			Name: "node-0",
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "8", "8"),
+				MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+			},
+		},
+		{
+			Name: "node-1",
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "8", "4"),
+				MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				MakeTopologyResInfo(nicResourceName, "2", "2"),
+			},
+		},
+		{
+			Name: "node-2",
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "8", "4"),
+				MakeTopologyResInfo(memory, "8Gi", "4Gi"),
+				MakeTopologyResInfo(nicResourceName, "2", "2"),
+			},
+		},
+		{
+			// not a NUMA zone, never selected
+			Name: "socket-0",
+			Type: "Socket",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "64", "64"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		req           v1.ResourceList
+		expectedZones []string
+		expectedOk    bool
+	}{
+		{
+			name: "fits in one zone",
+			req: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("6"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			expectedZones: []string{"node-0"},
+			expectedOk:    true,
+		},
+		{
+			name: "device fits in one zone",
+			req: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("2"),
+				nicResourceName: resource.MustParse("1"),
+			},
+			expectedZones: []string{"node-1"},
+			expectedOk:    true,
+		},
+		{
+			name: "cpu requires two zones",
+			req: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			expectedZones: []string{"node-0", "node-1"},
+			expectedOk:    true,
+		},
+		{
+			// the kubelet prefers the narrowest set by capacity, node-1 alone, which has not enough cpu free
+			name: "the zone with enough cpu has no device",
+			req: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("6"),
+				nicResourceName: resource.MustParse("1"),
+			},
+		},
+		{
+			name: "devices require two zones",
+			req: v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("1"),
+				nicResourceName: resource.MustParse("3"),
+			},
+			expectedZones: []string{"node-1", "node-2"},
+			expectedOk:    true,
+		},
+		{
+			name: "resources without NUMA affinity are ignored",
+			req: v1.ResourceList{
+				v1.ResourceCPU:              resource.MustParse("1"),
+				v1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+			},
+			expectedZones: []string{"node-0"},
+			expectedOk:    true,
+		},
+		{
+			name: "exceeds all the zones",
+			req: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("20"),
+			},
+		},
+		{
+			name: "not enough devices",
+			req: v1.ResourceList{
+				nicResourceName: resource.MustParse("5"),
+			},
+		},
+	}
+
+	allocatable := makeResourceListFromZones(zones)
+	allocatable[v1.ResourceEphemeralStorage] = resource.MustParse("200Gi")
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "host0"},
+		Status: v1.NodeStatus{
+			Capacity:    allocatable,
+			Allocatable: allocatable,
+		},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createNUMANodeList(zones)
+			numaIdxs, ok := resourcesAvailableInMinimalNUMANodes(tt.name, nodes, tt.req, v1.PodQOSGuaranteed, nodeInfo, defaultMaxZonesForSubsetSearch)
+			var zoneNames []string
+			for _, idx := range numaIdxs {
+				zoneNames = append(zoneNames, fmt.Sprintf("node-%d", nodes[idx].NUMAID))
+			}
+			if ok != tt.expectedOk {
+				t.Fatalf("unexpected result: %v expected %v", ok, tt.expectedOk)
+			}
+			if !reflect.DeepEqual(zoneNames, tt.expectedZones) {
+				t.Errorf("unexpected zones: %v expected %v", zoneNames, tt.expectedZones)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// validateRoundingPolicies returns an error if any of the given resource rounding policies is unknown.
+func validateRoundingPolicies(policies map[string]apiconfig.ResourceRoundingPolicy) error {
+	for name, policy := range policies {
+		if policy != apiconfig.RoundingCeil && policy != apiconfig.RoundingFloor {
+			return fmt.Errorf("unknown rounding policy %q for resource %q", policy, name)
+		}
+	}
+	return nil
+}
+
+// isRoundedPolicy returns true if the fit check of the given policy rounds the requests. The kubelet allocates
+// whole units from a single zone only with the single-numa-node policy.
+func isRoundedPolicy(policyName topologyv1alpha1.TopologyManagerPolicy) bool {
+	return policyName == topologyv1alpha1.SingleNUMANodePodLevel || policyName == topologyv1alpha1.SingleNUMANodeContainerLevel
+}
+
+// roundQuantity rounds the given quantity to whole units according to the given policy.
+func roundQuantity(qty resource.Quantity, policy apiconfig.ResourceRoundingPolicy) resource.Quantity {
+	milli := qty.MilliValue()
+	switch policy {
+	case apiconfig.RoundingCeil:
+		return *resource.NewQuantity((milli+999)/1000, qty.Format)
+	case apiconfig.RoundingFloor:
+		return *resource.NewQuantity(milli/1000, qty.Format)
+	default:
+		return qty
+	}
+}
+
+// withRoundedRequests returns the pod with the requests and limits of its containers rounded to whole units according
+// to the given policies, so the fit check accounts for the whole units the kubelet allocates: a request of 2500m CPUs
+// consumes 3 CPUs of the zone. Returns the pod unchanged (not a copy) if no request needs rounding.
+func withRoundedRequests(pod *v1.Pod, policies map[string]apiconfig.ResourceRoundingPolicy) *v1.Pod {
+	if len(policies) == 0 || !podHasFractionalRequests(pod, policies) {
+		return pod
+	}
+	ret := pod.DeepCopy()
+	for idx := range ret.Spec.InitContainers {
+		roundResources(&ret.Spec.InitContainers[idx].Resources, policies)
+	}
+	for idx := range ret.Spec.Containers {
+		roundResources(&ret.Spec.Containers[idx].Resources, policies)
+	}
+	return ret
+}
+
+func podHasFractionalRequests(pod *v1.Pod, policies map[string]apiconfig.ResourceRoundingPolicy) bool {
+	containers := append([]v1.Container{}, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, cnt := range containers {
+		for _, resources := range []v1.ResourceList{cnt.Resources.Requests, cnt.Resources.Limits} {
+			for resName, qty := range resources {
+				if _, ok := policies[string(resName)]; ok && qty.MilliValue()%1000 != 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func roundResources(resources *v1.ResourceRequirements, policies map[string]apiconfig.ResourceRoundingPolicy) {
+	for _, resList := range []v1.ResourceList{resources.Requests, resources.Limits} {
+		for resName, qty := range resList {
+			policy, ok := policies[string(resName)]
+			if !ok {
+				continue
+			}
+			resList[resName] = roundQuantity(qty, policy)
+		}
+	}
+}
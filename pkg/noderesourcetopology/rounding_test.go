@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestRoundQuantity(t *testing.T) {
+	tests := []struct {
+		qty      string
+		policy   apiconfig.ResourceRoundingPolicy
+		expected string
+	}{
+		{qty: "2500m", policy: apiconfig.RoundingCeil, expected: "3"},
+		{qty: "2500m", policy: apiconfig.RoundingFloor, expected: "2"},
+		{qty: "2500m", expected: "2500m"},
+		{qty: "2", policy: apiconfig.RoundingCeil, expected: "2"},
+		{qty: "2", policy: apiconfig.RoundingFloor, expected: "2"},
+		{qty: "100m", policy: apiconfig.RoundingCeil, expected: "1"},
+		{qty: "100m", policy: apiconfig.RoundingFloor, expected: "0"},
+	}
+	for _, tt := range tests {
+		got := roundQuantity(resource.MustParse(tt.qty), tt.policy)
+		if got.Cmp(resource.MustParse(tt.expected)) != 0 {
+			t.Errorf("rounding %s with policy %q: got %s expected %s", tt.qty, tt.policy, got.String(), tt.expected)
+		}
+	}
+}
+
+func TestWithRoundedRequestsUnchanged(t *testing.T) {
+	policies := map[string]apiconfig.ResourceRoundingPolicy{
+		string(v1.ResourceCPU): apiconfig.RoundingCeil,
+	}
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("1500Mi"),
+	})
+	if got := withRoundedRequests(pod, policies); got != pod {
+		t.Errorf("pod with whole CPUs copied")
+	}
+
+	fractional := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2500m"),
+		v1.ResourceMemory: resource.MustParse("1500Mi"),
+	})
+	got := withRoundedRequests(fractional, policies)
+	if cpus := got.Spec.Containers[0].Resources.Requests[v1.ResourceCPU]; cpus.Cmp(resource.MustParse("3")) != 0 {
+		t.Errorf("unexpected rounded cpu request %s", cpus.String())
+	}
+	if mem := got.Spec.Containers[0].Resources.Requests[v1.ResourceMemory]; mem.Cmp(resource.MustParse("1500Mi")) != 0 {
+		t.Errorf("unexpected rounded memory request %s", mem.String())
+	}
+	if cpus := fractional.Spec.Containers[0].Resources.Requests[v1.ResourceCPU]; cpus.Cmp(resource.MustParse("2500m")) != 0 {
+		t.Errorf("original pod changed: cpu request %s", cpus.String())
+	}
+}
+
+func TestValidateRoundingPolicies(t *testing.T) {
+	if err := validateRoundingPolicies(map[string]apiconfig.ResourceRoundingPolicy{"cpu": apiconfig.RoundingFloor}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateRoundingPolicies(map[string]apiconfig.ResourceRoundingPolicy{"cpu": "Nearest"}); err == nil {
+		t.Errorf("unknown policy accepted")
+	}
+}
+
+func TestFilterFractionalCPURounding(t *testing.T) {
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy, availableCPU string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", availableCPU),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2500m"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+	pod.Name = "testpod"
+
+	ceil := map[string]apiconfig.ResourceRoundingPolicy{string(v1.ResourceCPU): apiconfig.RoundingCeil}
+	floor := map[string]apiconfig.ResourceRoundingPolicy{string(v1.ResourceCPU): apiconfig.RoundingFloor}
+	notFit := framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod")
+
+	tests := []struct {
+		name         string
+		policy       topologyv1alpha1.TopologyManagerPolicy
+		availableCPU string
+		rounding     map[string]apiconfig.ResourceRoundingPolicy
+		wantStatus   *framework.Status
+	}{
+		{
+			name:         "ceil, 2 cores free - not fit",
+			policy:       topologyv1alpha1.SingleNUMANodePodLevel,
+			availableCPU: "2",
+			rounding:     ceil,
+			wantStatus:   notFit,
+		},
+		{
+			name:         "ceil, 2.5 cores free - not fit",
+			policy:       topologyv1alpha1.SingleNUMANodePodLevel,
+			availableCPU: "2500m",
+			rounding:     ceil,
+			wantStatus:   notFit,
+		},
+		{
+			name:         "ceil container scope, 2.5 cores free - not fit",
+			policy:       topologyv1alpha1.SingleNUMANodeContainerLevel,
+			availableCPU: "2500m",
+			rounding:     ceil,
+			wantStatus:   notFit,
+		},
+		{
+			name:         "ceil, 3 cores free - fit",
+			policy:       topologyv1alpha1.SingleNUMANodePodLevel,
+			availableCPU: "3",
+			rounding:     ceil,
+		},
+		{
+			name:         "no rounding, 2.5 cores free - fit",
+			policy:       topologyv1alpha1.SingleNUMANodePodLevel,
+			availableCPU: "2500m",
+		},
+		{
+			name:         "floor, 2 cores free - fit",
+			policy:       topologyv1alpha1.SingleNUMANodePodLevel,
+			availableCPU: "2",
+			rounding:     floor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrt := makeNRT(tt.policy, tt.availableCPU)
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+
+			tm := TopologyMatch{
+				filterHandlers:   newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:         nrtcache.NewPassthrough(fakeInformer.Lister()),
+				roundingPolicies: tt.rounding,
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			// only the code matters, the reasons differ by scope
+			if gotStatus.Code() != tt.wantStatus.Code() {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -24,9 +24,9 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
-	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
 
 	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
@@ -36,7 +36,8 @@ const (
 	defaultWeight = int64(1)
 )
 
-type scoreStrategy func(v1.ResourceList, v1.ResourceList, resourceToWeightMap) int64
+// scoreStrategy scores a NUMA zone for the requested resources, given the resources available in the zone and its capacity.
+type scoreStrategy func(requested, allocatable, capacity v1.ResourceList, resourceToWeightMap resourceToWeightMap) int64
 
 // resourceToWeightMap contains resource name and weight.
 type resourceToWeightMap map[v1.ResourceName]int64
@@ -57,12 +58,16 @@ func (rw resourceToWeightMap) weight(r v1.ResourceName) int64 {
 
 func (tm *TopologyMatch) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
 	klog.V(6).InfoS("scoring node", "nodeName", nodeName)
+	podState := tm.preFilterStateFor(state, pod)
 	// if it's a non-guaranteed pod, every node is considered to be a good fit
-	if v1qos.GetPodQOS(pod) != v1.PodQOSGuaranteed {
+	if podState.qos != v1.PodQOSGuaranteed {
 		return framework.MaxNodeScore, nil
 	}
+	pod = podState.pod
 
-	nodeTopology, ok := tm.nrtCache.GetCachedNRTCopy(nodeName, pod)
+	defer tm.evalLatency.Track(nodeName)()
+
+	nodeTopology, ok := tm.nodeTopologyForScore(state, nodeName, pod)
 
 	if !ok {
 		klog.V(4).InfoS("noderesourcetopology is not valid for node", "node", nodeName)
@@ -85,8 +90,32 @@ func (tm *TopologyMatch) Score(ctx context.Context, state *framework.CycleState,
 		klog.V(4).InfoS("policy handler not found", "policy", policyName)
 		return 0, nil
 	}
+	if lifetime, ok := expectedLifetime(pod); ok {
+		if lifetimeHandler, ok := tm.lifetimeScoringHandlers[lifetime][topologyv1alpha1.TopologyManagerPolicy(policyName)]; ok {
+			handler = lifetimeHandler
+		}
+	}
+
+	zones := nodeTopology.Zones
+	if nrtcache.IsExclusiveZonePod(pod) {
+		// only the unused zones can host the pod, so we score the node on them
+		zones = unusedZones(zones)
+		if len(zones) == 0 {
+			klog.V(5).InfoS("no unused zones for exclusive zone pod", "node", nodeName)
+			return 0, nil
+		}
+	}
 
-	return handler(pod, nodeTopology.Zones)
+	score, status := handler(pod, zones)
+	if status != nil {
+		return score, status
+	}
+	score = distanceAwareScore(pod, zones, topologyv1alpha1.TopologyManagerPolicy(policyName), score, tm.maxZonesForSubsetSearch)
+	score = priorZoneScore(pod, nodeName, zones, score)
+	if isSpreadReplicasPod(pod) {
+		score = ownerSpreadScore(pod, podState.requests, nodeName, zones, tm.nrtCache.OwnerPodsPerZone(nodeName, pod), score)
+	}
+	return score, nil
 }
 
 func (tm *TopologyMatch) ScoreExtensions() framework.ScoreExtensions {
@@ -96,16 +125,14 @@ func (tm *TopologyMatch) ScoreExtensions() framework.ScoreExtensions {
 // scoreForEachNUMANode will iterate over all NUMA zones of the node and invoke the scoreStrategy func for every zone.
 // it will return the minimal score of all the calculated NUMA's score, in order to avoid edge cases.
 func scoreForEachNUMANode(requested v1.ResourceList, numaList NUMANodeList, score scoreStrategy, resourceToWeightMap resourceToWeightMap) int64 {
-	numaScores := make([]int64, len(numaList))
 	minScore := int64(0)
 
 	for _, numa := range numaList {
-		numaScore := score(requested, numa.Resources, resourceToWeightMap)
+		numaScore := score(requested, numa.Resources, numa.Capacity, resourceToWeightMap)
 		// if NUMA's score is 0, i.e. not fit at all, it won't be taken under consideration by Kubelet.
 		if (minScore == 0) || (numaScore != 0 && numaScore < minScore) {
 			minScore = numaScore
 		}
-		numaScores[numa.NUMAID] = numaScore
 		klog.V(6).InfoS("numa score result", "numaID", numa.NUMAID, "score", numaScore)
 	}
 	return minScore
@@ -130,7 +157,7 @@ func podScopeScore(pod *v1.Pod, zones topologyv1alpha1.ZoneList, scorerFn scoreS
 	// but it works with HintProviders, takes into account all possible allocations.
 	resources := util.GetPodEffectiveRequest(pod)
 
-	allocatablePerNUMA := createNUMANodeList(zones)
+	allocatablePerNUMA := createNUMANodeListForRequest(zones, resources)
 	finalScore := scoreForEachNUMANode(resources, allocatablePerNUMA, scorerFn, resourceToWeightMap)
 	klog.V(5).InfoS("pod scope scoring final node score", "finalScore", finalScore)
 	return finalScore, nil
@@ -141,10 +168,10 @@ func containerScopeScore(pod *v1.Pod, zones topologyv1alpha1.ZoneList, scorerFn
 	// https://github.com/kubernetes/kubernetes/blob/9ff3b7e744b34c099c1405d9add192adbef0b6b1/pkg/kubelet/cm/topologymanager/scope_container.go#L52
 	containers := append(pod.Spec.InitContainers, pod.Spec.Containers...)
 	contScore := make([]float64, len(containers))
-	allocatablePerNUMA := createNUMANodeList(zones)
 
 	for i, container := range containers {
 		identifier := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, container.Name)
+		allocatablePerNUMA := createNUMANodeListForRequest(zones, container.Resources.Requests)
 		contScore[i] = float64(scoreForEachNUMANode(container.Resources.Requests, allocatablePerNUMA, scorerFn, resourceToWeightMap))
 		klog.V(6).InfoS("container scope scoring", "container", identifier, "score", contScore[i])
 	}
@@ -152,3 +179,32 @@ func containerScopeScore(pod *v1.Pod, zones topologyv1alpha1.ZoneList, scorerFn
 	klog.V(5).InfoS("container scope scoring final node score", "finalScore", finalScore)
 	return finalScore, nil
 }
+
+// createNUMANodeListForRequest is like createNUMANodeList, but the NUMA nodes carry only the available amounts of the
+// requested resources, which are the only ones the scoring strategies look at.
+func createNUMANodeListForRequest(zones topologyv1alpha1.ZoneList, req v1.ResourceList) NUMANodeList {
+	return createNUMANodeListWith(zones, func(zone topologyv1alpha1.Zone) v1.ResourceList {
+		available, _ := zoneAvailableForRequest(zone, req)
+		return available
+	})
+}
+
+// zoneAvailableForRequest returns the amounts available in the zone of the requested resources only,
+// and true if the zone reports all the requested resources. Resources requested with zero quantity
+// don't need to be reported.
+func zoneAvailableForRequest(zone topologyv1alpha1.Zone, req v1.ResourceList) (v1.ResourceList, bool) {
+	available := make(v1.ResourceList, len(req))
+	for _, zr := range zone.Resources {
+		name := v1.ResourceName(zr.Name)
+		if _, ok := req[name]; !ok {
+			continue
+		}
+		available[name] = zr.Available.DeepCopy()
+	}
+	for name, qty := range req {
+		if _, ok := available[name]; !ok && !qty.IsZero() {
+			return available, false
+		}
+	}
+	return available, true
+}
@@ -18,11 +18,13 @@ package noderesourcetopology
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/listers/topology/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -195,7 +197,7 @@ func TestNodeResourceScorePlugin(t *testing.T) {
 			scoringHandlers := newScoringHandlers(test.strategy, nil)
 
 			tm := &TopologyMatch{
-				filterHandlers:  newFilterHandlers(),
+				filterHandlers:  newFilterHandlers(defaultMaxZonesForSubsetSearch),
 				scoringHandlers: scoringHandlers,
 				nrtCache:        nrtcache.NewPassthrough(lister),
 			}
@@ -442,7 +444,7 @@ func TestNodeResourceScorePluginLeastNUMA(t *testing.T) {
 			nodesMap, lister := initTest(tc.policy)
 
 			tm := &TopologyMatch{
-				filterHandlers:  newFilterHandlers(),
+				filterHandlers:  newFilterHandlers(defaultMaxZonesForSubsetSearch),
 				scoringHandlers: leastNUMAscoreHandlers(),
 				nrtCache:        nrtcache.NewPassthrough(lister),
 			}
@@ -486,3 +488,339 @@ func findMaxScoreNode(nodeToScore nodeToScoreMap) string {
 	}
 	return electedNode
 }
+
+func TestBestEffortFilterAndScore(t *testing.T) {
+	nodeTopologies := []*topologyv1alpha1.NodeResourceTopology{
+		{
+			ObjectMeta:       metav1.ObjectMeta{Name: "fit-node"},
+			TopologyPolicies: []string{string(topologyv1alpha1.BestEffortPodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", "8"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", "8"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta:       metav1.ObjectMeta{Name: "spread-node"},
+			TopologyPolicies: []string{string(topologyv1alpha1.BestEffortPodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	for _, obj := range nodeTopologies {
+		fakeInformer.Informer().GetStore().Add(obj)
+	}
+
+	tm := &TopologyMatch{
+		filterHandlers:  newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		scoringHandlers: newScoringHandlers(leastAllocatedScoreStrategy, nil),
+		nrtCache:        nrtcache.NewPassthrough(fakeInformer.Lister()),
+	}
+
+	// can't fit a single zone on spread-node
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("6"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})
+
+	scores := make(nodeToScoreMap)
+	for _, nrt := range nodeTopologies {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+		status := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+		if !status.IsSuccess() {
+			t.Fatalf("best-effort node %q filtered out: %v", nrt.Name, status)
+		}
+
+		score, status := tm.Score(context.Background(), framework.NewCycleState(), pod, nrt.Name)
+		if !status.IsSuccess() {
+			t.Fatalf("unexpected scoring failure on node %q: %v", nrt.Name, status)
+		}
+		scores[nrt.Name] = score
+	}
+
+	if scores["spread-node"] >= scores["fit-node"] {
+		t.Errorf("misaligned placement scored not lower than the aligned one: %v", scores)
+	}
+}
+
+func TestScoreSeesReservedPods(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+		TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(nrt)
+	nrtCache, err := nrtcache.NewOverReserve(fakeInformer.Lister(), emptyNodeIndexer{}, 0)
+	if err != nil {
+		t.Fatalf("cannot create the cache: %v", err)
+	}
+
+	tm := &TopologyMatch{
+		filterHandlers:  newFilterHandlers(defaultMaxZonesForSubsetSearch),
+		scoringHandlers: newScoringHandlers(leastAllocatedScoreStrategy, nil),
+		nrtCache:        nrtCache,
+	}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+
+	// none of the pods is bound, so only their reservations can make the later pods avoid the node
+	prevScore := framework.MaxNodeScore + 1
+	for idx := 0; idx < 4; idx++ {
+		pod := makePodByResourceList(&v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+		})
+		pod.Namespace = "namespace1"
+		pod.Name = fmt.Sprintf("pod-%d", idx)
+
+		state := framework.NewCycleState()
+		if status := tm.Filter(context.Background(), state, pod, nodeInfo); !status.IsSuccess() {
+			t.Fatalf("pod %q filtered out: %v", pod.Name, status)
+		}
+		score, status := tm.Score(context.Background(), state, pod, nrt.Name)
+		if !status.IsSuccess() {
+			t.Fatalf("unexpected scoring failure for pod %q: %v", pod.Name, status)
+		}
+		if score >= prevScore {
+			t.Errorf("pod %q: score %d not lower than the score %d before the last reservation", pod.Name, score, prevScore)
+		}
+		prevScore = score
+
+		if status := tm.Reserve(context.Background(), state, pod, nrt.Name); !status.IsSuccess() {
+			t.Fatalf("cannot reserve pod %q: %v", pod.Name, status)
+		}
+	}
+}
+
+func TestMostAllocatedPrefersBusyNodes(t *testing.T) {
+	makeNRT := func(name, availableCPU, availableMemory string) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: name},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", availableCPU),
+						MakeTopologyResInfo(memory, "16Gi", availableMemory),
+					},
+				},
+			},
+		}
+	}
+
+	fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+	fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+	fakeInformer.Informer().GetStore().Add(makeNRT("empty", "8", "16Gi"))
+	fakeInformer.Informer().GetStore().Add(makeNRT("half-full", "4", "8Gi"))
+
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})
+
+	tests := []struct {
+		name     string
+		weights  resourceToWeightMap
+		expected nodeToScoreMap
+	}{
+		{
+			// empty: cpu (0 + 2) / 8 = 25%, memory (0 + 2) / 16 = 12%, score (25 + 12) / 2 = 18
+			// half-full: cpu (4 + 2) / 8 = 75%, memory (8 + 2) / 16 = 62%, score (75 + 62) / 2 = 68
+			name:     "default weights",
+			expected: nodeToScoreMap{"empty": 18, "half-full": 68},
+		},
+		{
+			// empty: (25 * 3 + 12) / 4 = 21
+			// half-full: (75 * 3 + 62) / 4 = 71
+			name:     "cpu weighted",
+			weights:  resourceToWeightMap{v1.ResourceCPU: 3, v1.ResourceMemory: 1},
+			expected: nodeToScoreMap{"empty": 21, "half-full": 71},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := &TopologyMatch{
+				scoringHandlers:     newScoringHandlers(mostAllocatedScoreStrategy, tt.weights),
+				resourceToWeightMap: tt.weights,
+				nrtCache:            nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+			got := make(nodeToScoreMap)
+			for nodeName := range tt.expected {
+				score, status := tm.Score(context.Background(), framework.NewCycleState(), pod, nodeName)
+				if status != nil {
+					t.Fatalf("unexpected status: %v", status)
+				}
+				got[nodeName] = score
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got scores %v expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMostAllocatedIgnoresZonesNotFitting(t *testing.T) {
+	requested := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("6"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	}
+	capacity := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("8"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+	// the memory would fit and score high, but the cpus don't fit
+	available := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("3Gi"),
+	}
+	if score := mostAllocatedScoreStrategy(requested, available, capacity, nil); score != 0 {
+		t.Errorf("zone not fitting the pod scored %d", score)
+	}
+}
+
+func TestZoneAvailableForRequest(t *testing.T) {
+	zones := topologyv1alpha1.ZoneList{
+		{
+			Name: "node-0",
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "20", "12"),
+				MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+			},
+		},
+		{
+			Name: "node-1",
+			Type: "Node",
+			Resources: topologyv1alpha1.ResourceInfoList{
+				MakeTopologyResInfo(cpu, "20", "20"),
+				MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				MakeTopologyResInfo(nicResourceName, "8", "8"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		zoneIdx           int
+		req               v1.ResourceList
+		expectedAvailable v1.ResourceList
+		expectedAll       bool
+	}{
+		{
+			name:    "cpu only",
+			zoneIdx: 0,
+			req: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("2"),
+			},
+			expectedAvailable: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("12"),
+			},
+			expectedAll: true,
+		},
+		{
+			name:    "zero quantities not required",
+			zoneIdx: 0,
+			req: v1.ResourceList{
+				v1.ResourceMemory:                resource.MustParse("1Gi"),
+				v1.ResourceCPU:                   resource.MustParse("0"),
+				v1.ResourceName(nicResourceName): resource.MustParse("0"),
+			},
+			expectedAvailable: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("32Gi"),
+				v1.ResourceCPU:    resource.MustParse("12"),
+			},
+			expectedAll: true,
+		},
+		{
+			name:    "device reported by the zone",
+			zoneIdx: 1,
+			req: v1.ResourceList{
+				v1.ResourceCPU:                   resource.MustParse("2"),
+				v1.ResourceName(nicResourceName): resource.MustParse("1"),
+			},
+			expectedAvailable: v1.ResourceList{
+				v1.ResourceCPU:                   resource.MustParse("20"),
+				v1.ResourceName(nicResourceName): resource.MustParse("8"),
+			},
+			expectedAll: true,
+		},
+		{
+			name:    "device not reported by the zone",
+			zoneIdx: 0,
+			req: v1.ResourceList{
+				v1.ResourceCPU:                   resource.MustParse("2"),
+				v1.ResourceName(nicResourceName): resource.MustParse("1"),
+			},
+			expectedAvailable: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("12"),
+			},
+			expectedAll: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, all := zoneAvailableForRequest(zones[tt.zoneIdx], tt.req)
+			if all != tt.expectedAll {
+				t.Errorf("all resources present: got %v expected %v", all, tt.expectedAll)
+			}
+			if !apiequality.Semantic.DeepEqual(got, tt.expectedAvailable) {
+				t.Errorf("available: got %v expected %v", got, tt.expectedAvailable)
+			}
+		})
+	}
+}
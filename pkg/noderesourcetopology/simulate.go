@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// SimulatePlacement returns the names of the NUMA zones the given pod would be aligned to on the node described
+// by the given NRT object under the given policy, using the same logic of the filter handlers. Nothing is changed,
+// neither the NRT object nor any cached state. Returns false if the pod can't be aligned, or if the policy does
+// not align the resources (none, best-effort). Pods requesting the co-location of their containers are aligned to a single zone,
+// pods requesting the spread of their containers are aligned to a zone per container.
+func SimulatePlacement(nrt *topologyv1alpha1.NodeResourceTopology, pod *v1.Pod, policy topologyv1alpha1.TopologyManagerPolicy) ([]string, bool) {
+	if nrt == nil || pod == nil {
+		return nil, false
+	}
+
+	// the handlers only check the resources are exposed at node level, so the zone availability is good enough
+	res := makeResourceListFromZones(nrt.Zones)
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{
+		ObjectMeta: nrt.ObjectMeta,
+		Status: v1.NodeStatus{
+			Capacity:    res,
+			Allocatable: res,
+		},
+	})
+
+	// createNUMANodeList copies the resources, so the NRT object is never changed
+	nodes := createNUMANodeList(nrt.Zones)
+	qos := v1qos.GetPodQOS(pod)
+	numaIDs := sets.NewInt()
+
+	if isColocatedContainersPod(pod) && policy != topologyv1alpha1.None && !isBestEffortPolicy(policy) {
+		// see colocatedContainersHandler
+		policy = topologyv1alpha1.SingleNUMANodePodLevel
+	}
+	if !isColocatedContainersPod(pod) && isSpreadContainersPod(pod) && policy != topologyv1alpha1.None && policy != topologyv1alpha1.SingleNUMANodePodLevel && !isBestEffortPolicy(policy) {
+		// see spreadContainersHandler
+		numaIdxs, ok := spreadContainers(pod, nodes)
+		if !ok {
+			return nil, false
+		}
+		for _, idx := range numaIdxs {
+			numaIDs.Insert(nodes[idx].NUMAID)
+		}
+		return zoneNamesFromNUMAIDs(numaIDs), true
+	}
+
+	switch policy {
+	case topologyv1alpha1.SingleNUMANodePodLevel:
+		logID := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		numaID, match := resourcesAvailableInAnyNUMANodes(logID, nodes, util.GetPodEffectiveRequest(pod), qos, nodeInfo)
+		if !match {
+			return nil, false
+		}
+		numaIDs.Insert(numaID)
+
+	case topologyv1alpha1.SingleNUMANodeContainerLevel:
+		for _, initContainer := range pod.Spec.InitContainers {
+			logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, initContainer.Name)
+			if _, match := resourcesAvailableInAnyNUMANodes(logID, nodes, initContainer.Resources.Requests, qos, nodeInfo); !match {
+				return nil, false
+			}
+		}
+		for _, container := range pod.Spec.Containers {
+			logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, container.Name)
+			numaID, match := resourcesAvailableInAnyNUMANodes(logID, nodes, container.Resources.Requests, qos, nodeInfo)
+			if !match {
+				return nil, false
+			}
+			numaIDs.Insert(numaID)
+			subtractFromNUMA(nodes, numaID, container)
+		}
+
+	case topologyv1alpha1.RestrictedPodLevel:
+		logID := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		numaIdxs, match := resourcesAvailableInMinimalNUMANodes(logID, nodes, util.GetPodEffectiveRequest(pod), qos, nodeInfo, defaultMaxZonesForSubsetSearch)
+		if !match {
+			return nil, false
+		}
+		for _, idx := range numaIdxs {
+			numaIDs.Insert(nodes[idx].NUMAID)
+		}
+
+	case topologyv1alpha1.RestrictedContainerLevel:
+		for _, initContainer := range pod.Spec.InitContainers {
+			logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, initContainer.Name)
+			if _, match := resourcesAvailableInMinimalNUMANodes(logID, nodes, initContainer.Resources.Requests, qos, nodeInfo, defaultMaxZonesForSubsetSearch); !match {
+				return nil, false
+			}
+		}
+		for _, container := range pod.Spec.Containers {
+			logID := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, container.Name)
+			numaIdxs, match := resourcesAvailableInMinimalNUMANodes(logID, nodes, container.Resources.Requests, qos, nodeInfo, defaultMaxZonesForSubsetSearch)
+			if !match {
+				return nil, false
+			}
+			for _, idx := range numaIdxs {
+				numaIDs.Insert(nodes[idx].NUMAID)
+			}
+			subtractFromNUMAs(container.Resources.Requests, nodes, numaIdxs...)
+		}
+
+	default:
+		return nil, false
+	}
+
+	return zoneNamesFromNUMAIDs(numaIDs), true
+}
+
+func zoneNamesFromNUMAIDs(numaIDs sets.Int) []string {
+	zones := make([]string, 0, numaIDs.Len())
+	for _, numaID := range numaIDs.List() {
+		zones = append(zones, fmt.Sprintf("node-%d", numaID))
+	}
+	return zones
+}
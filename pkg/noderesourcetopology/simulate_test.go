@@ -0,0 +1,183 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestSimulatePlacement(t *testing.T) {
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "2"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+				{
+					Name: "node-2",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "4", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		policy        topologyv1alpha1.TopologyManagerPolicy
+		containerCPUs []string
+		expectedZones []string
+		expectedOK    bool
+	}{
+		{
+			name:          "single-numa-node pod scope, fits the first zone",
+			policy:        topologyv1alpha1.SingleNUMANodePodLevel,
+			containerCPUs: []string{"1", "1"},
+			expectedZones: []string{"node-0"},
+			expectedOK:    true,
+		},
+		{
+			name:          "single-numa-node pod scope, fits only the second zone",
+			policy:        topologyv1alpha1.SingleNUMANodePodLevel,
+			containerCPUs: []string{"2", "2"},
+			expectedZones: []string{"node-1"},
+			expectedOK:    true,
+		},
+		{
+			name:          "single-numa-node pod scope, does not fit",
+			policy:        topologyv1alpha1.SingleNUMANodePodLevel,
+			containerCPUs: []string{"3", "3"},
+			expectedOK:    false,
+		},
+		{
+			name:          "single-numa-node container scope, containers on different zones",
+			policy:        topologyv1alpha1.SingleNUMANodeContainerLevel,
+			containerCPUs: []string{"2", "4", "3"},
+			expectedZones: []string{"node-0", "node-1", "node-2"},
+			expectedOK:    true,
+		},
+		{
+			name:          "single-numa-node container scope, does not fit",
+			policy:        topologyv1alpha1.SingleNUMANodeContainerLevel,
+			containerCPUs: []string{"6"},
+			expectedOK:    false,
+		},
+		{
+			name:          "restricted pod scope, spans two zones",
+			policy:        topologyv1alpha1.RestrictedPodLevel,
+			containerCPUs: []string{"3", "3"},
+			expectedZones: []string{"node-0", "node-1"},
+			expectedOK:    true,
+		},
+		{
+			name:          "restricted container scope, containers on the same zone",
+			policy:        topologyv1alpha1.RestrictedContainerLevel,
+			containerCPUs: []string{"1", "1"},
+			expectedZones: []string{"node-0"},
+			expectedOK:    true,
+		},
+		{
+			name:          "restricted container scope, does not fit",
+			policy:        topologyv1alpha1.RestrictedContainerLevel,
+			containerCPUs: []string{"6", "6"},
+			expectedOK:    false,
+		},
+		{
+			name:          "best-effort policy does not align",
+			policy:        topologyv1alpha1.BestEffortPodLevel,
+			containerCPUs: []string{"1"},
+			expectedOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrt := makeNRT(tt.policy)
+			nrtOrig := nrt.DeepCopy()
+
+			var resources []v1.ResourceList
+			for _, cpuQty := range tt.containerCPUs {
+				resources = append(resources, v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse(cpuQty),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+				})
+			}
+			pod := makePod("testpod", withMultiContainers(resources))
+
+			zones, ok := SimulatePlacement(nrt, pod, tt.policy)
+			if ok != tt.expectedOK {
+				t.Fatalf("unexpected result: got %v expected %v", ok, tt.expectedOK)
+			}
+			if !reflect.DeepEqual(zones, tt.expectedZones) {
+				t.Errorf("unexpected zones: got %v expected %v", zones, tt.expectedZones)
+			}
+			if !reflect.DeepEqual(nrt, nrtOrig) {
+				t.Errorf("the NRT object was changed")
+			}
+
+			if tt.policy == topologyv1alpha1.BestEffortPodLevel {
+				return
+			}
+			// the simulation must agree with the filter
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+			status := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			if status.IsSuccess() != ok {
+				t.Errorf("simulation disagrees with the filter: simulated %v filter status %v", ok, status)
+			}
+		})
+	}
+}
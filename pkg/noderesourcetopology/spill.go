@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// spillableResourcesHandler checks the requests of the pod for the spillable resources against the sum of the
+// resources available on the NUMA zones, since the kubelet may allocate them from more than one zone.
+// Checking the whole pod at once prevents its containers from counting the same spilled resources twice.
+func spillableResourcesHandler(pod *v1.Pod, spillable sets.String, zones topologyv1alpha1.ZoneList) *framework.Status {
+	nodes := createNUMANodeList(zones)
+	qos := v1qos.GetPodQOS(pod)
+	logID := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+
+	for resName, quantity := range util.GetPodEffectiveRequest(pod) {
+		if !spillable.Has(string(resName)) || quantity.IsZero() {
+			continue
+		}
+		var total resource.Quantity
+		for _, node := range nodes {
+			if numaQuantity, ok := node.Resources[resName]; ok {
+				total.Add(numaQuantity)
+			}
+		}
+		if !isResourceSetSuitable(qos, resName, quantity, total) {
+			klog.V(5).InfoS("spillable resource does not fit the zones", "logID", logID, "resource", resName, "request", quantity.String(), "available", total.String())
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot fit %s across the NUMA zones", resName))
+		}
+	}
+	return nil
+}
+
+// withSpilledZones returns a copy of the given zones in which each NUMA zone reports, for the spillable resources,
+// the amount available on all the NUMA zones together, so the handlers of the policies consider the spillable
+// requests fitting any zone as long as they fit the node. The pod is left untouched to preserve its QoS class.
+func withSpilledZones(zones topologyv1alpha1.ZoneList, spillable sets.String) topologyv1alpha1.ZoneList {
+	totals := make(map[string]resource.Quantity)
+	for _, zone := range zones {
+		if zone.Type != nrtcache.ZoneTypeNUMANode {
+			continue
+		}
+		for _, res := range zone.Resources {
+			if !spillable.Has(res.Name) {
+				continue
+			}
+			qty := totals[res.Name]
+			qty.Add(res.Available)
+			totals[res.Name] = qty
+		}
+	}
+
+	ret := zones.DeepCopy()
+	for zi := range ret {
+		if ret[zi].Type != nrtcache.ZoneTypeNUMANode {
+			continue
+		}
+		for ri := range ret[zi].Resources {
+			if total, ok := totals[ret[zi].Resources[ri].Name]; ok {
+				ret[zi].Resources[ri].Available = total.DeepCopy()
+			}
+		}
+	}
+	return ret
+}
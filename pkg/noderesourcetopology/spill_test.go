@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestNodeResourceTopologySpillableResources(t *testing.T) {
+	makeNRT := func(policy topologyv1alpha1.TopologyManagerPolicy) *topologyv1alpha1.NodeResourceTopology {
+		return &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha1.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", "4"),
+						MakeTopologyResInfo(memory, "8Gi", "6Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha1.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "8", "2"),
+						MakeTopologyResInfo(memory, "8Gi", "6Gi"),
+					},
+				},
+			},
+		}
+	}
+
+	makeResources := func(cpuQty, memoryQty string) v1.ResourceList {
+		return v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpuQty),
+			v1.ResourceMemory: resource.MustParse(memoryQty),
+		}
+	}
+
+	memorySpill := sets.NewString(string(v1.ResourceMemory))
+
+	tests := []struct {
+		name       string
+		policy     topologyv1alpha1.TopologyManagerPolicy
+		resources  []v1.ResourceList
+		spillable  sets.String
+		wantStatus framework.Code
+	}{
+		{
+			name:       "memory exceeds any zone, strict - not fit",
+			policy:     topologyv1alpha1.SingleNUMANodePodLevel,
+			resources:  []v1.ResourceList{makeResources("2", "10Gi")},
+			wantStatus: framework.Unschedulable,
+		},
+		{
+			name:      "memory exceeds any zone, spillable - fit",
+			policy:    topologyv1alpha1.SingleNUMANodePodLevel,
+			resources: []v1.ResourceList{makeResources("2", "10Gi")},
+			spillable: memorySpill,
+		},
+		{
+			name:      "memory exceeds any zone, spillable, container scope - fit",
+			policy:    topologyv1alpha1.SingleNUMANodeContainerLevel,
+			resources: []v1.ResourceList{makeResources("2", "10Gi")},
+			spillable: memorySpill,
+		},
+		{
+			name:      "memory exceeds any zone, spillable, restricted - fit",
+			policy:    topologyv1alpha1.RestrictedPodLevel,
+			resources: []v1.ResourceList{makeResources("2", "10Gi")},
+			spillable: memorySpill,
+		},
+		{
+			name:       "memory exceeds the node total, spillable - not fit",
+			policy:     topologyv1alpha1.SingleNUMANodePodLevel,
+			resources:  []v1.ResourceList{makeResources("2", "14Gi")},
+			spillable:  memorySpill,
+			wantStatus: framework.Unschedulable,
+		},
+		{
+			name:   "containers fit the spilled zones one by one but not together - not fit",
+			policy: topologyv1alpha1.SingleNUMANodeContainerLevel,
+			resources: []v1.ResourceList{
+				makeResources("1", "8Gi"),
+				makeResources("1", "8Gi"),
+			},
+			spillable:  memorySpill,
+			wantStatus: framework.Unschedulable,
+		},
+		{
+			name:       "cpu exceeds any zone, memory spillable - cpu stays strict",
+			policy:     topologyv1alpha1.SingleNUMANodePodLevel,
+			resources:  []v1.ResourceList{makeResources("6", "2Gi")},
+			spillable:  memorySpill,
+			wantStatus: framework.Unschedulable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrt := makeNRT(tt.policy)
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(nrt)
+
+			tm := TopologyMatch{
+				filterHandlers:     newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:           nrtcache.NewPassthrough(fakeInformer.Lister()),
+				spillableResources: tt.spillable,
+			}
+
+			pod := makePod("testpod", withMultiContainers(tt.resources))
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			if gotStatus.Code() != tt.wantStatus {
+				t.Errorf("status does not match: %v, want code: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
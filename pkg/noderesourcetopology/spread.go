@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+)
+
+// AnnotationSpreadContainers is the pod annotation requesting each (app) container of the pod to be aligned
+// to a different NUMA zone, e.g. for resilience of multi-process pods. Value must be a boolean.
+// If the pod also requests the co-location of its containers, the co-location wins. The annotation is ignored
+// on nodes using the single-numa-node policy with the pod scope, which always allocate the whole pod from one zone.
+const AnnotationSpreadContainers = "noderesourcetopology/spread-containers"
+
+// isSpreadContainersPod returns true if the given pod requests its containers to be spread across NUMA zones.
+func isSpreadContainersPod(pod *v1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	val, ok := pod.Annotations[AnnotationSpreadContainers]
+	if !ok {
+		return false
+	}
+	spread, err := strconv.ParseBool(val)
+	return err == nil && spread
+}
+
+// spreadContainersHandler admits pods requesting their containers to be spread only if each app container fits
+// in a different NUMA zone. The init containers run before and don't overlap with the app containers, so they
+// are not spread. This is enforced by the scheduler only; the kubelet is not aware of the spread.
+func spreadContainersHandler(pod *v1.Pod, zones topologyv1alpha1.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+	klog.V(5).InfoS("Spread containers handler")
+
+	nodes := createNUMANodeList(zones)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes("spread containers handler NUMA resources", nodeInfo.Node().Name, nodes)
+
+	if _, ok := spreadContainers(pod, nodes); !ok {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cannot spread containers of pod: %s", pod.Name))
+	}
+	return nil
+}
+
+// spreadContainers assigns each app container of the pod to a different NUMA node which can fit it, and returns
+// the indexes (in numaNodes, NOT the NUMA IDs) of the assigned NUMA nodes, in the same order of the containers.
+// Returns false if no such assignment exists.
+func spreadContainers(pod *v1.Pod, numaNodes NUMANodeList) ([]int, bool) {
+	containers := pod.Spec.Containers
+	if len(containers) > len(numaNodes) {
+		return nil, false
+	}
+
+	qos := v1qos.GetPodQOS(pod)
+	assigned := make([]int, len(containers))
+	used := make([]bool, len(numaNodes))
+
+	// the pods have a handful of containers, and the nodes up to 8 NUMA nodes, so backtracking is cheap enough
+	var assign func(idx int) bool
+	assign = func(idx int) bool {
+		if idx == len(containers) {
+			return true
+		}
+		for numaIdx, numaNode := range numaNodes {
+			if used[numaIdx] || !resourcesFitCombination(qos, containers[idx].Resources.Requests, numaNode.Resources) {
+				continue
+			}
+			used[numaIdx] = true
+			assigned[idx] = numaIdx
+			if assign(idx + 1) {
+				return true
+			}
+			used[numaIdx] = false
+		}
+		return false
+	}
+
+	if !assign(0) {
+		return nil, false
+	}
+	return assigned, true
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	topologyinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+)
+
+func TestSpreadContainersFilter(t *testing.T) {
+	makeNRT := func(cpuAvailable ...string) *topologyv1alpha1.NodeResourceTopology {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodeContainerLevel)},
+		}
+		for idx, avail := range cpuAvailable {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha1.Zone{
+				Name: fmt.Sprintf("node-%d", idx),
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "8", avail),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+
+	tests := []struct {
+		name          string
+		nrt           *topologyv1alpha1.NodeResourceTopology
+		containerCPUs []string
+		spread        bool
+		wantStatus    *framework.Status
+		wantZones     []string
+	}{
+		{
+			name:          "containers share the first zone",
+			nrt:           makeNRT("8", "4"),
+			containerCPUs: []string{"2", "2"},
+			wantZones:     []string{"node-0"},
+		},
+		{
+			name:          "spread containers forced onto distinct zones",
+			nrt:           makeNRT("8", "4"),
+			containerCPUs: []string{"2", "2"},
+			spread:        true,
+			wantZones:     []string{"node-0", "node-1"},
+		},
+		{
+			name:          "spread containers, the first fit is not a valid assignment",
+			nrt:           makeNRT("4", "2"),
+			containerCPUs: []string{"2", "4"},
+			spread:        true,
+			wantZones:     []string{"node-0", "node-1"},
+		},
+		{
+			name:          "spread containers, only one zone has room",
+			nrt:           makeNRT("8", "1"),
+			containerCPUs: []string{"2", "2"},
+			spread:        true,
+			wantStatus:    framework.NewStatus(framework.Unschedulable, "cannot spread containers of pod: testpod"),
+		},
+		{
+			name:          "spread containers, more containers than zones",
+			nrt:           makeNRT("8", "8"),
+			containerCPUs: []string{"1", "1", "1"},
+			spread:        true,
+			wantStatus:    framework.NewStatus(framework.Unschedulable, "cannot spread containers of pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(tt.nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			var resources []v1.ResourceList
+			for _, cpuQty := range tt.containerCPUs {
+				resources = append(resources, v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse(cpuQty),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+				})
+			}
+			pod := makePod("testpod", withMultiContainers(resources))
+			if tt.spread {
+				pod.Annotations = map[string]string{AnnotationSpreadContainers: "true"}
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+
+			zones, ok := SimulatePlacement(tt.nrt, pod, topologyv1alpha1.SingleNUMANodeContainerLevel)
+			if ok != (tt.wantStatus == nil) {
+				t.Fatalf("simulation disagrees with the filter: simulated %v", ok)
+			}
+			if !reflect.DeepEqual(zones, tt.wantZones) {
+				t.Errorf("unexpected zones: got %v expected %v", zones, tt.wantZones)
+			}
+		})
+	}
+}
+
+func TestSpreadContainersPodLevelPolicy(t *testing.T) {
+	makeNRT := func(cpuAvailable ...string) *topologyv1alpha1.NodeResourceTopology {
+		nrt := &topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "host0"},
+			TopologyPolicies: []string{string(topologyv1alpha1.SingleNUMANodePodLevel)},
+		}
+		for idx, avail := range cpuAvailable {
+			nrt.Zones = append(nrt.Zones, topologyv1alpha1.Zone{
+				Name: fmt.Sprintf("node-%d", idx),
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					MakeTopologyResInfo(cpu, avail, avail),
+					MakeTopologyResInfo(memory, "8Gi", "8Gi"),
+				},
+			})
+		}
+		return nrt
+	}
+
+	tests := []struct {
+		name       string
+		nrt        *topologyv1alpha1.NodeResourceTopology
+		wantStatus *framework.Status
+		wantZones  []string
+	}{
+		{
+			// spreading would need two zones with 9 CPUs
+			name:      "whole pod fits a single zone",
+			nrt:       makeNRT("20", "4"),
+			wantZones: []string{"node-0"},
+		},
+		{
+			// spreading would place each container on its own zone
+			name:       "whole pod does not fit any single zone",
+			nrt:        makeNRT("10", "10"),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "cannot align pod: testpod"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := faketopologyv1alpha1.NewSimpleClientset()
+			fakeInformer := topologyinformers.NewSharedInformerFactory(fakeClient, 0).Topology().V1alpha1().NodeResourceTopologies()
+			fakeInformer.Informer().GetStore().Add(tt.nrt)
+
+			tm := TopologyMatch{
+				filterHandlers: newFilterHandlers(defaultMaxZonesForSubsetSearch),
+				nrtCache:       nrtcache.NewPassthrough(fakeInformer.Lister()),
+			}
+
+			resources := v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("9"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}
+			pod := makePod("testpod", withMultiContainers([]v1.ResourceList{resources, resources}))
+			pod.Annotations = map[string]string{AnnotationSpreadContainers: "true"}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(makeNodeFromNodeResourceTopology(tt.nrt))
+			gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+
+			zones, ok := SimulatePlacement(tt.nrt, pod, topologyv1alpha1.SingleNUMANodePodLevel)
+			if ok != (tt.wantStatus == nil) {
+				t.Fatalf("simulation disagrees with the filter: simulated %v", ok)
+			}
+			if !reflect.DeepEqual(zones, tt.wantZones) {
+				t.Errorf("unexpected zones: got %v expected %v", zones, tt.wantZones)
+			}
+		})
+	}
+}
@@ -80,6 +80,27 @@ func NodeResourceTopologyResources(nrtObj *topologyv1alpha1.NodeResourceTopology
 	return nrtObj.Name + "={" + strings.Join(zones, ",") + "}"
 }
 
+// NodeResourceTopologyZones renders the zones of the given NRT object in the compact
+// "zone <name>: <resource> <available>/<capacity>, ..." form, one zone per line.
+// Meant to make test failures and debug logs about the accounting readable.
+func NodeResourceTopologyZones(nrtObj *topologyv1alpha1.NodeResourceTopology) string {
+	zones := []string{}
+	for _, zoneInfo := range nrtObj.Zones {
+		zones = append(zones, Zone(zoneInfo))
+	}
+	return strings.Join(zones, "\n")
+}
+
+// Zone renders a single zone as "zone <name>: <resource> <available>/<capacity>, ...",
+// keeping the resource order found in the zone.
+func Zone(zoneInfo topologyv1alpha1.Zone) string {
+	items := []string{}
+	for _, resInfo := range zoneInfo.Resources {
+		items = append(items, fmt.Sprintf("%s %s/%s", resInfo.Name, resInfo.Available.String(), resInfo.Capacity.String()))
+	}
+	return "zone " + zoneInfo.Name + ": " + strings.Join(items, ", ")
+}
+
 func nrtResourceInfoListToString(resInfoList []topologyv1alpha1.ResourceInfo) string {
 	items := []string{}
 	for _, resInfo := range resInfoList {
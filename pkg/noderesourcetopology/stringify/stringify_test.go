@@ -23,6 +23,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 )
 
 func TestResourceListToLoggable(t *testing.T) {
@@ -126,6 +129,63 @@ func TestResourceListToLoggable(t *testing.T) {
 	}
 }
 
+func TestNodeResourceTopologyZones(t *testing.T) {
+	nrt := &topologyv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Zones: topologyv1alpha1.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					{
+						Name:      "cpu",
+						Capacity:  resource.MustParse("20"),
+						Available: resource.MustParse("2"),
+					},
+					{
+						Name:      "memory",
+						Capacity:  resource.MustParse("32Gi"),
+						Available: resource.MustParse("26Gi"),
+					},
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha1.ResourceInfoList{
+					{
+						Name:      "cpu",
+						Capacity:  resource.MustParse("20"),
+						Available: resource.MustParse("2"),
+					},
+					{
+						Name:      "memory",
+						Capacity:  resource.MustParse("32Gi"),
+						Available: resource.MustParse("26Gi"),
+					},
+					{
+						Name:      "nic",
+						Capacity:  resource.MustParse("8"),
+						Available: resource.MustParse("6"),
+					},
+				},
+			},
+		},
+	}
+
+	got := Zone(nrt.Zones[1])
+	expected := "zone node-1: cpu 2/20, memory 26Gi/32Gi, nic 6/8"
+	if got != expected {
+		t.Errorf("got=%q expected=%q", got, expected)
+	}
+
+	got = NodeResourceTopologyZones(nrt)
+	expected = "zone node-0: cpu 2/20, memory 26Gi/32Gi\nzone node-1: cpu 2/20, memory 26Gi/32Gi, nic 6/8"
+	if got != expected {
+		t.Errorf("got=%q expected=%q", got, expected)
+	}
+}
+
 // taken from klog
 
 const missingValue = "(MISSING)"
@@ -47,12 +47,13 @@ func ResourceList(r *framework.Resource) v1.ResourceList {
 // - the sum of all app containers(spec.Containers) request for a resource.
 // - the effective init containers(spec.InitContainers) request for a resource.
 // The effective init containers request is the highest request on all init containers.
+// Resources with only a limit are requested at the limit, like the apiserver defaults them.
 func GetPodEffectiveRequest(pod *v1.Pod) v1.ResourceList {
 	initResources := make(v1.ResourceList)
 	resources := make(v1.ResourceList)
 
 	for _, container := range pod.Spec.InitContainers {
-		for name, quantity := range container.Resources.Requests {
+		for name, quantity := range containerRequests(container) {
 			if q, ok := initResources[name]; ok && quantity.Cmp(q) <= 0 {
 				continue
 			}
@@ -60,8 +61,10 @@ func GetPodEffectiveRequest(pod *v1.Pod) v1.ResourceList {
 		}
 	}
 	for _, container := range pod.Spec.Containers {
-		for name, quantity := range container.Resources.Requests {
+		for name, quantity := range containerRequests(container) {
 			if q, ok := resources[name]; ok {
+				// the quantity may share its decimal representation with the pod, which must not change
+				quantity = quantity.DeepCopy()
 				quantity.Add(q)
 			}
 			resources[name] = quantity
@@ -75,3 +78,19 @@ func GetPodEffectiveRequest(pod *v1.Pod) v1.ResourceList {
 	}
 	return resources
 }
+
+// containerRequests returns the resources requested by the container, falling back to the limit
+// for the resources which have a limit but no request.
+func containerRequests(container v1.Container) v1.ResourceList {
+	if len(container.Resources.Limits) == 0 {
+		return container.Resources.Requests
+	}
+	requests := make(v1.ResourceList, len(container.Resources.Limits))
+	for name, quantity := range container.Resources.Limits {
+		requests[name] = quantity
+	}
+	for name, quantity := range container.Resources.Requests {
+		requests[name] = quantity
+	}
+	return requests
+}
@@ -125,3 +125,82 @@ func TestGetPodEffectiveRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPodEffectiveRequestLimitsOnly(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Limits: makeResourceList(5000, 1),
+					},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Limits: v1.ResourceList{
+							v1.ResourceCPU: resource.MustParse("4"),
+						},
+					},
+				},
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: makeResourceList(1000, 1),
+						Limits:   makeResourceList(2000, 2),
+					},
+				},
+			},
+		},
+	}
+	// the limit is used only when there is no request
+	want := makeResourceList(5000, 1)
+	if got := GetPodEffectiveRequest(pod); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPodEffectiveRequest() = %v, want %v", got, want)
+	}
+
+	pod.Spec.InitContainers = nil
+	got := GetPodEffectiveRequest(pod)
+	if cpu := got[v1.ResourceCPU]; cpu.Cmp(resource.MustParse("5")) != 0 {
+		t.Errorf("GetPodEffectiveRequest() cpu = %s, want 5", cpu.String())
+	}
+}
+
+func TestGetPodEffectiveRequestSameDevice(t *testing.T) {
+	nic := v1.ResourceName("vendor_A.com/nic")
+	// the containers share the same list, like when built from the same template, in decimal representation
+	devices := v1.ResourceList{
+		nic: resource.MustParse("2"),
+	}
+	qty := devices[nic]
+	qty.AsDec()
+	devices[nic] = qty
+
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: devices,
+						Limits:   devices,
+					},
+				},
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: devices,
+						Limits:   devices,
+					},
+				},
+			},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		got := GetPodEffectiveRequest(pod)
+		if devs := got[nic]; devs.Cmp(resource.MustParse("4")) != 0 {
+			t.Errorf("GetPodEffectiveRequest() devices = %s, want 4", devs.String())
+		}
+	}
+	if devs := pod.Spec.Containers[0].Resources.Requests[nic]; devs.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("container devices changed to %s, want 2", devs.String())
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	topologyv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NRTBuilder builds NodeResourceTopology objects for tests, e.g.
+//
+//	NewNRTBuilder("node").WithPolicy(topologyv1alpha1.SingleNUMANodePodLevel).
+//		WithZone("node-0").WithResource("cpu", "20", "20").
+//		WithZone("node-1").WithResource("cpu", "20", "18").Build()
+//
+// The resources and the attributes are added to the zone added last.
+type NRTBuilder struct {
+	nrt topologyv1alpha1.NodeResourceTopology
+}
+
+// NewNRTBuilder starts building the NodeResourceTopology object of the node with the given name.
+func NewNRTBuilder(nodeName string) *NRTBuilder {
+	return &NRTBuilder{
+		nrt: topologyv1alpha1.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		},
+	}
+}
+
+// WithPolicy adds the given topology manager policy.
+func (b *NRTBuilder) WithPolicy(policy topologyv1alpha1.TopologyManagerPolicy) *NRTBuilder {
+	b.nrt.TopologyPolicies = append(b.nrt.TopologyPolicies, string(policy))
+	return b
+}
+
+// WithAnnotation sets the given annotation, like the podset fingerprint.
+func (b *NRTBuilder) WithAnnotation(key, value string) *NRTBuilder {
+	if b.nrt.Annotations == nil {
+		b.nrt.Annotations = make(map[string]string)
+	}
+	b.nrt.Annotations[key] = value
+	return b
+}
+
+// WithZone adds a NUMA zone with the given name, like "node-0".
+func (b *NRTBuilder) WithZone(name string) *NRTBuilder {
+	return b.WithZoneOfType(name, "Node")
+}
+
+// WithZoneOfType adds a zone with the given name and type, like a zone of type "Socket".
+func (b *NRTBuilder) WithZoneOfType(name, zoneType string) *NRTBuilder {
+	b.nrt.Zones = append(b.nrt.Zones, topologyv1alpha1.Zone{
+		Name: name,
+		Type: zoneType,
+	})
+	return b
+}
+
+// WithResource adds to the last zone the resource with the given name, capacity and availability.
+// Panics if no zone was added yet, or if the quantities can't be parsed.
+func (b *NRTBuilder) WithResource(name, capacity, available string) *NRTBuilder {
+	zone := b.lastZone("resource " + name)
+	zone.Resources = append(zone.Resources, topologyv1alpha1.ResourceInfo{
+		Name:      name,
+		Capacity:  resource.MustParse(capacity),
+		Available: resource.MustParse(available),
+	})
+	return b
+}
+
+// WithAttribute adds to the last zone the attribute with the given name and value. Panics if no zone was added yet.
+func (b *NRTBuilder) WithAttribute(name, value string) *NRTBuilder {
+	zone := b.lastZone("attribute " + name)
+	zone.Attributes = append(zone.Attributes, topologyv1alpha1.AttributeInfo{
+		Name:  name,
+		Value: value,
+	})
+	return b
+}
+
+// Build returns a new object, so the builder can be reused to build variants.
+func (b *NRTBuilder) Build() *topologyv1alpha1.NodeResourceTopology {
+	return b.nrt.DeepCopy()
+}
+
+func (b *NRTBuilder) lastZone(what string) *topologyv1alpha1.Zone {
+	if len(b.nrt.Zones) == 0 {
+		panic(fmt.Sprintf("cannot add %s to node %s: no zones", what, b.nrt.Name))
+	}
+	return &b.nrt.Zones[len(b.nrt.Zones)-1]
+}